@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// Manager mints auth tokens with a TokenSigner and caches them.
+// A cached token is replaced once it is within the refresh window of its deadline,
+// so a token handed out always stays valid for at least that long.
+type Manager struct {
+	mu            sync.Mutex
+	signer        TokenSigner
+	lifetime      time.Duration
+	refreshBefore time.Duration
+	token         string
+	deadline      time.Time
+	now           func() time.Time
+}
+
+// NewManager creates a Manager minting tokens with signer
+func NewManager(signer TokenSigner) *Manager {
+	return &Manager{
+		signer:        signer,
+		lifetime:      DefaultLifetime,
+		refreshBefore: DefaultRefreshBefore,
+		now:           time.Now,
+	}
+}
+
+// SetLifetime sets the lifetime of newly minted tokens (default: 8h)
+func (m *Manager) SetLifetime(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lifetime = d
+}
+
+// SetRefreshBefore sets how long before its deadline a token is replaced (default: 10m)
+func (m *Manager) SetRefreshBefore(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.refreshBefore = d
+}
+
+// Token returns the cached token, minting a new one if it is missing or about to expire
+func (m *Manager) Token() (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	if m.token != "" && now.Add(m.refreshBefore).Before(m.deadline) {
+		return m.token, nil
+	}
+
+	deadline := now.Add(m.lifetime)
+	token, err := m.signer.GetAuthToken(deadline)
+	if err != nil {
+		return "", err
+	}
+	// Deadlines are signed with second precision
+	m.token, m.deadline = token, time.Unix(deadline.Unix(), 0)
+	return token, nil
+}
+
+// Deadline returns the deadline of the cached token, or the zero time if there is none
+func (m *Manager) Deadline() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.deadline
+}
+
+// Invalidate drops the cached token, e.g. after the server rejected it
+func (m *Manager) Invalidate() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.token, m.deadline = "", time.Time{}
+}
+
+var _ Provider = (*Manager)(nil)
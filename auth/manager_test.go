@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// mockSigner mints tokens in the standard format and counts them
+type mockSigner struct {
+	minted int
+	err    error
+}
+
+func (s *mockSigner) GetAuthToken(deadline time.Time) (string, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+	s.minted++
+	return tokenFor(deadline, s.minted), nil
+}
+
+func tokenFor(deadline time.Time, n int) string {
+	return fmt.Sprintf("%d:7:2:sig%d", deadline.Unix(), n)
+}
+
+func newTestManager(signer TokenSigner) (*Manager, *time.Time) {
+	now := time.Unix(1700000000, 0)
+	m := NewManager(signer)
+	m.now = func() time.Time { return now }
+	return m, &now
+}
+
+func TestManager_CachesUntilRefreshWindow(t *testing.T) {
+	signer := &mockSigner{}
+	m, now := newTestManager(signer)
+	m.SetLifetime(time.Hour)
+	m.SetRefreshBefore(10 * time.Minute)
+
+	first, err := m.Token()
+	if err != nil {
+		t.Fatalf("Token failed: %v", err)
+	}
+	if want := now.Add(time.Hour); !m.Deadline().Equal(want) {
+		t.Errorf("expected deadline %v, got %v", want, m.Deadline())
+	}
+
+	*now = now.Add(49 * time.Minute)
+	if token, _ := m.Token(); token != first || signer.minted != 1 {
+		t.Errorf("expected cached token, got %s after %d mints", token, signer.minted)
+	}
+
+	// Within 10 minutes of the deadline, a new token is minted
+	*now = now.Add(2 * time.Minute)
+	token, err := m.Token()
+	if err != nil {
+		t.Fatalf("Token failed: %v", err)
+	}
+	if token == first || signer.minted != 2 {
+		t.Errorf("expected a refreshed token, got %s after %d mints", token, signer.minted)
+	}
+}
+
+func TestManager_Invalidate(t *testing.T) {
+	signer := &mockSigner{}
+	m, _ := newTestManager(signer)
+
+	m.Token() //nolint:errcheck
+	m.Invalidate()
+	m.Token() //nolint:errcheck
+
+	if signer.minted != 2 {
+		t.Errorf("expected a new token after Invalidate, got %d mints", signer.minted)
+	}
+}
+
+func TestManager_SignerError(t *testing.T) {
+	signErr := errors.New("no key")
+	m, _ := newTestManager(&mockSigner{err: signErr})
+
+	if _, err := m.Token(); !errors.Is(err, signErr) {
+		t.Errorf("expected signer error, got %v", err)
+	}
+}
+
+func TestReadOnly_Static(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	r := NewReadOnly(tokenFor(now.Add(time.Hour), 1))
+	r.now = func() time.Time { return now }
+
+	if token, err := r.Token(); err != nil || token != tokenFor(now.Add(time.Hour), 1) {
+		t.Fatalf("expected the token, got %s, %v", token, err)
+	}
+
+	now = now.Add(time.Hour)
+	if _, err := r.Token(); !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("expected ErrTokenExpired, got %v", err)
+	}
+
+	// Tokens without a deadline, such as read-only API tokens, never expire client-side
+	if token, err := NewReadOnly("ro:7:all:token").Token(); err != nil || token != "ro:7:all:token" {
+		t.Errorf("expected the opaque token, got %s, %v", token, err)
+	}
+	if _, err := NewReadOnly("").Token(); !errors.Is(err, ErrNoToken) {
+		t.Errorf("expected ErrNoToken, got %v", err)
+	}
+}
+
+func TestReadOnly_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	m, now := newTestManager(&mockSigner{})
+	m.SetLifetime(time.Hour)
+
+	if err := WriteTokenFile(path, m); err != nil {
+		t.Fatalf("WriteTokenFile failed: %v", err)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("expected mode 0600, got %v", info.Mode().Perm())
+	}
+
+	r := NewReadOnlyFile(path)
+	r.now = func() time.Time { return *now }
+
+	first, err := r.Token()
+	if err != nil || first != tokenFor(now.Add(time.Hour), 1) {
+		t.Fatalf("expected the written token, got %s, %v", first, err)
+	}
+
+	// The signing process refreshes the file; the reader picks it up near the deadline
+	*now = now.Add(55 * time.Minute)
+	if err := WriteTokenFile(path, m); err != nil {
+		t.Fatalf("WriteTokenFile failed: %v", err)
+	}
+	second, err := r.Token()
+	if err != nil || second == first {
+		t.Fatalf("expected the refreshed token, got %s, %v", second, err)
+	}
+
+	// A missing file keeps the last token until it expires
+	os.Remove(path) //nolint:errcheck
+	r.Invalidate()
+	if token, err := r.Token(); err != nil || token != second {
+		t.Errorf("expected the last token, got %s, %v", token, err)
+	}
+	if _, err := NewReadOnlyFile(path).Token(); err == nil {
+		t.Error("expected an error without a token file")
+	}
+}
@@ -0,0 +1,67 @@
+// Package auth manages the auth tokens required by Lighter's private endpoints
+// and WebSocket channels.
+//
+// Two providers are available:
+//   - Manager: mints tokens with a signing key (e.g. a *client.TxClient), caches
+//     them and refreshes them ahead of their deadline.
+//   - ReadOnly: serves a token minted elsewhere, for monitoring processes that
+//     must not hold the signing key. It can reload the token from a file that the
+//     signing process keeps up to date.
+//
+// Usage:
+//
+//	tokens := auth.NewManager(signerClient)
+//	httpClient := http.NewFullClientWithOptions(url, http.WithAuthProvider(tokens))
+//	wsClient := ws.NewClient(wsURL, ws.DefaultOptions().WithAuthProvider(tokens))
+//
+//	// An empty auth argument is filled in by the provider
+//	orders, err := httpClient.Order().GetActiveOrders(accountIndex, nil, "")
+//	err = wsClient.SubscribeAccountAll(accountIndex, "")
+package auth
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultLifetime is the lifetime of minted tokens; Lighter accepts at most 8 hours
+	DefaultLifetime = 8 * time.Hour
+	// DefaultRefreshBefore is how long before its deadline a token is replaced
+	DefaultRefreshBefore = 10 * time.Minute
+)
+
+var (
+	// ErrTokenExpired is returned when the only available token has expired
+	ErrTokenExpired = errors.New("auth token expired")
+	// ErrNoToken is returned when a read-only provider has no token to serve
+	ErrNoToken = errors.New("no auth token available")
+)
+
+// Provider supplies auth tokens for private endpoints and channels
+type Provider interface {
+	// Token returns a token that stays valid for a while; callers should not cache it
+	Token() (string, error)
+}
+
+// TokenSigner mints auth tokens; *client.TxClient implements it
+type TokenSigner interface {
+	GetAuthToken(deadline time.Time) (string, error)
+}
+
+// ParseDeadline returns the deadline of a token of the form
+// "<deadline>:<account index>:<api key index>:<signature>".
+// It reports false for other formats, such as read-only tokens.
+func ParseDeadline(token string) (time.Time, bool) {
+	parts := strings.Split(token, ":")
+	if len(parts) != 4 {
+		return time.Time{}, false
+	}
+	deadline, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(deadline, 0), true
+}
@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ReadOnly serves a token minted by another process, so that the process using it
+// never holds the signing key. A file-backed ReadOnly reloads the file when the token
+// is about to expire or was invalidated; the signing process keeps it current with
+// WriteTokenFile.
+type ReadOnly struct {
+	mu            sync.Mutex
+	load          func() (string, error) // nil for a static token
+	token         string
+	deadline      time.Time // zero if the token format carries no deadline
+	stale         bool
+	refreshBefore time.Duration
+	now           func() time.Time
+}
+
+// NewReadOnly creates a provider serving a fixed token
+func NewReadOnly(token string) *ReadOnly {
+	r := &ReadOnly{refreshBefore: DefaultRefreshBefore, now: time.Now}
+	r.set(token)
+	return r
+}
+
+// NewReadOnlyFile creates a provider serving the token stored in the file at path
+func NewReadOnlyFile(path string) *ReadOnly {
+	return &ReadOnly{
+		load: func() (string, error) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return "", fmt.Errorf("failed to read auth token: %w", err)
+			}
+			return strings.TrimSpace(string(data)), nil
+		},
+		stale:         true,
+		refreshBefore: DefaultRefreshBefore,
+		now:           time.Now,
+	}
+}
+
+// SetRefreshBefore sets how long before its deadline the token file is reloaded (default: 10m)
+func (r *ReadOnly) SetRefreshBefore(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.refreshBefore = d
+}
+
+// Token returns the current token. A file-backed provider keeps serving its last
+// token while the file cannot be read, until that token expires.
+func (r *ReadOnly) Token() (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	expiring := !r.deadline.IsZero() && !now.Add(r.refreshBefore).Before(r.deadline)
+
+	var loadErr error
+	if r.load != nil && (r.stale || expiring) {
+		token, err := r.load()
+		if err == nil {
+			r.set(token)
+		}
+		loadErr = err
+	}
+
+	switch {
+	case r.token == "" && loadErr != nil:
+		return "", loadErr
+	case r.token == "":
+		return "", ErrNoToken
+	case !r.deadline.IsZero() && !now.Before(r.deadline):
+		return "", ErrTokenExpired
+	}
+	return r.token, nil
+}
+
+// Invalidate makes a file-backed provider reload the file on the next call to Token
+func (r *ReadOnly) Invalidate() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stale = true
+}
+
+func (r *ReadOnly) set(token string) {
+	r.token = token
+	r.deadline, _ = ParseDeadline(token)
+	r.stale = false
+}
+
+// WriteTokenFile stores a token from p at path for a NewReadOnlyFile provider.
+// The file is replaced atomically and is readable by its owner only.
+func WriteTokenFile(path string, p Provider) error {
+	token, err := p.Token()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // Already renamed on success
+
+	if _, err := tmp.WriteString(token + "\n"); err != nil {
+		tmp.Close() //nolint:errcheck // Reporting the write error
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+var _ Provider = (*ReadOnly)(nil)
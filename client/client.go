@@ -96,7 +96,7 @@ func (c *TxClient) Check() error {
 	// check that the API key registered on Lighter matches this one
 	publicKey, err := c.HTTP().GetApiKey(c.accountIndex, c.apiKeyIndex)
 	if err != nil {
-		return fmt.Errorf("failed to get Api Keys. err: %v", err)
+		return fmt.Errorf("failed to get Api Keys. err: %w", err)
 	}
 
 	pubKeyBytes := c.GetKeyManager().PubKeyBytes()
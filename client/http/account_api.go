@@ -43,8 +43,8 @@ func (a *accountAPIImpl) GetAccountMetadata(by api.QueryBy, value string, auth s
 		"by":    string(by),
 		"value": value,
 	}
-	if auth != "" {
-		params["auth"] = auth
+	if err := a.client.setAuth(params, auth); err != nil {
+		return nil, err
 	}
 	err := a.client.getAndParseL2HTTPResponse("api/v1/accountMetadata", params, result)
 	if err != nil {
@@ -58,8 +58,8 @@ func (a *accountAPIImpl) GetAccountLimits(accountIndex int64, auth string) (*api
 	params := map[string]any{
 		"account_index": accountIndex,
 	}
-	if auth != "" {
-		params["auth"] = auth
+	if err := a.client.setAuth(params, auth); err != nil {
+		return nil, err
 	}
 	err := a.client.getAndParseL2HTTPResponse("api/v1/accountLimits", params, result)
 	if err != nil {
@@ -74,8 +74,8 @@ func (a *accountAPIImpl) GetLiquidations(accountIndex int64, limit int, auth str
 		"account_index": accountIndex,
 		"limit":         limit,
 	}
-	if auth != "" {
-		params["auth"] = auth
+	if err := a.client.setAuth(params, auth); err != nil {
+		return nil, err
 	}
 	if opts != nil {
 		if opts.MarketID != nil {
@@ -98,8 +98,8 @@ func (a *accountAPIImpl) GetPositionFunding(accountIndex int64, limit int, auth
 		"account_index": accountIndex,
 		"limit":         limit,
 	}
-	if auth != "" {
-		params["auth"] = auth
+	if err := a.client.setAuth(params, auth); err != nil {
+		return nil, err
 	}
 	if opts != nil {
 		if opts.MarketID != nil {
@@ -129,8 +129,8 @@ func (a *accountAPIImpl) GetPnL(accountIndex int64, resolution string, timestamp
 		"end_timestamp":   timestamps.EndTimestamp,
 		"count_back":      countBack,
 	}
-	if auth != "" {
-		params["auth"] = auth
+	if err := a.client.setAuth(params, auth); err != nil {
+		return nil, err
 	}
 	if ignoreTransfers {
 		params["ignore_transfers"] = true
@@ -149,8 +149,8 @@ func (a *accountAPIImpl) GetPublicPoolsMetadata(filter string, index int, limit
 		"index":  index,
 		"limit":  limit,
 	}
-	if auth != "" {
-		params["auth"] = auth
+	if err := a.client.setAuth(params, auth); err != nil {
+		return nil, err
 	}
 	if accountIndex != nil {
 		params["account_index"] = *accountIndex
@@ -167,7 +167,9 @@ func (a *accountAPIImpl) ChangeAccountTier(accountIndex int64, newTier string, a
 	body := map[string]any{
 		"account_index": accountIndex,
 		"new_tier":      newTier,
-		"auth":          auth,
+	}
+	if err := a.client.setAuth(body, auth); err != nil {
+		return nil, err
 	}
 	err := a.client.postAndParseL2HTTPResponse("api/v1/changeAccountTier", body, result)
 	if err != nil {
@@ -181,8 +183,8 @@ func (a *accountAPIImpl) GetL1Metadata(l1Address string, auth string) (*api.L1Me
 	params := map[string]any{
 		"l1_address": l1Address,
 	}
-	if auth != "" {
-		params["auth"] = auth
+	if err := a.client.setAuth(params, auth); err != nil {
+		return nil, err
 	}
 	err := a.client.getAndParseL2HTTPResponse("api/v1/l1Metadata", params, result)
 	if err != nil {
@@ -0,0 +1,27 @@
+package http
+
+import (
+	"github.com/0xJord4n/lighter-go/auth"
+)
+
+// WithAuthProvider fills in the auth token of private endpoints called without one.
+// The same provider can be shared with ws.Client, so that both use one cached token.
+func WithAuthProvider(p auth.Provider) Option {
+	return func(o *options) {
+		o.authProvider = p
+	}
+}
+
+// setAuth adds the auth param: the explicit token if given, otherwise one from the provider
+func (c *client) setAuth(params map[string]any, token string) error {
+	if token == "" && c.opts.authProvider != nil {
+		var err error
+		if token, err = c.opts.authProvider.Token(); err != nil {
+			return err
+		}
+	}
+	if token != "" {
+		params["auth"] = token
+	}
+	return nil
+}
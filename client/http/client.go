@@ -8,13 +8,15 @@
 //	client := http.NewFullClient("https://mainnet.zklighter.elliot.ai")
 //
 //	// Get account information
-//	account, err := client.Account().GetAccountByL1Address(ctx, "0x...")
+//	accounts, err := client.Account().GetAccountsByL1Address("0x...")
 //
 //	// Get order book
-//	orderbook, err := client.Order().GetOrderBook(ctx, 0, 20)
+//	orderBooks, err := client.Order().GetOrderBooks(&marketIndex, api.MarketFilterAll)
 //
-//	// Get candlestick data
-//	candles, err := client.Candlestick().GetCandlesticks(ctx, 0, "1h", 100)
+//	// Bind requests to a context for deadlines and cancellation
+//	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+//	defer cancel()
+//	candles, err := client.WithContext(ctx).Candlestick().GetCandlesticks(0, api.Resolution1h, timestamps, 100)
 //
 // API Groups:
 //   - Account(): Account and position information
@@ -27,6 +29,7 @@
 package http
 
 import (
+	"context"
 	"crypto/tls"
 	"net"
 	"net/http"
@@ -65,6 +68,11 @@ var _ core.FullHTTPClient = (*client)(nil)
 
 type client struct {
 	endpoint string
+	opts     *options
+	hc       *http.Client // resolved from opts
+
+	// ctx is attached to every outgoing request. Nil means context.Background().
+	ctx context.Context
 
 	// Lazy-initialized API groups
 	accountAPI     *accountAPIImpl
@@ -87,9 +95,7 @@ func NewClient(baseUrl string) core.MinimalHTTPClient {
 		return nil
 	}
 
-	return &client{
-		endpoint: baseUrl,
-	}
+	return newClient(baseUrl)
 }
 
 // NewFullClient creates a new HTTP client with full API access.
@@ -99,9 +105,27 @@ func NewFullClient(baseUrl string) core.FullHTTPClient {
 		return nil
 	}
 
-	return &client{
-		endpoint: baseUrl,
+	return newClient(baseUrl)
+}
+
+// NewFullClientWithOptions creates a new HTTP client with full API access
+// that owns its configuration. Clients created without transport or timeout
+// options share the default connection pool, like NewFullClient.
+//
+// Example:
+//
+//	client := http.NewFullClientWithOptions(types.Mainnet.APIURL(),
+//		http.WithTimeout(5*time.Second),
+//		http.WithProxy(proxyURL),
+//		http.WithUserAgent("my-bot/1.0"),
+//		http.WithHeader("X-Api-Key", gatewayKey),
+//	)
+func NewFullClientWithOptions(baseUrl string, opts ...Option) core.FullHTTPClient {
+	if baseUrl == "" {
+		return nil
 	}
+
+	return newClient(baseUrl, opts...)
 }
 
 // NewFullClientForNetwork creates a new HTTP client for the specified network.
@@ -114,6 +138,21 @@ func NewFullClientForNetwork(network types.Network) core.FullHTTPClient {
 	return NewFullClient(network.APIURL())
 }
 
+// NewFullClientForNetworkWithOptions creates a new HTTP client for the specified network
+// with the given options. See NewFullClientWithOptions.
+func NewFullClientForNetworkWithOptions(network types.Network, opts ...Option) core.FullHTTPClient {
+	return NewFullClientWithOptions(network.APIURL(), opts...)
+}
+
+func newClient(baseUrl string, opts ...Option) *client {
+	o := newOptions(opts...)
+	return &client{
+		endpoint: baseUrl,
+		opts:     o,
+		hc:       o.client(),
+	}
+}
+
 // Account returns the AccountAPI for account-related operations
 func (c *client) Account() core.AccountAPI {
 	c.mu.Lock()
@@ -191,6 +230,28 @@ func (c *client) Info() core.InfoAPI {
 	return c.infoAPI
 }
 
+// WithContext returns a copy of the client whose requests are bound to ctx.
+// The copy has its own lazily-initialized API groups, so the receiver is unaffected.
+func (c *client) WithContext(ctx context.Context) core.FullHTTPClient {
+	if ctx == nil {
+		panic("nil context")
+	}
+	return &client{
+		endpoint: c.endpoint,
+		opts:     c.opts,
+		hc:       c.hc,
+		ctx:      ctx,
+	}
+}
+
+// context returns the context requests should be made with
+func (c *client) context() context.Context {
+	if c.ctx != nil {
+		return c.ctx
+	}
+	return context.Background()
+}
+
 // Endpoint returns the base URL of the client
 func (c *client) Endpoint() string {
 	return c.endpoint
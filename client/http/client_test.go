@@ -0,0 +1,141 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	core "github.com/0xJord4n/lighter-go/client"
+	"github.com/0xJord4n/lighter-go/client/ws"
+	"github.com/0xJord4n/lighter-go/nonce"
+	"github.com/0xJord4n/lighter-go/types"
+	"github.com/0xJord4n/lighter-go/types/api"
+	"github.com/0xJord4n/lighter-go/types/txtypes"
+)
+
+const testChainId = 304
+
+// signedTx returns a cancel order transaction signed with a fresh key
+func signedTx(t *testing.T, nonce int64) txtypes.TxInfo {
+	t.Helper()
+	privateKey, _, _ := core.GenerateAPIKey()
+	txClient, err := core.NewTxClient(nil, privateKey, 7, 2, testChainId)
+	if err != nil {
+		t.Fatalf("NewTxClient failed: %v", err)
+	}
+	tx, err := txClient.GetCancelOrderTransaction(&types.CancelOrderTxReq{MarketIndex: 0, Index: 1}, &types.TransactOpts{Nonce: types.NewInt64(nonce)})
+	if err != nil {
+		t.Fatalf("GetCancelOrderTransaction failed: %v", err)
+	}
+	return tx
+}
+
+// slowServer holds every request until the client goes away
+func slowServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The server only notices the client going away once the body was read
+		io.Copy(io.Discard, r.Body) //nolint:errcheck
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// cancelSoon returns a context cancelled shortly after the request was sent
+func cancelSoon(t *testing.T) context.Context {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	time.AfterFunc(50*time.Millisecond, cancel)
+	return ctx
+}
+
+func TestWithContext_CancelsGet(t *testing.T) {
+	srv := slowServer(t)
+	c := NewFullClient(srv.URL).WithContext(cancelSoon(t))
+
+	start := time.Now()
+	if _, err := c.Info().GetStatus(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if time.Since(start) > 2*time.Second {
+		t.Error("expected the request aborted on cancellation")
+	}
+}
+
+func TestOrderBookFetcher_CancelsWithContext(t *testing.T) {
+	srv := slowServer(t)
+	fetcher, ok := NewFullClient(srv.URL).Order().(ws.ContextOrderBookFetcher)
+	if !ok {
+		t.Fatal("expected the Order API to implement ws.ContextOrderBookFetcher")
+	}
+
+	if _, err := fetcher.GetOrderBooksWithContext(cancelSoon(t), nil, api.MarketFilterAll); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestWithContext_CancelsSendSignedTx(t *testing.T) {
+	srv := slowServer(t)
+	c := NewFullClientWithOptions(srv.URL, WithSendTxRetryPolicy(DefaultRetryPolicy())).WithContext(cancelSoon(t))
+
+	if _, err := c.Transaction().SendSignedTx(signedTx(t, 5), nil, nil, nil, ""); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestWithContext_CancelsSignerClient(t *testing.T) {
+	srv := slowServer(t)
+	privateKey, _, _ := core.GenerateAPIKey()
+	signerClient, err := core.NewSignerClient(NewFullClient(srv.URL), privateKey, testChainId, 2, 7, nil)
+	if err != nil {
+		t.Fatalf("NewSignerClient failed: %v", err)
+	}
+
+	if _, err := signerClient.WithContext(cancelSoon(t)).SendAndSubmit(signedTx(t, 5)); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestSignerClient_AcknowledgesAPIErrors(t *testing.T) {
+	for name, tt := range map[string]struct {
+		response scriptedResponse
+		pending  int
+	}{
+		"rejected":     {response: scriptedResponse{status: http.StatusBadRequest, body: `{"code":21104,"message":"invalid nonce"}`}, pending: 0},
+		"server error": {response: respBadGateway, pending: 1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			srv := newScriptedServer(t, map[string][]scriptedResponse{
+				"/api/v1/nextNonce": {{status: http.StatusOK, body: `{"code":200,"nonce":5}`}},
+				"/api/v1/sendTx":    {tt.response},
+			})
+			httpClient := NewFullClient(srv.URL)
+			manager := nonce.NewOptimisticManager(httpClient)
+			privateKey, _, _ := core.GenerateAPIKey()
+			signerClient, err := core.NewSignerClient(httpClient, privateKey, testChainId, 2, 7, manager)
+			if err != nil {
+				t.Fatalf("NewSignerClient failed: %v", err)
+			}
+
+			tx, err := signerClient.GetCancelOrderTransaction(&types.CancelOrderTxReq{MarketIndex: 0, Index: 1}, nil)
+			if err != nil {
+				t.Fatalf("GetCancelOrderTransaction failed: %v", err)
+			}
+			if _, err := signerClient.SendAndSubmit(tx); err == nil {
+				t.Fatal("expected the submission to fail")
+			}
+			if n := manager.PendingCount(7, 2); n != tt.pending {
+				t.Errorf("expected %d pending nonces, got %d", tt.pending, n)
+			}
+		})
+	}
+}
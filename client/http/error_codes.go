@@ -0,0 +1,83 @@
+package http
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Sentinels for known Lighter errors, for use with errors.Is:
+//
+//	if errors.Is(err, http.ErrInvalidNonce) {
+//		nonceManager.Reset(accountIndex, apiKeyIndex)
+//	}
+//
+// Lighter publishes no table of its numeric error codes, so errors are matched by
+// the message they are reported with, whatever their code. Errors with other messages
+// still match the generic sentinels (ErrBadRequest, ...) by code or HTTP status.
+var (
+	ErrInvalidParam        error = knownError("invalid param")
+	ErrInvalidNonce        error = knownError("invalid nonce")
+	ErrInvalidSignature    error = knownError("invalid signature")
+	ErrInvalidExpiry       error = knownError("invalid expiry")
+	ErrAccountNotFound     error = knownError("account not found")
+	ErrApiKeyNotFound      error = knownError("api key not found")
+	ErrMarketNotFound      error = knownError("market not found")
+	ErrMarketHalted        error = knownError("market halted")
+	ErrOrderNotFound       error = knownError("order not found")
+	ErrInvalidOrderPrice   error = knownError("invalid order price")
+	ErrInvalidOrderAmount  error = knownError("invalid order amount")
+	ErrInsufficientMargin  error = knownError("insufficient margin")
+	ErrInsufficientBalance error = knownError("insufficient balance")
+	ErrReduceOnlyViolated  error = knownError("reduce only violated")
+	ErrTooManyPendingTxs   error = knownError("too many pending transactions")
+	ErrTooManyOpenOrders   error = knownError("too many open orders")
+)
+
+// knownError is the type of the known error sentinels. Being a value, a sentinel
+// can't be modified by the code matching against it.
+type knownError string
+
+// Error implements the error interface
+func (k knownError) Error() string {
+	return fmt.Sprintf("API error: %s", string(k))
+}
+
+// errorCatalogEntry maps the messages Lighter reports an error with to its sentinel
+type errorCatalogEntry struct {
+	sentinel error
+	messages []string
+}
+
+// errorCatalog lists the known errors and their messages
+var errorCatalog = []errorCatalogEntry{
+	{ErrInvalidNonce, []string{"invalid nonce", "nonce too low", "nonce too high"}},
+	{ErrInvalidSignature, []string{"invalid signature"}},
+	{ErrInvalidExpiry, []string{"invalid expiry"}},
+	{ErrApiKeyNotFound, []string{"api key not found"}},
+	{ErrAccountNotFound, []string{"account not found"}},
+	{ErrMarketHalted, []string{"market halted", "market is halted"}},
+	{ErrMarketNotFound, []string{"market not found"}},
+	{ErrOrderNotFound, []string{"order not found"}},
+	{ErrInvalidOrderPrice, []string{"invalid order price"}},
+	{ErrInvalidOrderAmount, []string{"invalid order amount"}},
+	{ErrInsufficientMargin, []string{"insufficient margin"}},
+	{ErrInsufficientBalance, []string{"insufficient balance"}},
+	{ErrReduceOnlyViolated, []string{"reduce only violated"}},
+	{ErrTooManyPendingTxs, []string{"too many pending transactions"}},
+	{ErrTooManyOpenOrders, []string{"too many open orders"}},
+	{ErrInvalidParam, []string{"invalid param"}},
+}
+
+// classify returns the catalog sentinel matching the message of e, or nil. The message
+// must be a known one, optionally followed by ": <details>".
+func classify(e *APIError) error {
+	msg := strings.ToLower(strings.TrimSpace(e.Message))
+	for _, entry := range errorCatalog {
+		for _, m := range entry.messages {
+			if msg == m || strings.HasPrefix(msg, m+":") {
+				return entry.sentinel
+			}
+		}
+	}
+	return nil
+}
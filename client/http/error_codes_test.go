@@ -0,0 +1,70 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestErrorCodes_GetAndPost(t *testing.T) {
+	tests := []struct {
+		name     string
+		response scriptedResponse
+		want     error
+		notWant  error
+	}{
+		{
+			name:     "result code",
+			response: scriptedResponse{status: http.StatusOK, body: `{"code":21104,"message":"invalid nonce"}`},
+			want:     ErrInvalidNonce,
+			notWant:  ErrInvalidSignature,
+		},
+		{
+			name:     "http status with details",
+			response: scriptedResponse{status: http.StatusBadRequest, body: `{"code":21733,"message":"Insufficient margin: 12.5 required"}`},
+			want:     ErrInsufficientMargin,
+			notWant:  ErrInvalidParam,
+		},
+		{
+			name:     "unknown message",
+			response: scriptedResponse{status: http.StatusBadRequest, body: `{"code":21733,"message":"not enough margin for this order"}`},
+			want:     ErrBadRequest,
+			notWant:  ErrInsufficientMargin,
+		},
+		{
+			name:     "generic code with known message",
+			response: scriptedResponse{status: http.StatusBadRequest, body: `{"code":400,"message":"Invalid nonce: expected 5"}`},
+			want:     ErrInvalidNonce,
+		},
+		{
+			name:     "generic code with other message",
+			response: scriptedResponse{status: http.StatusBadRequest, body: `{"code":400,"message":"order rejected, invalid nonce check skipped"}`},
+			want:     ErrBadRequest,
+			notWant:  ErrInvalidNonce,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newScriptedServer(t, map[string][]scriptedResponse{
+				"/api/v1/nextNonce": {tt.response},
+				"/api/v1/sendTx":    {tt.response},
+			})
+			c := NewFullClient(srv.URL)
+
+			_, getErr := c.GetNextNonce(7, 2)
+			_, postErr := c.Transaction().SendSignedTx(signedTx(t, 5), nil, nil, nil, "")
+			for method, err := range map[string]error{"GET": getErr, "POST": postErr} {
+				if _, ok := IsAPIError(err); !ok {
+					t.Fatalf("%s: expected an APIError, got %v", method, err)
+				}
+				if tt.want != nil && !errors.Is(err, tt.want) {
+					t.Errorf("%s: expected %v to match %v", method, err, tt.want)
+				}
+				if tt.notWant != nil && errors.Is(err, tt.notWant) {
+					t.Errorf("%s: expected %v not to match %v", method, err, tt.notWant)
+				}
+			}
+		})
+	}
+}
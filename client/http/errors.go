@@ -3,21 +3,47 @@ package http
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
-// APIError represents an error returned by the Lighter API
+// APIError represents an error returned by the Lighter API.
+// Every request path returns it both for non-200 HTTP responses and for
+// non-OK result codes, so it can be matched against the sentinels of this
+// package and the error code catalog with errors.Is.
 type APIError struct {
-	Code       int32  `json:"code"`
-	Message    string `json:"message"`
-	StatusCode int    `json:"-"` // HTTP status code
+	Code       int32          `json:"code"`
+	Message    string         `json:"message"`
+	StatusCode int            `json:"-"` // HTTP status code
+	RetryAfter time.Duration  `json:"-"` // From the Retry-After header, if any
+	Endpoint   string         `json:"-"` // Request path, e.g. "api/v1/sendTx"
+	Params     map[string]any `json:"-"` // Request parameters, with credentials redacted
 }
 
 // Error implements the error interface
 func (e *APIError) Error() string {
+	var msg string
 	if e.StatusCode != 0 && e.StatusCode != 200 {
-		return fmt.Sprintf("API error (HTTP %d, code %d): %s", e.StatusCode, e.Code, e.Message)
+		msg = fmt.Sprintf("API error (HTTP %d, code %d): %s", e.StatusCode, e.Code, e.Message)
+	} else {
+		msg = fmt.Sprintf("API error (code %d): %s", e.Code, e.Message)
 	}
-	return fmt.Sprintf("API error (code %d): %s", e.Code, e.Message)
+	if e.Endpoint != "" {
+		msg = fmt.Sprintf("%s: %s", e.Endpoint, msg)
+	}
+	return msg
+}
+
+// Is reports whether e matches target, so that errors.Is works with the
+// sentinels of this package. An APIError matches a known error sentinel whose
+// message it reports, and a generic sentinel with the same code or HTTP status.
+func (e *APIError) Is(target error) bool {
+	switch t := target.(type) {
+	case knownError:
+		return classify(e) == t
+	case *APIError:
+		return t.Code != 0 && (t.Code == e.Code || int(t.Code) == e.StatusCode)
+	}
+	return false
 }
 
 // IsNotFound returns true if this is a not found error
@@ -100,11 +126,15 @@ var (
 
 // ConnectionError represents a connection error
 type ConnectionError struct {
-	Err error
+	Endpoint string
+	Err      error
 }
 
 // Error implements the error interface
 func (e *ConnectionError) Error() string {
+	if e.Endpoint != "" {
+		return fmt.Sprintf("%s: connection error: %v", e.Endpoint, e.Err)
+	}
 	return fmt.Sprintf("connection error: %v", e.Err)
 }
 
@@ -113,6 +143,23 @@ func (e *ConnectionError) Unwrap() error {
 	return e.Err
 }
 
+// DecodeError is returned when a response body cannot be parsed
+type DecodeError struct {
+	Endpoint string
+	Body     []byte
+	Err      error
+}
+
+// Error implements the error interface
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("%s: failed to decode response: %v", e.Endpoint, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
 // ValidationError represents a request validation error
 type ValidationError struct {
 	Field   string
@@ -0,0 +1,154 @@
+package http
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/0xJord4n/lighter-go/auth"
+	"github.com/0xJord4n/lighter-go/ratelimit"
+)
+
+// Option configures a client created with NewFullClientWithOptions
+type Option func(*options)
+
+// options holds the per-client configuration.
+// It is shared (read-only) between a client and the copies returned by WithContext.
+type options struct {
+	httpClient   *http.Client      // set by WithHTTPClient, used as is
+	roundTripper http.RoundTripper // set by WithTransport
+	transport    *http.Transport   // per-client clone of the default transport, created by tuning options
+	timeout      time.Duration
+	headers      http.Header
+	basePath     string
+	retry        *RetryPolicy // read endpoints
+	sendTxRetry  *RetryPolicy // confirmed tx submission
+	limiter      *ratelimit.Limiter
+	authProvider auth.Provider
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{
+		headers: make(http.Header),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// client resolves the configured options into the *http.Client to use.
+// Clients that don't tune the transport or timeout share the package-level client.
+func (o *options) client() *http.Client {
+	if o.httpClient != nil {
+		return o.httpClient
+	}
+
+	var rt http.RoundTripper = transport
+	if o.roundTripper != nil {
+		rt = o.roundTripper
+	} else if o.transport != nil {
+		rt = o.transport
+	}
+
+	if rt == http.RoundTripper(transport) && o.timeout == 0 {
+		return httpClient
+	}
+
+	c := &http.Client{
+		Timeout:   httpClient.Timeout,
+		Transport: rt,
+	}
+	if o.timeout != 0 {
+		c.Timeout = o.timeout
+	}
+	return c
+}
+
+// ownTransport returns the per-client transport, cloning the default one on first use
+func (o *options) ownTransport() *http.Transport {
+	if o.transport == nil {
+		o.transport = transport.Clone()
+	}
+	return o.transport
+}
+
+// WithHTTPClient makes the client use the given *http.Client as is.
+// All other transport and timeout options are ignored when this option is set.
+func WithHTTPClient(c *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = c
+	}
+}
+
+// WithTransport sets a custom round tripper, e.g. for tracing or request signing.
+// Proxy, TLS and pooling options are ignored when this option is set.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(o *options) {
+		o.roundTripper = rt
+	}
+}
+
+// WithTimeout sets the overall timeout of a single request (default: 30s)
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// WithProxy routes requests through the given proxy URL
+func WithProxy(proxyURL *url.URL) Option {
+	return func(o *options) {
+		o.ownTransport().Proxy = http.ProxyURL(proxyURL)
+	}
+}
+
+// WithTLSConfig sets the TLS configuration, e.g. to use custom root CAs
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(o *options) {
+		o.ownTransport().TLSClientConfig = cfg
+	}
+}
+
+// WithMaxConnsPerHost limits the total number of connections per host (default: 1000)
+func WithMaxConnsPerHost(n int) Option {
+	return func(o *options) {
+		o.ownTransport().MaxConnsPerHost = n
+	}
+}
+
+// WithMaxIdleConnsPerHost sets the number of idle connections kept per host (default: 100)
+func WithMaxIdleConnsPerHost(n int) Option {
+	return func(o *options) {
+		o.ownTransport().MaxIdleConnsPerHost = n
+	}
+}
+
+// WithIdleConnTimeout sets how long idle connections are kept open (default: 10s)
+func WithIdleConnTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.ownTransport().IdleConnTimeout = d
+	}
+}
+
+// WithHeader adds a header sent with every request, e.g. an API gateway key
+func WithHeader(key, value string) Option {
+	return func(o *options) {
+		o.headers.Add(key, value)
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request
+func WithUserAgent(userAgent string) Option {
+	return func(o *options) {
+		o.headers.Set("User-Agent", userAgent)
+	}
+}
+
+// WithBasePath prefixes every request path, e.g. "/lighter" when the API sits behind a gateway
+func WithBasePath(basePath string) Option {
+	return func(o *options) {
+		o.basePath = basePath
+	}
+}
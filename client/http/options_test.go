@@ -0,0 +1,139 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingServer answers every request with an OK result and keeps the last request
+type recordingServer struct {
+	*httptest.Server
+	mu   sync.Mutex
+	last *http.Request
+}
+
+func newRecordingServer(t *testing.T) *recordingServer {
+	t.Helper()
+	srv := &recordingServer{}
+	srv.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		srv.mu.Lock()
+		srv.last = r.Clone(r.Context())
+		srv.mu.Unlock()
+		w.Write([]byte(`{"code":200}`)) //nolint:errcheck
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func (s *recordingServer) lastRequest(t *testing.T) *http.Request {
+	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.last == nil {
+		t.Fatal("expected a request")
+	}
+	return s.last
+}
+
+// countingTransport counts the requests it forwards
+type countingTransport struct {
+	mu    sync.Mutex
+	count int
+}
+
+func (t *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	t.count++
+	t.mu.Unlock()
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestOptions_HeadersAndBasePath(t *testing.T) {
+	srv := newRecordingServer(t)
+	c := NewFullClientWithOptions(srv.URL,
+		WithHeader("X-Api-Key", "gateway-key"),
+		WithUserAgent("my-bot/1.0"),
+		WithBasePath("/lighter"),
+	)
+
+	if _, err := c.GetNextNonce(7, 2); err != nil {
+		t.Fatalf("GetNextNonce failed: %v", err)
+	}
+	req := srv.lastRequest(t)
+	if got := req.Header.Get("X-Api-Key"); got != "gateway-key" {
+		t.Errorf("expected X-Api-Key header, got %q", got)
+	}
+	if got := req.Header.Get("User-Agent"); got != "my-bot/1.0" {
+		t.Errorf("expected User-Agent my-bot/1.0, got %q", got)
+	}
+	if req.URL.Path != "/lighter/api/v1/nextNonce" || req.URL.Query().Get("account_index") != "7" {
+		t.Errorf("expected the base path prefixed, got %s", req.URL)
+	}
+
+	// POST requests carry the headers and base path too
+	if _, err := c.Transaction().SendSignedTx(signedTx(t, 5), nil, nil, nil, ""); err != nil {
+		t.Fatalf("SendSignedTx failed: %v", err)
+	}
+	req = srv.lastRequest(t)
+	if req.URL.Path != "/lighter/api/v1/sendTx" || req.Header.Get("X-Api-Key") != "gateway-key" {
+		t.Errorf("expected POST to /lighter/api/v1/sendTx with headers, got %s %v", req.URL.Path, req.Header)
+	}
+}
+
+func TestOptions_Transport(t *testing.T) {
+	srv := newRecordingServer(t)
+
+	rt := &countingTransport{}
+	if _, err := NewFullClientWithOptions(srv.URL, WithTransport(rt)).Info().GetStatus(); err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if rt.count != 1 {
+		t.Errorf("expected the request through the custom transport, got %d", rt.count)
+	}
+
+	hcTransport := &countingTransport{}
+	hc := &http.Client{Transport: hcTransport}
+	if _, err := NewFullClientWithOptions(srv.URL, WithHTTPClient(hc), WithTransport(rt)).Info().GetStatus(); err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if hcTransport.count != 1 || rt.count != 1 {
+		t.Errorf("expected WithHTTPClient to take precedence, got %d and %d", hcTransport.count, rt.count)
+	}
+}
+
+func TestOptions_Proxy(t *testing.T) {
+	proxy := newRecordingServer(t)
+	proxyURL, _ := url.Parse(proxy.URL)
+
+	c := NewFullClientWithOptions("http://lighter.invalid", WithProxy(proxyURL))
+	if _, err := c.Info().GetStatus(); err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if req := proxy.lastRequest(t); req.Host != "lighter.invalid" {
+		t.Errorf("expected the request for lighter.invalid sent to the proxy, got host %s", req.Host)
+	}
+}
+
+func TestOptions_Timeout(t *testing.T) {
+	srv := slowServer(t)
+	c := NewFullClientWithOptions(srv.URL, WithTimeout(50*time.Millisecond))
+
+	start := time.Now()
+	_, err := c.Info().GetStatus()
+	if _, ok := err.(*ConnectionError); !ok {
+		t.Fatalf("expected a connection error, got %v", err)
+	}
+	if time.Since(start) > 2*time.Second {
+		t.Error("expected the request to time out")
+	}
+	if hc := newOptions(WithTimeout(time.Second)).client(); hc == httpClient || hc.Timeout != time.Second {
+		t.Error("expected a dedicated client with the timeout")
+	}
+	if newOptions().client() != httpClient {
+		t.Error("expected clients without options to share the default client")
+	}
+}
@@ -1,6 +1,8 @@
 package http
 
 import (
+	"context"
+
 	core "github.com/0xJord4n/lighter-go/client"
 	"github.com/0xJord4n/lighter-go/types/api"
 )
@@ -20,8 +22,8 @@ func (o *orderAPIImpl) GetActiveOrders(accountIndex int64, marketID *int16, auth
 	if marketID != nil {
 		params["market_id"] = *marketID
 	}
-	if auth != "" {
-		params["auth"] = auth
+	if err := o.client.setAuth(params, auth); err != nil {
+		return nil, err
 	}
 	err := o.client.getAndParseL2HTTPResponse("api/v1/accountActiveOrders", params, result)
 	if err != nil {
@@ -62,6 +64,12 @@ func (o *orderAPIImpl) GetInactiveOrders(accountIndex int64, marketID *int16, op
 	return result, nil
 }
 
+// GetOrderBooksWithContext is like GetOrderBooks, but bound to ctx.
+// It lets a websocket client stop an order book resync when it closes.
+func (o *orderAPIImpl) GetOrderBooksWithContext(ctx context.Context, marketID *int16, filter api.MarketFilter) (*api.OrderBooks, error) {
+	return o.client.WithContext(ctx).Order().GetOrderBooks(marketID, filter)
+}
+
 func (o *orderAPIImpl) GetOrderBooks(marketID *int16, filter api.MarketFilter) (*api.OrderBooks, error) {
 	result := &api.OrderBooks{}
 	params := map[string]any{}
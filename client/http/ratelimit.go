@@ -0,0 +1,81 @@
+package http
+
+import (
+	"strconv"
+
+	"github.com/0xJord4n/lighter-go/ratelimit"
+	"github.com/bytedance/sonic"
+)
+
+// WithRateLimiter throttles requests client-side using the given limiter.
+// The same limiter can be shared with other clients, including ws.Client,
+// so that they draw from a common budget.
+func WithRateLimiter(l *ratelimit.Limiter) Option {
+	return func(o *options) {
+		o.limiter = l
+	}
+}
+
+// acquire charges a request against the rate limiter, if one is configured.
+// Batches are charged once per transaction.
+func (c *client) acquire(path string, accountIndex int64, txCount int) error {
+	if c.opts.limiter == nil {
+		return nil
+	}
+	return c.opts.limiter.AcquireN(c.context(), path, accountIndex, txCount)
+}
+
+// txCount returns the number of transactions a request submits, 1 for other requests
+func txCount(path string, params map[string]any) int {
+	if path != ratelimit.EndpointSendTxBatch {
+		return 1
+	}
+	if txInfos, ok := params["tx_infos"].([]string); ok && len(txInfos) > 0 {
+		return len(txInfos)
+	}
+	return 1
+}
+
+// txAccountIndex returns the account a transaction submission is charged to:
+// the account_index param if given, otherwise the account found in the tx info.
+// Other requests only count against the IP budget.
+func txAccountIndex(path string, params map[string]any) int64 {
+	if path != ratelimit.EndpointSendTx && path != ratelimit.EndpointSendTxBatch {
+		return ratelimit.NoAccount
+	}
+
+	switch v := params["account_index"].(type) {
+	case int64:
+		return v
+	case string:
+		if idx, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return idx
+		}
+	}
+
+	if txInfo, ok := params["tx_info"].(string); ok {
+		return txInfoAccountIndex(txInfo)
+	}
+	if txInfos, ok := params["tx_infos"].([]string); ok && len(txInfos) > 0 {
+		return txInfoAccountIndex(txInfos[0])
+	}
+	return ratelimit.NoAccount
+}
+
+// txInfoAccountIndex extracts the sending account from a serialized tx info
+func txInfoAccountIndex(txInfo string) int64 {
+	var fields struct {
+		AccountIndex     *int64 `json:"AccountIndex"`
+		FromAccountIndex *int64 `json:"FromAccountIndex"`
+	}
+	if err := sonic.UnmarshalString(txInfo, &fields); err != nil {
+		return ratelimit.NoAccount
+	}
+	if fields.AccountIndex != nil {
+		return *fields.AccountIndex
+	}
+	if fields.FromAccountIndex != nil {
+		return *fields.FromAccountIndex
+	}
+	return ratelimit.NoAccount
+}
@@ -0,0 +1,32 @@
+package http
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/0xJord4n/lighter-go/ratelimit"
+	"github.com/0xJord4n/lighter-go/types/txtypes"
+)
+
+func TestRateLimiter_BatchChargedPerTx(t *testing.T) {
+	srv := newRecordingServer(t)
+	cfg := ratelimit.DefaultConfig()
+	cfg.Account = ratelimit.Limit{Weight: 1000, Per: 24 * time.Hour}
+	limiter := ratelimit.New(cfg)
+	c := NewFullClientWithOptions(srv.URL, WithRateLimiter(limiter))
+
+	if _, err := c.Transaction().SendSignedTx(signedTx(t, 1), nil, nil, nil, ""); err != nil {
+		t.Fatalf("SendSignedTx failed: %v", err)
+	}
+	single := 1000 - limiter.Remaining(7).Account
+
+	txs := []txtypes.TxInfo{signedTx(t, 2), signedTx(t, 3), signedTx(t, 4)}
+	if _, err := c.Transaction().SendSignedTxBatch(txs); err != nil {
+		t.Fatalf("SendSignedTxBatch failed: %v", err)
+	}
+	// The bucket barely refills between the calls
+	if charged := 1000 - limiter.Remaining(7).Account - single; math.Abs(charged-3*single) > 0.5 {
+		t.Errorf("expected a batch of 3 charged %v, got %v", 3*single, charged)
+	}
+}
@@ -2,60 +2,151 @@ package http
 
 import (
 	"bytes"
-	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
 	"net/url"
+	"path"
 
+	"github.com/0xJord4n/lighter-go/ratelimit"
 	"github.com/bytedance/sonic"
 )
 
 func (c *client) parseResultStatus(respBody []byte) error {
 	resultStatus := &ResultCode{}
 	if err := sonic.Unmarshal(respBody, resultStatus); err != nil {
-		return err
+		return &DecodeError{Body: respBody, Err: err}
 	}
 	if resultStatus.Code != CodeOK {
-		return errors.New(resultStatus.Message)
+		return NewAPIError(resultStatus.Code, resultStatus.Message)
 	}
 	return nil
 }
 
-func (c *client) getAndParseL2HTTPResponse(path string, params map[string]any, result interface{}) error {
+// buildURL resolves a request path against the endpoint and the configured base path
+func (c *client) buildURL(p string) (*url.URL, error) {
 	u, err := url.Parse(c.endpoint)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	u.Path = path
-
-	q := u.Query()
-	for k, v := range params {
-		q.Set(k, fmt.Sprintf("%v", v))
+	if c.opts.basePath != "" {
+		p = path.Join("/", c.opts.basePath, p)
 	}
-	u.RawQuery = q.Encode()
-	resp, err := httpClient.Get(u.String())
+	u.Path = p
+	return u, nil
+}
+
+// newRequest creates a request bound to the client context, carrying the default headers
+func (c *client) newRequest(method string, u *url.URL, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(c.context(), method, u.String(), body)
 	if err != nil {
+		return nil, err
+	}
+	for k, v := range c.opts.headers {
+		req.Header[k] = append([]string(nil), v...)
+	}
+	return req, nil
+}
+
+// newResponseError builds the error returned for a non-200 HTTP response,
+// keeping the Lighter result code if the body carries one
+func newResponseError(resp *http.Response, body []byte) *APIError {
+	apiErr := NewAPIErrorWithStatus(int32(resp.StatusCode), string(body), resp.StatusCode)
+	resultStatus := &ResultCode{}
+	if err := sonic.Unmarshal(body, resultStatus); err == nil && resultStatus.Code != 0 {
+		apiErr.Code = resultStatus.Code
+		apiErr.Message = resultStatus.Message
+	}
+	apiErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+	return apiErr
+}
+
+// do sends req once the rate limiter admits it and parses the response into result.
+// All failures are returned as *ConnectionError, *APIError or *DecodeError carrying the endpoint.
+func (c *client) do(req *http.Request, path string, params map[string]any, accountIndex int64, result interface{}) error {
+	err := c.doOnce(req, path, accountIndex, txCount(path, params), result)
+	switch e := err.(type) {
+	case *APIError:
+		e.Endpoint = path
+		e.Params = redactParams(params)
+	case *ConnectionError:
+		e.Endpoint = path
+	case *DecodeError:
+		e.Endpoint = path
+	}
+	return err
+}
+
+func (c *client) doOnce(req *http.Request, path string, accountIndex int64, txCount int, result interface{}) error {
+	if err := c.acquire(path, accountIndex, txCount); err != nil {
 		return err
 	}
+
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return &ConnectionError{Err: err}
+	}
 	defer resp.Body.Close() //nolint:errcheck // Response body close errors are non-actionable
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return err
+		return &ConnectionError{Err: err}
 	}
 	if resp.StatusCode != http.StatusOK {
-		return errors.New(string(body))
+		return newResponseError(resp, body)
 	}
 	if err = c.parseResultStatus(body); err != nil {
 		return err
 	}
 	if err := sonic.Unmarshal(body, result); err != nil {
-		return err
+		return &DecodeError{Body: body, Err: err}
 	}
 	return nil
 }
 
+// redactParams copies request params for error reports, hiding credentials
+func redactParams(params map[string]any) map[string]any {
+	if params == nil {
+		return nil
+	}
+	redacted := make(map[string]any, len(params))
+	for k, v := range params {
+		if k == "auth" {
+			v = "<redacted>"
+		}
+		redacted[k] = v
+	}
+	return redacted
+}
+
+// getAndParseL2HTTPResponse sends a GET request and parses the response,
+// retrying transient failures according to the client retry policy
+func (c *client) getAndParseL2HTTPResponse(path string, params map[string]any, result interface{}) error {
+	return c.withRetry(c.opts.retry, func() error {
+		return c.getAndParseL2HTTPResponseOnce(path, params, result)
+	})
+}
+
+func (c *client) getAndParseL2HTTPResponseOnce(path string, params map[string]any, result interface{}) error {
+	u, err := c.buildURL(path)
+	if err != nil {
+		return err
+	}
+
+	q := u.Query()
+	for k, v := range params {
+		q.Set(k, fmt.Sprintf("%v", v))
+	}
+	u.RawQuery = q.Encode()
+
+	req, err := c.newRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	return c.do(req, path, params, ratelimit.NoAccount, result)
+}
+
 func (c *client) GetNextNonce(accountIndex int64, apiKeyIndex uint8) (int64, error) {
 	result := &NextNonce{}
 	err := c.getAndParseL2HTTPResponse("api/v1/nextNonce", map[string]any{"account_index": accountIndex, "api_key_index": apiKeyIndex}, result)
@@ -79,55 +170,32 @@ func (c *client) GetApiKey(accountIndex int64, apiKeyIndex uint8) (string, error
 
 // postAndParseL2HTTPResponse sends a POST request with JSON body and parses the response
 func (c *client) postAndParseL2HTTPResponse(path string, body interface{}, result interface{}) error {
-	u, err := url.Parse(c.endpoint)
+	u, err := c.buildURL(path)
 	if err != nil {
 		return err
 	}
-	u.Path = path
 
 	jsonBody, err := sonic.Marshal(body)
 	if err != nil {
 		return fmt.Errorf("failed to marshal request body: %w", err)
 	}
 
-	req, err := http.NewRequest(http.MethodPost, u.String(), bytes.NewReader(jsonBody))
+	req, err := c.newRequest(http.MethodPost, u, bytes.NewReader(jsonBody))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := httpClient.Do(req)
-	if err != nil {
-		return &ConnectionError{Err: err}
-	}
-	defer resp.Body.Close() //nolint:errcheck // Response body close errors are non-actionable
-
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return NewAPIErrorWithStatus(int32(resp.StatusCode), string(respBody), resp.StatusCode)
-	}
-
-	if err = c.parseResultStatus(respBody); err != nil {
-		return err
-	}
-
-	if err := sonic.Unmarshal(respBody, result); err != nil {
-		return fmt.Errorf("failed to unmarshal response: %w", err)
-	}
-	return nil
+	params, _ := body.(map[string]any)
+	return c.do(req, path, params, txAccountIndex(path, params), result)
 }
 
 // postFormL2HTTPResponse sends a POST request with multipart/form-data body and parses the response
 func (c *client) postFormL2HTTPResponse(path string, params map[string]any, result interface{}) error {
-	u, err := url.Parse(c.endpoint)
+	u, err := c.buildURL(path)
 	if err != nil {
 		return err
 	}
-	u.Path = path
 
 	// Build multipart form data (matching Python SDK's multipart/form-data)
 	var body bytes.Buffer
@@ -141,33 +209,11 @@ func (c *client) postFormL2HTTPResponse(path string, params map[string]any, resu
 		return fmt.Errorf("failed to close multipart writer: %w", err)
 	}
 
-	req, err := http.NewRequest(http.MethodPost, u.String(), &body)
+	req, err := c.newRequest(http.MethodPost, u, &body)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 
-	resp, err := httpClient.Do(req)
-	if err != nil {
-		return &ConnectionError{Err: err}
-	}
-	defer resp.Body.Close() //nolint:errcheck // Response body close errors are non-actionable
-
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return NewAPIErrorWithStatus(int32(resp.StatusCode), string(respBody), resp.StatusCode)
-	}
-
-	if err = c.parseResultStatus(respBody); err != nil {
-		return err
-	}
-
-	if err := sonic.Unmarshal(respBody, result); err != nil {
-		return fmt.Errorf("failed to unmarshal response: %w", err)
-	}
-	return nil
+	return c.do(req, path, params, txAccountIndex(path, params), result)
 }
@@ -0,0 +1,166 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// errTxsAlreadyKnown signals that a failed submission actually reached Lighter
+var errTxsAlreadyKnown = errors.New("transactions already known")
+
+// RetryPolicy controls how failed requests are retried.
+//
+// Read endpoints (GET) are retried whenever Retryable reports true.
+// Transaction submission uses a stricter scheme: before resending, the client
+// looks up the signed tx hashes via GetTx and only resends if none of them
+// reached Lighter, so a retry can never double-submit.
+type RetryPolicy struct {
+	MaxAttempts    int           // Total attempts including the first one. Values <= 1 disable retries.
+	InitialBackoff time.Duration // Delay before the first retry
+	MaxBackoff     time.Duration // Upper bound for the delay between attempts
+	Multiplier     float64       // Backoff growth factor per attempt
+	Jitter         float64       // Fraction of the delay that is randomized, in [0, 1]
+
+	// Retryable reports whether a failed attempt may be retried. Defaults to IsRetryable.
+	Retryable func(err error) bool
+}
+
+// DefaultRetryPolicy returns a policy with 3 attempts and exponential backoff
+// from 100ms up to 2s, with 20% jitter
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     2 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+// WithRetryPolicy enables retries for read (GET) endpoints
+func WithRetryPolicy(p *RetryPolicy) Option {
+	return func(o *options) {
+		o.retry = p
+	}
+}
+
+// WithSendTxRetryPolicy enables confirmed retries for SendSignedTx and SendSignedTxBatch.
+// A failed submission is only resent after GetTx confirms that none of the signed
+// tx hashes are known to Lighter. SendTx and SendTxWithIndices are never retried,
+// as their tx hash is unknown to the client.
+func WithSendTxRetryPolicy(p *RetryPolicy) Option {
+	return func(o *options) {
+		o.sendTxRetry = p
+	}
+}
+
+// IsRetryable reports whether err is a transient failure: a connection error,
+// a rate limit or a 500/502/503/504 response. Context cancellation is never retryable.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var connErr *ConnectionError
+	if errors.As(err, &connErr) {
+		return true
+	}
+
+	if apiErr, ok := IsAPIError(err); ok {
+		if apiErr.IsRateLimited() {
+			return true
+		}
+		switch apiErr.StatusCode {
+		case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+	}
+	return false
+}
+
+func (p *RetryPolicy) retryable(err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return IsRetryable(err)
+}
+
+// backoff returns the delay before the given retry (1-based), honoring Retry-After
+// up to MaxBackoff, so a bogus header can't stall a call
+func (p *RetryPolicy) backoff(retry int, err error) time.Duration {
+	if apiErr, ok := IsAPIError(err); ok && apiErr.RetryAfter > 0 {
+		if p.MaxBackoff > 0 && apiErr.RetryAfter > p.MaxBackoff {
+			return p.MaxBackoff
+		}
+		return apiErr.RetryAfter
+	}
+
+	d := float64(p.InitialBackoff)
+	for i := 1; i < retry; i++ {
+		d *= p.Multiplier
+	}
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		d += d * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(d)
+}
+
+// withRetry runs fn until it succeeds, fails with a non-retryable error or runs out of attempts
+func (c *client) withRetry(p *RetryPolicy, fn func() error) error {
+	err := fn()
+	if p == nil {
+		return err
+	}
+	for attempt := 1; err != nil && attempt < p.MaxAttempts && p.retryable(err); attempt++ {
+		if sleepErr := c.sleep(p.backoff(attempt, err)); sleepErr != nil {
+			return err
+		}
+		err = fn()
+	}
+	return err
+}
+
+// sleep waits for d or until the client context is done
+func (c *client) sleep(d time.Duration) error {
+	ctx := c.context()
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// isTxNotFound reports whether err is GetTx's answer for an unknown tx hash.
+// Only a 404 status or code counts: any other failure leaves the tx status unknown,
+// and resending it could double-submit.
+func isTxNotFound(err error) bool {
+	apiErr, ok := IsAPIError(err)
+	return ok && apiErr.IsNotFound()
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(h string) time.Duration {
+	if h == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(h); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(h); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
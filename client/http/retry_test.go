@@ -0,0 +1,158 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fastRetryPolicy retries without waiting long
+func fastRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond, Multiplier: 2}
+}
+
+// scriptedServer answers each request path with the next scripted response,
+// repeating the last one, and counts the requests per path
+type scriptedServer struct {
+	*httptest.Server
+	mu        sync.Mutex
+	responses map[string][]scriptedResponse
+	calls     map[string]int
+}
+
+type scriptedResponse struct {
+	status int
+	header http.Header
+	body   string
+}
+
+func newScriptedServer(t *testing.T, responses map[string][]scriptedResponse) *scriptedServer {
+	t.Helper()
+	srv := &scriptedServer{responses: responses, calls: map[string]int{}}
+	srv.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		srv.mu.Lock()
+		script := srv.responses[r.URL.Path]
+		i := srv.calls[r.URL.Path]
+		srv.calls[r.URL.Path]++
+		srv.mu.Unlock()
+
+		if len(script) == 0 {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotImplemented)
+			return
+		}
+		resp := script[min(i, len(script)-1)]
+		for k, v := range resp.header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(resp.status)
+		w.Write([]byte(resp.body)) //nolint:errcheck
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func (s *scriptedServer) count(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[path]
+}
+
+var (
+	respOK         = scriptedResponse{status: http.StatusOK, body: `{"code":200}`}
+	respBadGateway = scriptedResponse{status: http.StatusBadGateway, body: `bad gateway`}
+	respNotFound   = scriptedResponse{status: http.StatusNotFound, body: `{"code":404,"message":"tx not found"}`}
+)
+
+func TestRetry_GetRetriesTransientFailures(t *testing.T) {
+	srv := newScriptedServer(t, map[string][]scriptedResponse{
+		"/api/v1/nextNonce": {
+			{status: http.StatusTooManyRequests, header: http.Header{"Retry-After": {"3600"}}, body: `{"code":429,"message":"too many requests"}`},
+			respBadGateway,
+			{status: http.StatusOK, body: `{"code":200,"nonce":12}`},
+		},
+	})
+	c := NewFullClientWithOptions(srv.URL, WithRetryPolicy(fastRetryPolicy()))
+
+	// The hour-long Retry-After is clamped to MaxBackoff
+	start := time.Now()
+	nonce, err := c.GetNextNonce(7, 2)
+	if err != nil {
+		t.Fatalf("GetNextNonce failed: %v", err)
+	}
+	if nonce != 12 || srv.count("/api/v1/nextNonce") != 3 {
+		t.Errorf("expected nonce 12 after 3 attempts, got %d after %d", nonce, srv.count("/api/v1/nextNonce"))
+	}
+	if time.Since(start) > 2*time.Second {
+		t.Error("expected Retry-After clamped to MaxBackoff")
+	}
+
+	// Without a policy, the first failure is returned
+	srv = newScriptedServer(t, map[string][]scriptedResponse{"/api/v1/nextNonce": {respBadGateway}})
+	if _, err := NewFullClient(srv.URL).GetNextNonce(7, 2); err == nil || srv.count("/api/v1/nextNonce") != 1 {
+		t.Errorf("expected a single failed attempt, got %v after %d", err, srv.count("/api/v1/nextNonce"))
+	}
+}
+
+func TestRetry_SendSkipsKnownTx(t *testing.T) {
+	srv := newScriptedServer(t, map[string][]scriptedResponse{
+		"/api/v1/sendTx": {respBadGateway, respOK},
+		"/api/v1/tx":     {respOK},
+	})
+	c := NewFullClientWithOptions(srv.URL, WithSendTxRetryPolicy(fastRetryPolicy()))
+
+	tx := signedTx(t, 5)
+	resp, err := c.Transaction().SendSignedTx(tx, nil, nil, nil, "")
+	if err != nil {
+		t.Fatalf("expected the known tx reported as sent, got %v", err)
+	}
+	if resp.TxHash != tx.GetTxHash() {
+		t.Errorf("expected tx hash %s, got %s", tx.GetTxHash(), resp.TxHash)
+	}
+	if n := srv.count("/api/v1/sendTx"); n != 1 {
+		t.Errorf("expected no resend of a known tx, got %d submissions", n)
+	}
+}
+
+func TestRetry_SendResendsAbsentTx(t *testing.T) {
+	srv := newScriptedServer(t, map[string][]scriptedResponse{
+		"/api/v1/sendTx": {respBadGateway, respOK},
+		"/api/v1/tx":     {respNotFound},
+	})
+	c := NewFullClientWithOptions(srv.URL, WithSendTxRetryPolicy(fastRetryPolicy()))
+
+	if _, err := c.Transaction().SendSignedTx(signedTx(t, 5), nil, nil, nil, ""); err != nil {
+		t.Fatalf("SendSignedTx failed: %v", err)
+	}
+	if n, lookups := srv.count("/api/v1/sendTx"), srv.count("/api/v1/tx"); n != 2 || lookups != 1 {
+		t.Errorf("expected a resend after one lookup, got %d submissions and %d lookups", n, lookups)
+	}
+}
+
+func TestRetry_SendAbortsOnLookupError(t *testing.T) {
+	for name, lookup := range map[string]scriptedResponse{
+		"server error": {status: http.StatusInternalServerError, body: `internal error`},
+		// A "not found" message without a 404 status or code leaves the tx status unknown
+		"not found message": {status: http.StatusBadRequest, body: `{"code":21500,"message":"account not found"}`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			srv := newScriptedServer(t, map[string][]scriptedResponse{
+				"/api/v1/sendTx": {respBadGateway, respOK},
+				"/api/v1/tx":     {lookup},
+			})
+			c := NewFullClientWithOptions(srv.URL, WithSendTxRetryPolicy(fastRetryPolicy()))
+
+			_, err := c.Transaction().SendSignedTx(signedTx(t, 5), nil, nil, nil, "")
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway {
+				t.Fatalf("expected the original 502 error, got %v", err)
+			}
+			if n := srv.count("/api/v1/sendTx"); n != 1 {
+				t.Errorf("expected no resend, got %d submissions", n)
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	core "github.com/0xJord4n/lighter-go/client"
 	"github.com/0xJord4n/lighter-go/types/api"
+	"github.com/0xJord4n/lighter-go/types/txtypes"
 )
 
 type transactionAPIImpl struct {
@@ -59,6 +60,118 @@ func (t *transactionAPIImpl) SendTxBatch(txTypes []uint8, txInfos []string) (*ap
 	return result, nil
 }
 
+// SendSignedTx sends a signed transaction, retrying according to the send tx retry policy
+func (t *transactionAPIImpl) SendSignedTx(tx txtypes.TxInfo, priceProtection *api.PriceProtection, accountIndex *int64, apiKeyIndex *uint8, auth string) (*api.RespSendTx, error) {
+	txInfo, err := tx.GetTxInfo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize tx info: %w", err)
+	}
+
+	var result *api.RespSendTx
+	err = t.sendConfirmed([]string{tx.GetTxHash()}, func() error {
+		var err error
+		result, err = t.SendTxWithIndices(tx.GetTxType(), txInfo, priceProtection, accountIndex, apiKeyIndex, auth)
+		return err
+	})
+	if err == errTxsAlreadyKnown {
+		return &api.RespSendTx{
+			BaseResponse: api.BaseResponse{Code: api.CodeOK},
+			TxHash:       tx.GetTxHash(),
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SendSignedTxBatch sends multiple signed transactions, retrying according to the send tx retry policy
+func (t *transactionAPIImpl) SendSignedTxBatch(txs []txtypes.TxInfo) (*api.RespSendTxBatch, error) {
+	txTypes := make([]uint8, len(txs))
+	txInfos := make([]string, len(txs))
+	hashes := make([]string, len(txs))
+	for i, tx := range txs {
+		txInfo, err := tx.GetTxInfo()
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize tx %d: %w", i, err)
+		}
+		txTypes[i] = tx.GetTxType()
+		txInfos[i] = txInfo
+		hashes[i] = tx.GetTxHash()
+	}
+
+	var result *api.RespSendTxBatch
+	err := t.sendConfirmed(hashes, func() error {
+		var err error
+		result, err = t.SendTxBatch(txTypes, txInfos)
+		return err
+	})
+	if err == errTxsAlreadyKnown {
+		return &api.RespSendTxBatch{
+			BaseResponse: api.BaseResponse{Code: api.CodeOK},
+			TxHashes:     hashes,
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// sendConfirmed runs send and, on a retryable failure, only resends once GetTx
+// confirms that none of the hashes reached Lighter. If all of them did, the
+// previous attempt succeeded despite the error and errTxsAlreadyKnown is returned.
+// If only some did, or their status can't be determined, the original error is returned.
+func (t *transactionAPIImpl) sendConfirmed(hashes []string, send func() error) error {
+	p := t.client.opts.sendTxRetry
+	err := send()
+	if p == nil {
+		return err
+	}
+	for _, hash := range hashes {
+		if hash == "" {
+			// Unsigned tx, its presence can't be checked
+			return err
+		}
+	}
+
+	for attempt := 1; err != nil && attempt < p.MaxAttempts && p.retryable(err); attempt++ {
+		if sleepErr := t.client.sleep(p.backoff(attempt, err)); sleepErr != nil {
+			return err
+		}
+
+		known, lookupErr := t.countKnownTxs(hashes)
+		if lookupErr != nil {
+			return err
+		}
+		if known == len(hashes) {
+			return errTxsAlreadyKnown
+		}
+		if known > 0 {
+			return err
+		}
+
+		err = send()
+	}
+	return err
+}
+
+// countKnownTxs returns how many of the hashes Lighter knows about
+func (t *transactionAPIImpl) countKnownTxs(hashes []string) (int, error) {
+	known := 0
+	for _, hash := range hashes {
+		_, err := t.GetTx(api.QueryByHash, hash)
+		if err == nil {
+			known++
+			continue
+		}
+		if !isTxNotFound(err) {
+			return 0, err
+		}
+	}
+	return known, nil
+}
+
 func (t *transactionAPIImpl) GetTx(by api.QueryBy, value string) (*api.EnrichedTx, error) {
 	result := &api.EnrichedTx{}
 	err := t.client.getAndParseL2HTTPResponse("api/v1/tx", map[string]any{
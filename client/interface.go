@@ -1,7 +1,10 @@
 package client
 
 import (
+	"context"
+
 	"github.com/0xJord4n/lighter-go/types/api"
+	"github.com/0xJord4n/lighter-go/types/txtypes"
 )
 
 // MinimalHTTPClient is the minimal interface for HTTP operations.
@@ -24,6 +27,11 @@ type FullHTTPClient interface {
 	Block() BlockAPI
 	Bridge() BridgeAPI
 	Info() InfoAPI
+
+	// WithContext returns a shallow copy of the client whose requests are bound to ctx.
+	// Cancelling ctx or hitting its deadline aborts any in-flight request made through
+	// the returned client or the API groups it hands out.
+	WithContext(ctx context.Context) FullHTTPClient
 }
 
 // AccountAPI provides access to account-related endpoints
@@ -133,6 +141,14 @@ type TransactionAPI interface {
 	// SendTxBatch submits multiple transactions
 	SendTxBatch(txTypes []uint8, txInfos []string) (*api.RespSendTxBatch, error)
 
+	// SendSignedTx submits a signed transaction with optional account and API key indices.
+	// As the tx hash is known up front, a failed submission can be safely retried
+	// once the hash is confirmed absent on Lighter.
+	SendSignedTx(tx txtypes.TxInfo, priceProtection *api.PriceProtection, accountIndex *int64, apiKeyIndex *uint8, auth string) (*api.RespSendTx, error)
+
+	// SendSignedTxBatch submits multiple signed transactions, retrying like SendSignedTx
+	SendSignedTxBatch(txs []txtypes.TxInfo) (*api.RespSendTxBatch, error)
+
 	// GetTx retrieves a transaction by hash or sequence index
 	GetTx(by api.QueryBy, value string) (*api.EnrichedTx, error)
 
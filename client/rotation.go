@@ -0,0 +1,164 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/0xJord4n/lighter-go/auth"
+	"github.com/0xJord4n/lighter-go/signer"
+	"github.com/0xJord4n/lighter-go/types"
+)
+
+var (
+	// RotationPollInterval is how often RotateAPIKey checks whether Lighter reports the new key
+	RotationPollInterval = time.Second
+
+	// ErrKeyNotManaged is returned when rotating an api key the client does not sign with
+	ErrKeyNotManaged = errors.New("api key is not used by this client")
+	// ErrRemoteKeyRotation is returned when rotating an api key held by a remote signer, such
+	// as remote.KeyManager: the new key would be generated and kept in this process
+	ErrRemoteKeyRotation = errors.New("api key is held by a remote signer, rotate it where it is kept")
+	// ErrRotationNotConfirmed is returned when the new key was submitted, but Lighter did not
+	// report it before the context was done. The key may still become active.
+	ErrRotationNotConfirmed = errors.New("api key rotation was not confirmed")
+)
+
+// RotatedKey is the key material of an api key created by RotateAPIKey
+type RotatedKey struct {
+	AccountIndex int64
+	ApiKeyIndex  uint8
+	PrivateKey   string // 0x-prefixed hex, as taken by NewSignerClient
+	PublicKey    string // 0x-prefixed hex
+	TxHash       string // Hash of the ChangePubKey transaction, set once it is submitted
+}
+
+// KeyRotationSink stores the key material of a new api key, e.g. in a keystore.
+// It is called before the ChangePubKey transaction is submitted, so the key is not lost
+// if the process dies while the rotation is pending. An error aborts the rotation.
+type KeyRotationSink func(key *RotatedKey) error
+
+// RotateAPIKey replaces an api key the client signs with by a newly generated one:
+//  1. the new key is handed to sink, if not nil
+//  2. a ChangePubKey transaction signed with the new key and l1Signer is submitted
+//  3. GetApiKey is polled until Lighter reports the new public key
+//  4. the client, its copies from WithContext and the clients registered by CreateClient
+//     switch to the new key, its nonce state is reset and cached auth tokens are dropped
+//
+// apiKeyIndex is the client's api key or, for NewSignerClientWithKeys, one of its keys.
+// Keys held by a signer.TxSigner are not rotated, ErrRemoteKeyRotation is returned.
+// ctx bounds the whole rotation; if it is done before the key is confirmed, the client keeps
+// the old key and ErrRotationNotConfirmed is returned.
+func (c *SignerClient) RotateAPIKey(ctx context.Context, l1Signer signer.L1Signer, apiKeyIndex uint8, sink KeyRotationSink) (*RotatedKey, error) {
+	current := c.keys.load()
+	if apiKeyIndex != c.apiKeyIndex {
+		if c.keyPool == nil || c.keyPool.AccountIndex() != c.accountIndex {
+			return nil, ErrKeyNotManaged
+		}
+		var ok bool
+		if current, ok = c.keyPool.KeyManager(apiKeyIndex); !ok {
+			return nil, ErrKeyNotManaged
+		}
+	}
+	if _, ok := current.(signer.TxSigner); ok {
+		return nil, ErrRemoteKeyRotation
+	}
+
+	privateKey, publicKey, err := GenerateAPIKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate api key: %w", err)
+	}
+	keyManager, err := parseKeyManager(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	key := &RotatedKey{
+		AccountIndex: c.accountIndex,
+		ApiKeyIndex:  apiKeyIndex,
+		PrivateKey:   privateKey,
+		PublicKey:    publicKey,
+	}
+	if sink != nil {
+		if err := sink(key); err != nil {
+			return nil, fmt.Errorf("failed to store rotated key: %w", err)
+		}
+	}
+
+	// ChangePubKey is signed with the key it registers
+	bound := c.WithContext(ctx)
+	rotating := &SignerClient{
+		TxClient: &TxClient{
+			apiClient:    bound.apiClient,
+			nonceManager: c.nonceManager,
+			nonceFetcher: bound.apiClient,
+			chainId:      c.chainId,
+			keys:         newKeyHolder(keyManager),
+			accountIndex: c.accountIndex,
+			apiKeyIndex:  apiKeyIndex,
+		},
+		fullHTTP:     bound.fullHTTP,
+		authProvider: c.authProvider,
+	}
+	resp, err := rotating.ChangePubKeyWithL1Signer(l1Signer, &types.ChangePubKeyReq{PubKey: keyManager.PubKeyBytes()}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit change pub key: %w", err)
+	}
+	key.TxHash = resp.TxHash
+
+	if err := waitForApiKey(ctx, bound.apiClient, c.accountIndex, apiKeyIndex, publicKey); err != nil {
+		return key, err
+	}
+
+	c.swapKeyManager(apiKeyIndex, keyManager)
+	if c.nonceManager != nil {
+		c.nonceManager.Reset(c.accountIndex, apiKeyIndex)
+	}
+	if m, ok := c.authProvider.(*auth.Manager); ok {
+		m.Invalidate()
+	}
+	return key, nil
+}
+
+// waitForApiKey polls Lighter until it reports publicKey for the api key
+func waitForApiKey(ctx context.Context, apiClient MinimalHTTPClient, accountIndex int64, apiKeyIndex uint8, publicKey string) error {
+	want := strings.TrimPrefix(publicKey, "0x")
+
+	ticker := time.NewTicker(RotationPollInterval)
+	defer ticker.Stop()
+	var lastErr error
+	for {
+		current, err := apiClient.GetApiKey(accountIndex, apiKeyIndex)
+		if err == nil && strings.EqualFold(strings.TrimPrefix(current, "0x"), want) {
+			return nil
+		}
+		lastErr = err
+
+		select {
+		case <-ctx.Done():
+			if lastErr != nil {
+				return fmt.Errorf("%w: %v (last error: %v)", ErrRotationNotConfirmed, ctx.Err(), lastErr)
+			}
+			return fmt.Errorf("%w: %v", ErrRotationNotConfirmed, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// swapKeyManager switches the client and the registered clients of the api key to keyManager
+func (c *SignerClient) swapKeyManager(apiKeyIndex uint8, keyManager signer.KeyManager) {
+	if apiKeyIndex == c.apiKeyIndex {
+		c.keys.store(keyManager)
+	}
+	if c.keyPool != nil && c.keyPool.AccountIndex() == c.accountIndex {
+		c.keyPool.SetKeyManager(apiKeyIndex, keyManager) //nolint:errcheck // Keys outside the pool are not in it
+	}
+
+	// Clients created by CreateClient for the same key would keep signing with the old one
+	txClientMu.Lock()
+	defer txClientMu.Unlock()
+	if registered := allTxClients[c.accountIndex][apiKeyIndex]; registered != nil {
+		registered.keys.store(keyManager)
+	}
+}
@@ -0,0 +1,247 @@
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	schnorr "github.com/elliottech/poseidon_crypto/signature/schnorr"
+
+	"github.com/0xJord4n/lighter-go/signer"
+	"github.com/0xJord4n/lighter-go/types"
+	"github.com/0xJord4n/lighter-go/types/api"
+	"github.com/0xJord4n/lighter-go/types/txtypes"
+)
+
+const (
+	testChainId = 304
+	testEthKey  = "0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+)
+
+// fakeLighter serves nonces and api keys, and applies the ChangePubKey transactions it
+// accepts when apply is set. Submissions fail with sendErr if set.
+type fakeLighter struct {
+	FullHTTPClient
+	mu      sync.Mutex
+	apply   bool
+	apiKeys map[uint8]string
+	nonces  map[uint8]int64
+	sent    []txtypes.TxInfo
+	sendErr error
+}
+
+func newFakeLighter(apiKeyIndex uint8, publicKey string) *fakeLighter {
+	return &fakeLighter{
+		apply:   true,
+		apiKeys: map[uint8]string{apiKeyIndex: publicKey[2:]},
+		nonces:  map[uint8]int64{apiKeyIndex: 10},
+	}
+}
+
+func (f *fakeLighter) GetNextNonce(accountIndex int64, apiKeyIndex uint8) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.nonces[apiKeyIndex], nil
+}
+
+func (f *fakeLighter) GetApiKey(accountIndex int64, apiKeyIndex uint8) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.apiKeys[apiKeyIndex], nil
+}
+
+func (f *fakeLighter) WithContext(ctx context.Context) FullHTTPClient {
+	return &boundLighter{fakeLighter: f, ctx: ctx}
+}
+
+// boundLighter is a fakeLighter bound to a context, failing nonce fetches once it is done
+type boundLighter struct {
+	*fakeLighter
+	ctx context.Context
+}
+
+func (b *boundLighter) GetNextNonce(accountIndex int64, apiKeyIndex uint8) (int64, error) {
+	if err := b.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return b.fakeLighter.GetNextNonce(accountIndex, apiKeyIndex)
+}
+
+func (f *fakeLighter) Transaction() TransactionAPI {
+	return &fakeTransactions{f: f}
+}
+
+func (f *fakeLighter) setNonce(apiKeyIndex uint8, nonce int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nonces[apiKeyIndex] = nonce
+}
+
+type fakeTransactions struct {
+	TransactionAPI
+	f *fakeLighter
+}
+
+func (t *fakeTransactions) SendSignedTx(tx txtypes.TxInfo, priceProtection *api.PriceProtection, accountIndex *int64, apiKeyIndex *uint8, auth string) (*api.RespSendTx, error) {
+	t.f.mu.Lock()
+	defer t.f.mu.Unlock()
+	if t.f.sendErr != nil {
+		return nil, t.f.sendErr
+	}
+	t.f.sent = append(t.f.sent, tx)
+	if changePubKey, ok := tx.(*txtypes.L2ChangePubKeyTxInfo); ok && t.f.apply {
+		t.f.apiKeys[changePubKey.ApiKeyIndex] = hex.EncodeToString(changePubKey.PubKey)
+		t.f.nonces[changePubKey.ApiKeyIndex] = changePubKey.Nonce + 1
+	}
+	return &api.RespSendTx{BaseResponse: api.BaseResponse{Code: api.CodeOK}, TxHash: tx.GetTxHash()}, nil
+}
+
+func (t *fakeTransactions) SendSignedTxBatch(txs []txtypes.TxInfo) (*api.RespSendTxBatch, error) {
+	t.f.mu.Lock()
+	defer t.f.mu.Unlock()
+	if t.f.sendErr != nil {
+		return nil, t.f.sendErr
+	}
+	t.f.sent = append(t.f.sent, txs...)
+	return &api.RespSendTxBatch{BaseResponse: api.BaseResponse{Code: api.CodeOK}}, nil
+}
+
+func newRotationClient(t *testing.T, accountIndex int64, apiKeyIndex uint8) (*SignerClient, *fakeLighter) {
+	t.Helper()
+	interval := RotationPollInterval
+	RotationPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { RotationPollInterval = interval })
+
+	privateKey, publicKey, _ := GenerateAPIKey()
+	fake := newFakeLighter(apiKeyIndex, publicKey)
+	c, err := NewSignerClient(fake, privateKey, testChainId, apiKeyIndex, accountIndex, nil)
+	if err != nil {
+		t.Fatalf("NewSignerClient failed: %v", err)
+	}
+	return c, fake
+}
+
+func TestRotateAPIKey_SwapsKey(t *testing.T) {
+	c, fake := newRotationClient(t, 41, 3)
+	bound := c.WithContext(context.Background())
+	oldKey := c.GetKeyManager()
+	registered, _ := CreateClient(fake, hex.EncodeToString(oldKey.PrvKeyBytes()), testChainId, 3, 41)
+	l1Signer, _ := signer.NewL1Signer(testEthKey)
+
+	var stored *RotatedKey
+	key, err := c.RotateAPIKey(context.Background(), l1Signer, 3, func(key *RotatedKey) error {
+		stored = &RotatedKey{PrivateKey: key.PrivateKey, PublicKey: key.PublicKey}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RotateAPIKey failed: %v", err)
+	}
+	if stored == nil || stored.PrivateKey != key.PrivateKey || key.TxHash == "" {
+		t.Fatalf("expected the key stored before submission, got %+v and %+v", stored, key)
+	}
+
+	// ChangePubKey is signed with the new key and carries the L1 signature
+	changePubKey := fake.sent[0].(*txtypes.L2ChangePubKeyTxInfo)
+	msgHash, _ := changePubKey.Hash(testChainId)
+	if err := schnorr.Validate(changePubKey.PubKey, msgHash, changePubKey.Sig); err != nil {
+		t.Errorf("expected ChangePubKey signed with the new key: %v", err)
+	}
+	if changePubKey.GetL1AddressBySignature().Hex() != l1Signer.Address() {
+		t.Error("expected ChangePubKey signed by the L1 address")
+	}
+
+	// The client, its copies and the registered client switch to the new key
+	newKey := c.GetKeyManager()
+	if newKey == oldKey || "0x"+hex.EncodeToString(changePubKey.PubKey) != key.PublicKey {
+		t.Fatal("expected the client to switch to the new key")
+	}
+	if bound.GetKeyManager() != newKey || registered.GetKeyManager() != newKey {
+		t.Error("expected copies and registered clients to switch to the new key")
+	}
+
+	// The nonce state is reset, so the next nonce is fetched again
+	fake.setNonce(3, 50)
+	order := &types.CreateOrderTxReq{MarketIndex: 0, BaseAmount: 100, Price: 3000, Type: txtypes.LimitOrder, TimeInForce: txtypes.GoodTillTime, OrderExpiry: time.Now().Add(time.Hour).UnixMilli()}
+	tx, err := c.GetCreateOrderTransaction(order, nil)
+	if err != nil {
+		t.Fatalf("GetCreateOrderTransaction failed: %v", err)
+	}
+	if tx.Nonce != 50 {
+		t.Errorf("expected nonce 50 after the reset, got %d", tx.Nonce)
+	}
+	pk := newKey.PubKeyBytes()
+	msgHash, _ = tx.Hash(testChainId)
+	if err := schnorr.Validate(pk[:], msgHash, tx.Sig); err != nil {
+		t.Errorf("expected orders signed with the new key: %v", err)
+	}
+}
+
+func TestRotateAPIKey_NotConfirmed(t *testing.T) {
+	c, fake := newRotationClient(t, 42, 3)
+	fake.apply = false
+	oldKey := c.GetKeyManager()
+	l1Signer, _ := signer.NewL1Signer(testEthKey)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	key, err := c.RotateAPIKey(ctx, l1Signer, 3, nil)
+	if !errors.Is(err, ErrRotationNotConfirmed) {
+		t.Fatalf("expected ErrRotationNotConfirmed, got %v", err)
+	}
+	if key == nil || key.TxHash == "" {
+		t.Error("expected the submitted key returned")
+	}
+	if c.GetKeyManager() != oldKey {
+		t.Error("expected the client to keep the old key")
+	}
+
+	if _, err := c.RotateAPIKey(context.Background(), l1Signer, 4, nil); !errors.Is(err, ErrKeyNotManaged) {
+		t.Errorf("expected ErrKeyNotManaged, got %v", err)
+	}
+	sinkErr := errors.New("keystore is read-only")
+	if _, err := c.RotateAPIKey(context.Background(), l1Signer, 3, func(*RotatedKey) error { return sinkErr }); !errors.Is(err, sinkErr) || len(fake.sent) != 1 {
+		t.Errorf("expected the rotation aborted before submission, got %v", err)
+	}
+}
+
+// txSigningKey stands for a KeyManager held by a remote signer
+type txSigningKey struct {
+	signer.KeyManager
+}
+
+func (k txSigningKey) SignTx(tx txtypes.TxInfo, hashedMessage []byte) ([]byte, error) {
+	return k.Sign(hashedMessage, nil)
+}
+
+func (k txSigningKey) SignAuthToken(message string, hashedMessage []byte) ([]byte, error) {
+	return k.Sign(hashedMessage, nil)
+}
+
+func TestRotateAPIKey_RefusesRemoteKeys(t *testing.T) {
+	privateKey, publicKey, _ := GenerateAPIKey()
+	keyManager, _ := parseKeyManager(privateKey)
+	fake := newFakeLighter(3, publicKey)
+	c, err := NewSignerClientWithKeyManager(fake, txSigningKey{keyManager}, testChainId, 3, 41, nil)
+	if err != nil {
+		t.Fatalf("NewSignerClientWithKeyManager failed: %v", err)
+	}
+	l1Signer, _ := signer.NewL1Signer(testEthKey)
+
+	sinkCalled := false
+	_, err = c.RotateAPIKey(context.Background(), l1Signer, 3, func(*RotatedKey) error {
+		sinkCalled = true
+		return nil
+	})
+	if !errors.Is(err, ErrRemoteKeyRotation) {
+		t.Fatalf("expected ErrRemoteKeyRotation, got %v", err)
+	}
+	if sinkCalled || len(fake.sent) != 0 {
+		t.Error("expected nothing generated or submitted")
+	}
+	if _, ok := c.GetKeyManager().(signer.TxSigner); !ok {
+		t.Error("expected the client to keep the remote key")
+	}
+}
@@ -26,13 +26,22 @@
 //	// Create stop loss / take profit orders
 //	txInfo, err := client.CreateStopLossOrder(0, 100000, 340000, false, expiry, nil)
 //	txInfo, err := client.CreateTakeProfitOrder(0, 100000, 360000, false, expiry, nil)
+//
+//	// Bound a submission with a deadline; cancelling ctx aborts the request
+//	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+//	defer cancel()
+//	resp, err := client.WithContext(ctx).SendAndSubmit(txInfo)
 package client
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"time"
+	"sort"
 
+	"github.com/0xJord4n/lighter-go/auth"
 	"github.com/0xJord4n/lighter-go/nonce"
+	"github.com/0xJord4n/lighter-go/ratelimit"
 	"github.com/0xJord4n/lighter-go/signer"
 	"github.com/0xJord4n/lighter-go/types"
 	"github.com/0xJord4n/lighter-go/types/api"
@@ -44,7 +53,7 @@ import (
 type SignerClient struct {
 	*TxClient
 	fullHTTP     FullHTTPClient
-	nonceManager nonce.Manager
+	authProvider auth.Provider
 }
 
 // NewSignerClient creates a SignerClient with full HTTP capabilities.
@@ -58,11 +67,33 @@ func NewSignerClient(httpClient FullHTTPClient, privateKey string, chainId uint3
 	if nonceManager == nil {
 		nonceManager = nonce.NewOptimisticManager(httpClient)
 	}
+	txClient.SetNonceManager(nonceManager)
+
+	return &SignerClient{
+		TxClient:     txClient,
+		fullHTTP:     httpClient,
+		authProvider: auth.NewManager(txClient),
+	}, nil
+}
+
+// NewSignerClientWithKeyManager creates a SignerClient signing with keyManager instead of a private key,
+// e.g. a remote.KeyManager backed by a lighter-signer daemon.
+// If nonceManager is nil, a new OptimisticNonceManager will be created.
+func NewSignerClientWithKeyManager(httpClient FullHTTPClient, keyManager signer.KeyManager, chainId uint32, apiKeyIndex uint8, accountIndex int64, nonceManager nonce.Manager) (*SignerClient, error) {
+	if accountIndex < 0 {
+		return nil, fmt.Errorf("invalid account index")
+	}
+	txClient := NewTxClientWithKeyManager(httpClient, keyManager, accountIndex, apiKeyIndex, chainId)
+
+	if nonceManager == nil {
+		nonceManager = nonce.NewOptimisticManager(httpClient)
+	}
+	txClient.SetNonceManager(nonceManager)
 
 	return &SignerClient{
 		TxClient:     txClient,
 		fullHTTP:     httpClient,
-		nonceManager: nonceManager,
+		authProvider: auth.NewManager(txClient),
 	}, nil
 }
 
@@ -77,6 +108,56 @@ func NewSignerClientForNetwork(httpClient FullHTTPClient, network Network, priva
 	return NewSignerClient(httpClient, privateKey, network.ChainID(), apiKeyIndex, accountIndex, nonceManager)
 }
 
+// NewSignerClientWithKeys creates a SignerClient that spreads transactions over several api keys
+// of one account, so that orders can be signed and submitted concurrently. privateKeys maps api key
+// indices to hex-encoded private keys. Transactions created without an explicit ApiKeyIndex and
+// nonce are signed with the least loaded key; the lowest api key index is used for auth tokens.
+// If nonceManager is nil, a new OptimisticNonceManager will be created.
+func NewSignerClientWithKeys(httpClient FullHTTPClient, chainId uint32, accountIndex int64, privateKeys map[uint8]string, nonceManager nonce.Manager) (*SignerClient, error) {
+	if accountIndex < 0 {
+		return nil, fmt.Errorf("invalid account index")
+	}
+
+	indices := make([]int, 0, len(privateKeys))
+	for apiKeyIndex := range privateKeys {
+		indices = append(indices, int(apiKeyIndex))
+	}
+	sort.Ints(indices)
+
+	keys := make([]nonce.PoolKey, 0, len(indices))
+	for _, i := range indices {
+		apiKeyIndex := uint8(i)
+		keyManager, err := parseKeyManager(privateKeys[apiKeyIndex])
+		if err != nil {
+			return nil, fmt.Errorf("invalid private key for api key %d: %w", apiKeyIndex, err)
+		}
+		keys = append(keys, nonce.PoolKey{ApiKeyIndex: apiKeyIndex, KeyManager: keyManager})
+	}
+
+	if nonceManager == nil {
+		nonceManager = nonce.NewOptimisticManager(httpClient)
+	}
+	pool, err := nonce.NewPool(accountIndex, nonceManager, keys...)
+	if err != nil {
+		return nil, err
+	}
+
+	txClient := &TxClient{
+		apiClient:    httpClient,
+		nonceManager: pool,
+		keyPool:      pool,
+		chainId:      chainId,
+		keys:         newKeyHolder(keys[0].KeyManager),
+		accountIndex: accountIndex,
+		apiKeyIndex:  keys[0].ApiKeyIndex,
+	}
+	return &SignerClient{
+		TxClient:     txClient,
+		fullHTTP:     httpClient,
+		authProvider: auth.NewManager(txClient),
+	}, nil
+}
+
 // createTxClient is a helper that creates a TxClient without registering it globally
 func createTxClient(httpClient MinimalHTTPClient, privateKey string, chainId uint32, apiKeyIndex uint8, accountIndex int64) (*TxClient, error) {
 	// Use the existing CreateClient function but we need to get the client back
@@ -88,9 +169,35 @@ func (c *SignerClient) FullHTTP() FullHTTPClient {
 	return c.fullHTTP
 }
 
-// NonceManager returns the nonce manager
-func (c *SignerClient) NonceManager() nonce.Manager {
-	return c.nonceManager
+// AuthProvider returns the provider of the auth tokens used for private endpoints.
+// By default it is an auth.Manager caching tokens minted with the client's key;
+// share it with ws.Client to subscribe to private channels with the same tokens.
+func (c *SignerClient) AuthProvider() auth.Provider {
+	return c.authProvider
+}
+
+// SetAuthProvider replaces the provider of auth tokens
+func (c *SignerClient) SetAuthProvider(p auth.Provider) {
+	c.authProvider = p
+}
+
+// WithContext returns a shallow copy of the SignerClient whose HTTP calls, such as
+// order book lookups, nonce fetches and tx submission, are bound to ctx.
+// The copy shares the key manager, nonce manager and auth provider with the receiver.
+// Nonces are fetched with the bound HTTP client if the nonce manager implements
+// nonce.FetcherOverrider, as the managers of the nonce package do.
+func (c *SignerClient) WithContext(ctx context.Context) *SignerClient {
+	fullHTTP := c.fullHTTP.WithContext(ctx)
+
+	txClient := *c.TxClient
+	txClient.apiClient = fullHTTP
+	txClient.nonceFetcher = fullHTTP
+
+	return &SignerClient{
+		TxClient:     &txClient,
+		fullHTTP:     fullHTTP,
+		authProvider: c.authProvider,
+	}
 }
 
 // CreateMarketOrder creates a market order with minimal parameters
@@ -302,51 +409,81 @@ func (c *SignerClient) CreateStopLossLimitOrder(marketIndex int16, size int64, p
 	return c.GetCreateOrderTransaction(req, opts)
 }
 
-// SendAndSubmit signs a transaction and submits it to the API
+// SendAndSubmit signs a transaction and submits it to the API.
+// The nonce the transaction was signed with is acknowledged to the nonce manager,
+// unless the outcome is unknown, e.g. after a connection error.
 func (c *SignerClient) SendAndSubmit(txInfo txtypes.TxInfo) (*api.RespSendTx, error) {
-	txInfoJSON, err := txInfo.GetTxInfo()
-	if err != nil {
-		return nil, fmt.Errorf("failed to serialize tx info: %w", err)
-	}
-
-	// Use SendTxWithIndices to pass account_index and api_key_index (matching TS SDK)
-	accountIndex := c.GetAccountIndex()
-	apiKeyIndex := c.GetApiKeyIndex()
-	resp, err := c.fullHTTP.Transaction().SendTxWithIndices(
-		txInfo.GetTxType(),
-		txInfoJSON,
+	// Pass account_index and api_key_index (matching TS SDK)
+	accountIndex := txInfo.GetAccountIndex()
+	apiKeyIndex := txInfo.GetApiKeyIndex()
+	c.trackTxHashes(txInfo)
+	resp, err := c.fullHTTP.Transaction().SendSignedTx(
+		txInfo,
 		nil,
 		&accountIndex,
 		&apiKeyIndex,
 		"",
 	)
+	c.acknowledge(err, txInfo)
 	if err != nil {
-		// Acknowledge failure for nonce recovery
-		if optManager, ok := c.nonceManager.(*nonce.OptimisticManager); ok {
-			// Extract nonce from tx info if possible
-			optManager.AcknowledgeFailure(c.GetAccountIndex(), c.GetApiKeyIndex(), -1)
-		}
 		return nil, err
 	}
 
 	return resp, nil
 }
 
-// SendTxBatch submits multiple transactions
+// SendTxBatch submits multiple transactions and acknowledges their nonces to the nonce manager
 func (c *SignerClient) SendTxBatch(txInfos []txtypes.TxInfo) (*api.RespSendTxBatch, error) {
-	txTypes := make([]uint8, len(txInfos))
-	txInfoJSONs := make([]string, len(txInfos))
+	c.trackTxHashes(txInfos...)
+	resp, err := c.fullHTTP.Transaction().SendSignedTxBatch(txInfos)
+	c.acknowledge(err, txInfos...)
+	return resp, err
+}
 
-	for i, tx := range txInfos {
-		txTypes[i] = tx.GetTxType()
-		jsonStr, err := tx.GetTxInfo()
-		if err != nil {
-			return nil, fmt.Errorf("failed to serialize tx %d: %w", i, err)
+// trackTxHashes hands the hashes of transactions about to be submitted to the nonce
+// manager, so that a restarted manager can check whether they reached Lighter
+func (c *SignerClient) trackTxHashes(txInfos ...txtypes.TxInfo) {
+	if c.nonceManager == nil {
+		return
+	}
+	for _, txInfo := range txInfos {
+		nonce.TrackTxHash(c.nonceManager, txInfo.GetAccountIndex(), txInfo.GetApiKeyIndex(), txInfo.GetNonce(), txInfo.GetTxHash())
+	}
+}
+
+// acknowledge reports the outcome of a submission to the nonce manager.
+// Failures are only acknowledged when Lighter rejected the transactions: after a
+// connection error, a cancellation or a 5xx they may still be executed, so their
+// nonces are left pending, as ws.Client does for transactions without a result.
+func (c *SignerClient) acknowledge(err error, txInfos ...txtypes.TxInfo) {
+	if err != nil && !isRejection(err) {
+		return
+	}
+	c.settleNonces(err == nil, txInfos...)
+}
+
+// settleNonces acknowledges the nonces of transactions to the nonce manager
+func (c *SignerClient) settleNonces(success bool, txInfos ...txtypes.TxInfo) {
+	if c.nonceManager == nil {
+		return
+	}
+	for _, txInfo := range txInfos {
+		if success {
+			nonce.AcknowledgeTxSuccess(c.nonceManager, txInfo.GetAccountIndex(), txInfo.GetApiKeyIndex(), txInfo.GetNonce(), txInfo.GetTxHash())
+		} else {
+			nonce.AcknowledgeTxFailure(c.nonceManager, txInfo.GetAccountIndex(), txInfo.GetApiKeyIndex(), txInfo.GetNonce(), txInfo.GetTxHash())
 		}
-		txInfoJSONs[i] = jsonStr
 	}
+}
 
-	return c.fullHTTP.Transaction().SendTxBatch(txTypes, txInfoJSONs)
+// isRejection reports whether a submission definitively failed: Lighter answered with an
+// error other than a 5xx (an *http.APIError), or the client-side rate limiter refused it
+func isRejection(err error) bool {
+	if errors.Is(err, ratelimit.ErrRateLimited) {
+		return true
+	}
+	var apiErr interface{ IsServerError() bool }
+	return errors.As(err, &apiErr) && !apiErr.IsServerError()
 }
 
 // Transfer creates, signs (L1 + L2), and submits a transfer transaction.
@@ -358,7 +495,12 @@ func (c *SignerClient) Transfer(ethPrivateKey string, req *types.TransferTxReq,
 	if err != nil {
 		return nil, fmt.Errorf("failed to create L1 signer: %w", err)
 	}
+	return c.TransferWithL1Signer(l1Signer, req, opts)
+}
 
+// TransferWithL1Signer creates, signs (L1 + L2), and submits a transfer transaction,
+// with the L1 signature from l1Signer, e.g. a signer.ExternalL1Signer for a hardware wallet
+func (c *SignerClient) TransferWithL1Signer(l1Signer signer.L1Signer, req *types.TransferTxReq, opts *types.TransactOpts) (*api.RespSendTx, error) {
 	// Create the transfer transaction (L2 signed)
 	txInfo, err := c.GetTransferTransaction(req, opts)
 	if err != nil {
@@ -366,9 +508,11 @@ func (c *SignerClient) Transfer(ethPrivateKey string, req *types.TransferTxReq,
 	}
 
 	// Sign with Ethereum key (L1 signature)
-	l1Sig, err := l1Signer.Sign(txInfo.GetL1SignatureBody(c.GetChainId()))
+	l1Sig, err := signL1(l1Signer, txInfo.GetL1SignatureBody(c.GetChainId()))
 	if err != nil {
-		return nil, fmt.Errorf("failed to sign L1 message: %w", err)
+		// Never submitted, the nonce can be reused
+		c.settleNonces(false, txInfo)
+		return nil, err
 	}
 	txInfo.SetL1Sig(l1Sig)
 
@@ -385,7 +529,12 @@ func (c *SignerClient) ChangePubKey(ethPrivateKey string, req *types.ChangePubKe
 	if err != nil {
 		return nil, fmt.Errorf("failed to create L1 signer: %w", err)
 	}
+	return c.ChangePubKeyWithL1Signer(l1Signer, req, opts)
+}
 
+// ChangePubKeyWithL1Signer creates, signs (L1 + L2), and submits a change pub key
+// transaction, with the L1 signature from l1Signer
+func (c *SignerClient) ChangePubKeyWithL1Signer(l1Signer signer.L1Signer, req *types.ChangePubKeyReq, opts *types.TransactOpts) (*api.RespSendTx, error) {
 	// Create the change pub key transaction (L2 signed)
 	txInfo, err := c.GetChangePubKeyTransaction(req, opts)
 	if err != nil {
@@ -393,18 +542,32 @@ func (c *SignerClient) ChangePubKey(ethPrivateKey string, req *types.ChangePubKe
 	}
 
 	// Sign with Ethereum key (L1 signature)
-	l1Sig, err := l1Signer.Sign(txInfo.GetL1SignatureBody())
+	l1Sig, err := signL1(l1Signer, txInfo.GetL1SignatureBody())
 	if err != nil {
-		return nil, fmt.Errorf("failed to sign L1 message: %w", err)
+		// Never submitted, the nonce can be reused
+		c.settleNonces(false, txInfo)
+		return nil, err
 	}
 	txInfo.SetL1Sig(l1Sig)
 
 	// Submit
-	txInfoJSON, err := txInfo.GetTxInfo()
+	c.trackTxHashes(txInfo)
+	resp, err := c.fullHTTP.Transaction().SendSignedTx(txInfo, nil, nil, nil, "")
+	c.acknowledge(err, txInfo)
+	return resp, err
+}
+
+// signL1 signs message with l1Signer and verifies the signature was made by its address,
+// so a wallet signing with the wrong account fails before submission
+func signL1(l1Signer signer.L1Signer, message string) (string, error) {
+	l1Sig, err := l1Signer.Sign(message)
 	if err != nil {
-		return nil, fmt.Errorf("failed to serialize tx info: %w", err)
+		return "", fmt.Errorf("failed to sign L1 message: %w", err)
 	}
-	return c.fullHTTP.Transaction().SendTx(txInfo.GetTxType(), txInfoJSON, nil)
+	if err := signer.VerifyL1Signature(message, l1Sig, l1Signer.Address()); err != nil {
+		return "", fmt.Errorf("failed to verify L1 signature: %w", err)
+	}
+	return l1Sig, nil
 }
 
 // GetOpenOrders retrieves open orders for the account
@@ -432,14 +595,7 @@ func (c *SignerClient) GetPositions() (*api.DetailedAccounts, error) {
 
 // Helper to get auth token
 func (c *SignerClient) getAuthToken() (string, error) {
-	deadline := time.Now().Add(8 * time.Hour)
-	authInfo, err := c.GetAuthToken(deadline)
-	if err != nil {
-		return "", err
-	}
-
-	// The auth token is returned as a string directly
-	return authInfo, nil
+	return c.authProvider.Token()
 }
 
 // calculateSlippagePrice calculates price with slippage
@@ -0,0 +1,330 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/0xJord4n/lighter-go/nonce"
+	"github.com/0xJord4n/lighter-go/ratelimit"
+	"github.com/0xJord4n/lighter-go/signer"
+	"github.com/0xJord4n/lighter-go/types"
+	"github.com/0xJord4n/lighter-go/types/txtypes"
+)
+
+// ackRecorder records the nonces handed out and acknowledged by the wrapped Manager
+type ackRecorder struct {
+	nonce.Manager
+	mu        sync.Mutex
+	allocated []int64
+	acks      []string
+}
+
+func (r *ackRecorder) GetNonce(accountIndex int64, apiKeyIndex uint8) (int64, error) {
+	n, err := r.Manager.GetNonce(accountIndex, apiKeyIndex)
+	if err == nil {
+		r.mu.Lock()
+		r.allocated = append(r.allocated, n)
+		r.mu.Unlock()
+	}
+	return n, err
+}
+
+// lastAllocated returns the last nonce handed out
+func (r *ackRecorder) lastAllocated() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.allocated[len(r.allocated)-1]
+}
+
+func (r *ackRecorder) AcknowledgeSuccess(accountIndex int64, apiKeyIndex uint8, n int64) {
+	r.record("success", n)
+	r.Manager.AcknowledgeSuccess(accountIndex, apiKeyIndex, n)
+}
+
+func (r *ackRecorder) AcknowledgeFailure(accountIndex int64, apiKeyIndex uint8, n int64) {
+	r.record("failure", n)
+	r.Manager.AcknowledgeFailure(accountIndex, apiKeyIndex, n)
+}
+
+func (r *ackRecorder) record(outcome string, n int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.acks = append(r.acks, fmt.Sprintf("%s %d", outcome, n))
+}
+
+// take returns and clears the recorded acknowledgments
+func (r *ackRecorder) take() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	acks := r.acks
+	r.acks = nil
+	return acks
+}
+
+// fakeAPIError mimics http.APIError, which this package can't import
+type fakeAPIError struct {
+	status int
+}
+
+func (e *fakeAPIError) Error() string       { return fmt.Sprintf("API error (HTTP %d)", e.status) }
+func (e *fakeAPIError) IsServerError() bool { return e.status >= 500 }
+
+func newAckClient(t *testing.T) (*SignerClient, *fakeLighter, *ackRecorder) {
+	t.Helper()
+	privateKey, publicKey, _ := GenerateAPIKey()
+	fake := newFakeLighter(3, publicKey)
+	recorder := &ackRecorder{Manager: nonce.NewOptimisticManager(fake)}
+	c, err := NewSignerClient(fake, privateKey, testChainId, 3, 43, recorder)
+	if err != nil {
+		t.Fatalf("NewSignerClient failed: %v", err)
+	}
+	return c, fake, recorder
+}
+
+func testOrder() *types.CreateOrderTxReq {
+	return &types.CreateOrderTxReq{MarketIndex: 0, BaseAmount: 100, Price: 3000, Type: txtypes.LimitOrder, TimeInForce: txtypes.GoodTillTime, OrderExpiry: time.Now().Add(time.Hour).UnixMilli()}
+}
+
+func testTransfer() *types.TransferTxReq {
+	return &types.TransferTxReq{ToAccountIndex: 44, AssetIndex: 3, Amount: 1000}
+}
+
+func assertAcks(t *testing.T, r *ackRecorder, want ...string) {
+	t.Helper()
+	got := r.take()
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("expected acknowledgments %v, got %v", want, got)
+	}
+}
+
+func TestSignerClient_AllocatesNonces(t *testing.T) {
+	c, _, recorder := newAckClient(t)
+
+	order, err := c.GetCreateOrderTransaction(testOrder(), nil)
+	if err != nil {
+		t.Fatalf("GetCreateOrderTransaction failed: %v", err)
+	}
+	cancel, err := c.GetCancelOrderTransaction(&types.CancelOrderTxReq{MarketIndex: 0, Index: 1}, nil)
+	if err != nil {
+		t.Fatalf("GetCancelOrderTransaction failed: %v", err)
+	}
+	explicit, err := c.GetCancelOrderTransaction(&types.CancelOrderTxReq{MarketIndex: 0, Index: 2}, &types.TransactOpts{Nonce: types.NewInt64(99)})
+	if err != nil {
+		t.Fatalf("GetCancelOrderTransaction failed: %v", err)
+	}
+	if order.Nonce != 10 || cancel.Nonce != 11 || explicit.Nonce != 99 {
+		t.Errorf("expected nonces 10, 11 and 99, got %d, %d and %d", order.Nonce, cancel.Nonce, explicit.Nonce)
+	}
+	if n := recorder.Manager.(*nonce.OptimisticManager).PendingCount(43, 3); n != 2 {
+		t.Errorf("expected the 2 allocated nonces pending, got %d", n)
+	}
+	assertAcks(t, recorder)
+}
+
+func TestSignerClient_AcknowledgesSubmissions(t *testing.T) {
+	tests := []struct {
+		name    string
+		sendErr error
+		outcome string // empty if the nonces are left pending
+	}{
+		{name: "accepted", outcome: "success"},
+		{name: "rejected", sendErr: &fakeAPIError{status: 400}, outcome: "failure"},
+		{name: "rejected in body", sendErr: fmt.Errorf("wrapped: %w", &fakeAPIError{}), outcome: "failure"},
+		{name: "rate limited", sendErr: ratelimit.ErrRateLimited, outcome: "failure"},
+		{name: "server error", sendErr: &fakeAPIError{status: 502}},
+		{name: "connection error", sendErr: errors.New("connection reset by peer")},
+		{name: "cancelled", sendErr: context.Canceled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, fake, recorder := newAckClient(t)
+			fake.sendErr = tt.sendErr
+			ack := func(nonces ...int64) []string {
+				if tt.outcome == "" {
+					return nil
+				}
+				acks := make([]string, len(nonces))
+				for i, n := range nonces {
+					acks[i] = fmt.Sprintf("%s %d", tt.outcome, n)
+				}
+				return acks
+			}
+
+			order, _ := c.GetCreateOrderTransaction(testOrder(), nil)
+			if _, err := c.SendAndSubmit(order); !errors.Is(err, tt.sendErr) {
+				t.Fatalf("SendAndSubmit: expected %v, got %v", tt.sendErr, err)
+			}
+			assertAcks(t, recorder, ack(order.Nonce)...)
+
+			first, _ := c.GetCreateOrderTransaction(testOrder(), nil)
+			second, _ := c.GetCancelOrderTransaction(&types.CancelOrderTxReq{MarketIndex: 0, Index: 1}, nil)
+			if _, err := c.SendTxBatch([]txtypes.TxInfo{first, second}); !errors.Is(err, tt.sendErr) {
+				t.Fatalf("SendTxBatch: expected %v, got %v", tt.sendErr, err)
+			}
+			assertAcks(t, recorder, ack(first.Nonce, second.Nonce)...)
+
+			if _, err := c.Transfer(testEthKey, testTransfer(), nil); !errors.Is(err, tt.sendErr) {
+				t.Fatalf("Transfer: expected %v, got %v", tt.sendErr, err)
+			}
+			assertAcks(t, recorder, ack(recorder.lastAllocated())...)
+
+			privateKey, _, _ := GenerateAPIKey()
+			keyManager, _ := parseKeyManager(privateKey)
+			if _, err := c.ChangePubKey(testEthKey, &types.ChangePubKeyReq{PubKey: keyManager.PubKeyBytes()}, nil); !errors.Is(err, tt.sendErr) {
+				t.Fatalf("ChangePubKey: expected %v, got %v", tt.sendErr, err)
+			}
+			assertAcks(t, recorder, ack(recorder.lastAllocated())...)
+		})
+	}
+}
+
+func TestSignerClient_ReleasesUnsubmittedNonces(t *testing.T) {
+	c, fake, recorder := newAckClient(t)
+	l1Signer, _ := signer.NewL1Signer(testEthKey)
+	failing, _ := signer.NewExternalL1Signer(l1Signer.Address(), func(string) (string, error) {
+		return "", errors.New("user rejected the request")
+	})
+
+	if _, err := c.TransferWithL1Signer(failing, testTransfer(), nil); err == nil {
+		t.Fatal("expected the L1 signing error")
+	}
+	assertAcks(t, recorder, fmt.Sprintf("failure %d", recorder.lastAllocated()))
+
+	privateKey, _, _ := GenerateAPIKey()
+	keyManager, _ := parseKeyManager(privateKey)
+	if _, err := c.ChangePubKeyWithL1Signer(failing, &types.ChangePubKeyReq{PubKey: keyManager.PubKeyBytes()}, nil); err == nil {
+		t.Fatal("expected the L1 signing error")
+	}
+	assertAcks(t, recorder, fmt.Sprintf("failure %d", recorder.lastAllocated()))
+	if len(fake.sent) != 0 {
+		t.Errorf("expected nothing submitted, got %d transactions", len(fake.sent))
+	}
+}
+
+func TestSignerClient_ReleasesUnsignedNonces(t *testing.T) {
+	c, _, recorder := newAckClient(t)
+
+	invalid := testOrder()
+	invalid.Price = 0
+	if _, err := c.GetCreateOrderTransaction(invalid, nil); err == nil {
+		t.Fatal("expected the invalid order rejected")
+	}
+	assertAcks(t, recorder, "failure 10")
+
+	// The nonce is handed out again instead of being skipped
+	order, err := c.GetCreateOrderTransaction(testOrder(), nil)
+	if err != nil {
+		t.Fatalf("GetCreateOrderTransaction failed: %v", err)
+	}
+	if order.Nonce != 10 {
+		t.Errorf("expected nonce 10 reused, got %d", order.Nonce)
+	}
+
+	// Nonces provided by the caller are not the manager's to release
+	if _, err := c.GetCreateOrderTransaction(invalid, &types.TransactOpts{Nonce: types.NewInt64(99)}); err == nil {
+		t.Fatal("expected the invalid order rejected")
+	}
+	assertAcks(t, recorder)
+
+	// Pooled keys release their lease
+	privateKey, publicKey, _ := GenerateAPIKey()
+	otherKey, _, _ := GenerateAPIKey()
+	pooled, err := NewSignerClientWithKeys(newFakeLighter(3, publicKey), testChainId, 43, map[uint8]string{3: privateKey, 4: otherKey}, nil)
+	if err != nil {
+		t.Fatalf("NewSignerClientWithKeys failed: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := pooled.GetCreateOrderTransaction(invalid, nil); err == nil {
+			t.Fatal("expected the invalid order rejected")
+		}
+	}
+	for _, k := range []uint8{3, 4} {
+		if n := pooled.KeyPool().InFlight(k); n != 0 {
+			t.Errorf("expected no nonce in flight for key %d, got %d", k, n)
+		}
+	}
+}
+
+func TestSignerClient_RejectsKeysOutsideThePool(t *testing.T) {
+	privateKey, publicKey, _ := GenerateAPIKey()
+	otherKey, _, _ := GenerateAPIKey()
+	pooled, err := NewSignerClientWithKeys(newFakeLighter(3, publicKey), testChainId, 43, map[uint8]string{3: privateKey, 4: otherKey}, nil)
+	if err != nil {
+		t.Fatalf("NewSignerClientWithKeys failed: %v", err)
+	}
+
+	apiKeyIndex, explicitNonce := uint8(9), int64(5)
+	ops := &types.TransactOpts{ApiKeyIndex: &apiKeyIndex, Nonce: &explicitNonce}
+	if _, err := pooled.GetCreateOrderTransaction(testOrder(), ops); !errors.Is(err, nonce.ErrKeyNotInPool) {
+		t.Errorf("expected ErrKeyNotInPool, got %v", err)
+	}
+
+	// Another account is signed with the client's own key
+	fromAccountIndex := int64(44)
+	ops = &types.TransactOpts{FromAccountIndex: &fromAccountIndex, ApiKeyIndex: &apiKeyIndex, Nonce: &explicitNonce}
+	if _, err := pooled.GetCreateOrderTransaction(testOrder(), ops); err != nil {
+		t.Errorf("expected another account signed, got %v", err)
+	}
+}
+
+func TestSignerClient_WithContextFetchesNonces(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	privateKey, publicKey, _ := GenerateAPIKey()
+	c, err := NewSignerClient(newFakeLighter(3, publicKey), privateKey, testChainId, 3, 43, nil)
+	if err != nil {
+		t.Fatalf("NewSignerClient failed: %v", err)
+	}
+	if _, err := c.WithContext(ctx).GetCreateOrderTransaction(testOrder(), nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the nonce fetch bound to the context, got %v", err)
+	}
+	if _, err := c.GetCreateOrderTransaction(testOrder(), nil); err != nil {
+		t.Errorf("expected the receiver unaffected, got %v", err)
+	}
+
+	// Pooled keys fetch through the bound client too
+	otherKey, _, _ := GenerateAPIKey()
+	fake := newFakeLighter(3, publicKey)
+	fake.setNonce(4, 20)
+	pooled, err := NewSignerClientWithKeys(fake, testChainId, 43, map[uint8]string{3: privateKey, 4: otherKey}, nil)
+	if err != nil {
+		t.Fatalf("NewSignerClientWithKeys failed: %v", err)
+	}
+	if _, err := pooled.WithContext(ctx).GetCreateOrderTransaction(testOrder(), nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the pooled nonce fetch bound to the context, got %v", err)
+	}
+	if _, err := pooled.GetCreateOrderTransaction(testOrder(), nil); err != nil {
+		t.Errorf("expected the receiver unaffected, got %v", err)
+	}
+}
+
+func TestSignerClient_TracksTxHashes(t *testing.T) {
+	privateKey, publicKey, _ := GenerateAPIKey()
+	fake := newFakeLighter(3, publicKey)
+	fake.sendErr = &fakeAPIError{status: 502}
+	store, err := nonce.OpenFileStore(filepath.Join(t.TempDir(), "nonces.journal"))
+	if err != nil {
+		t.Fatalf("OpenFileStore failed: %v", err)
+	}
+	defer store.Close()
+	manager, _ := nonce.NewOptimisticManagerWithStore(fake, store)
+	c, err := NewSignerClient(fake, privateKey, testChainId, 3, 43, manager)
+	if err != nil {
+		t.Fatalf("NewSignerClient failed: %v", err)
+	}
+
+	order, _ := c.GetCreateOrderTransaction(testOrder(), nil)
+	c.SendAndSubmit(order) //nolint:errcheck // The outcome is unknown, the nonce stays pending
+
+	states, _ := store.Load()
+	if len(states) != 1 || states[0].TxHashes[order.Nonce] != order.GetTxHash() {
+		t.Errorf("expected the tx hash of the pending nonce stored, got %+v", states)
+	}
+}
@@ -3,10 +3,13 @@ package client
 import (
 	"encoding/hex"
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/0xJord4n/lighter-go/nonce"
 	"github.com/0xJord4n/lighter-go/signer"
 	"github.com/0xJord4n/lighter-go/types"
+	"github.com/0xJord4n/lighter-go/types/txtypes"
 )
 
 var (
@@ -17,8 +20,11 @@ var (
 
 type TxClient struct {
 	apiClient    MinimalHTTPClient
+	nonceManager nonce.Manager
+	keyPool      *nonce.Pool        // optional, spreads transactions over several api keys
+	nonceFetcher nonce.NonceFetcher // optional, overrides the nonce manager's fetcher, see SignerClient.WithContext
 	chainId      uint32
-	keyManager   signer.KeyManager
+	keys         *keyHolder // shared by copies, so a rotated key is used by all of them
 	accountIndex int64
 	apiKeyIndex  uint8
 }
@@ -26,6 +32,53 @@ type TxClient struct {
 // NewTxClient is linked to a specific (account, apiKey) pair
 // apiKeyPrivateKey should be hex-encoded bytes generated using `hexutil.Encode(TxClient.GetKeyManager().PrvKeyBytes())`
 func NewTxClient(apiClient MinimalHTTPClient, apiKeyPrivateKey string, accountIndex int64, apiKeyIndex uint8, chainId uint32) (*TxClient, error) {
+	keyManager, err := parseKeyManager(apiKeyPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return NewTxClientWithKeyManager(apiClient, keyManager, accountIndex, apiKeyIndex, chainId), nil
+}
+
+// NewTxClientWithKeyManager is linked to a specific (account, apiKey) pair whose key is held by keyManager,
+// e.g. a remote.KeyManager signing in a separate process, so the private key never enters this one
+func NewTxClientWithKeyManager(apiClient MinimalHTTPClient, keyManager signer.KeyManager, accountIndex int64, apiKeyIndex uint8, chainId uint32) *TxClient {
+	txClient := &TxClient{
+		apiClient:    apiClient,
+		apiKeyIndex:  apiKeyIndex,
+		accountIndex: accountIndex,
+		chainId:      chainId,
+		keys:         newKeyHolder(keyManager),
+	}
+	if apiClient != nil {
+		txClient.nonceManager = nonce.NewAPIManager(apiClient)
+	}
+	return txClient
+}
+
+// keyHolder holds the key manager of a TxClient, which is replaced when its api key is rotated
+type keyHolder struct {
+	mu         sync.RWMutex
+	keyManager signer.KeyManager
+}
+
+func newKeyHolder(keyManager signer.KeyManager) *keyHolder {
+	return &keyHolder{keyManager: keyManager}
+}
+
+func (h *keyHolder) load() signer.KeyManager {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.keyManager
+}
+
+func (h *keyHolder) store(keyManager signer.KeyManager) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.keyManager = keyManager
+}
+
+// parseKeyManager creates a key manager from a hex-encoded private key, with or without 0x prefix
+func parseKeyManager(apiKeyPrivateKey string) (signer.KeyManager, error) {
 	// remove 0x from private key, if any, and parse to bytes
 	if len(apiKeyPrivateKey) < 2 {
 		return nil, fmt.Errorf("empty private key")
@@ -39,18 +92,7 @@ func NewTxClient(apiClient MinimalHTTPClient, apiKeyPrivateKey string, accountIn
 		return nil, err
 	}
 
-	keyManager, err := signer.NewKeyManager(b)
-	if err != nil {
-		return nil, err
-	}
-
-	return &TxClient{
-		apiClient:    apiClient,
-		apiKeyIndex:  apiKeyIndex,
-		accountIndex: accountIndex,
-		chainId:      chainId,
-		keyManager:   keyManager,
-	}, nil
+	return signer.NewKeyManager(b)
 }
 
 // FullFillDefaultOps returns a usable TransactOpts object if none was provided.
@@ -66,14 +108,23 @@ func (c *TxClient) FullFillDefaultOps(ops *types.TransactOpts) (*types.TransactO
 	if ops.FromAccountIndex == nil {
 		ops.FromAccountIndex = &c.accountIndex
 	}
+	if ops.ApiKeyIndex == nil && (ops.Nonce == nil || *ops.Nonce == -1) &&
+		c.keyPool != nil && *ops.FromAccountIndex == c.keyPool.AccountIndex() {
+		lease, err := c.keyPool.AcquireWithFetcher(c.nonceFetcher)
+		if err != nil {
+			return nil, err
+		}
+		ops.ApiKeyIndex = &lease.ApiKeyIndex
+		ops.Nonce = &lease.Nonce
+	}
 	if ops.ApiKeyIndex == nil {
 		ops.ApiKeyIndex = &c.apiKeyIndex
 	}
 	if ops.Nonce == nil || *ops.Nonce == -1 {
-		if c.apiClient == nil {
+		if c.nonceManager == nil {
 			return nil, fmt.Errorf("nonce was not provided & HTTPClient is nil. Either provide the nonce or enable HTTPClient to get the nonce from Lighter")
 		}
-		nonce, err := c.apiClient.GetNextNonce(*ops.FromAccountIndex, *ops.ApiKeyIndex)
+		nonce, err := nonce.GetNonceWith(c.nonceManager, c.nonceFetcher, *ops.FromAccountIndex, *ops.ApiKeyIndex)
 		if err != nil {
 			return nil, err
 		}
@@ -83,12 +134,46 @@ func (c *TxClient) FullFillDefaultOps(ops *types.TransactOpts) (*types.TransactO
 	return ops, nil
 }
 
+// signTx fills ops and signs a transaction with sign. If signing fails, the nonce allocated
+// for the transaction is acknowledged as failed, so that the nonce manager does not hand
+// out the next one while this one is never sent.
+func signTx[T txtypes.TxInfo](c *TxClient, ops *types.TransactOpts, sign func(key signer.KeyManager, ops *types.TransactOpts) (T, error)) (T, error) {
+	allocated := ops == nil || ops.Nonce == nil || *ops.Nonce == -1
+	ops, err := c.FullFillDefaultOps(ops)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	var txInfo T
+	key, err := c.signingKey(ops)
+	if err == nil {
+		txInfo, err = sign(key, ops)
+	}
+	if err != nil && allocated {
+		c.nonceManager.AcknowledgeFailure(*ops.FromAccountIndex, *ops.ApiKeyIndex, *ops.Nonce)
+	}
+	return txInfo, err
+}
+
+// signingKey returns the key manager of the api key the transaction is signed with, or
+// nonce.ErrKeyNotInPool for an api key of the pool's account the pool does not own
+func (c *TxClient) signingKey(ops *types.TransactOpts) (signer.KeyManager, error) {
+	if c.keyPool != nil && *ops.FromAccountIndex == c.keyPool.AccountIndex() {
+		km, ok := c.keyPool.KeyManager(*ops.ApiKeyIndex)
+		if !ok {
+			return nil, nonce.ErrKeyNotInPool
+		}
+		return km, nil
+	}
+	return c.keys.load(), nil
+}
+
 func (c *TxClient) GetChainId() uint32 {
 	return c.chainId
 }
 
 func (c *TxClient) GetKeyManager() signer.KeyManager {
-	return c.keyManager
+	return c.keys.load()
 }
 
 func (c *TxClient) GetAccountIndex() int64 {
@@ -99,6 +184,22 @@ func (c *TxClient) GetApiKeyIndex() uint8 {
 	return c.apiKeyIndex
 }
 
+// NonceManager returns the manager used to allocate nonces when none is provided in TransactOpts
+func (c *TxClient) NonceManager() nonce.Manager {
+	return c.nonceManager
+}
+
+// SetNonceManager replaces the manager used to allocate nonces.
+// By default, a TxClient with an HTTP client fetches every nonce from the API.
+func (c *TxClient) SetNonceManager(m nonce.Manager) {
+	c.nonceManager = m
+}
+
+// KeyPool returns the pool of api keys transactions are spread over, nil if the client signs with a single key
+func (c *TxClient) KeyPool() *nonce.Pool {
+	return c.keyPool
+}
+
 func (c *TxClient) HTTP() MinimalHTTPClient {
 	return c.apiClient
 }
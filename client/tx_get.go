@@ -6,204 +6,116 @@ import (
 
 	schnorr "github.com/elliottech/poseidon_crypto/signature/schnorr"
 
+	"github.com/0xJord4n/lighter-go/signer"
 	"github.com/0xJord4n/lighter-go/types"
 	"github.com/0xJord4n/lighter-go/types/txtypes"
 )
 
 func (c *TxClient) GetAuthToken(deadline time.Time) (string, error) {
-	return types.ConstructAuthToken(c.keyManager, deadline, &types.TransactOpts{
+	return types.ConstructAuthToken(c.keys.load(), deadline, &types.TransactOpts{
 		ApiKeyIndex:      &c.apiKeyIndex,
 		FromAccountIndex: &c.accountIndex,
 	})
 }
 
 func (c *TxClient) GetChangePubKeyTransaction(tx *types.ChangePubKeyReq, ops *types.TransactOpts) (*txtypes.L2ChangePubKeyTxInfo, error) {
-	ops, err := c.FullFillDefaultOps(ops)
-	if err != nil {
-		return nil, err
-	}
-	txInfo, err := types.ConstructChangePubKeyTx(c.keyManager, c.chainId, tx, ops)
-	if err != nil {
-		return nil, err
-	}
+	return signTx(c, ops, func(key signer.KeyManager, ops *types.TransactOpts) (*txtypes.L2ChangePubKeyTxInfo, error) {
+		txInfo, err := types.ConstructChangePubKeyTx(key, c.chainId, tx, ops)
+		if err != nil {
+			return nil, err
+		}
 
-	pk := c.keyManager.PubKeyBytes()
-	msgHash, _ := txInfo.Hash(c.chainId)
+		pk := key.PubKeyBytes()
+		msgHash, _ := txInfo.Hash(c.chainId)
 
-	if err := schnorr.Validate(pk[:], msgHash, txInfo.Sig); err != nil {
-		return nil, fmt.Errorf("failed to validate signature. error: %v", err)
-	}
+		if err := schnorr.Validate(pk[:], msgHash, txInfo.Sig); err != nil {
+			return nil, fmt.Errorf("failed to validate signature. error: %v", err)
+		}
 
-	return txInfo, nil
+		return txInfo, nil
+	})
 }
 
 func (c *TxClient) GetCreateSubAccountTransaction(ops *types.TransactOpts) (*txtypes.L2CreateSubAccountTxInfo, error) {
-	ops, err := c.FullFillDefaultOps(ops)
-	if err != nil {
-		return nil, err
-	}
-	txInfo, err := types.ConstructCreateSubAccountTx(c.keyManager, c.chainId, ops)
-	if err != nil {
-		return nil, err
-	}
-	return txInfo, nil
+	return signTx(c, ops, func(key signer.KeyManager, ops *types.TransactOpts) (*txtypes.L2CreateSubAccountTxInfo, error) {
+		return types.ConstructCreateSubAccountTx(key, c.chainId, ops)
+	})
 }
 
 func (c *TxClient) GetCreatePublicPoolTransaction(tx *types.CreatePublicPoolTxReq, ops *types.TransactOpts) (*txtypes.L2CreatePublicPoolTxInfo, error) {
-	ops, err := c.FullFillDefaultOps(ops)
-	if err != nil {
-		return nil, err
-	}
-	txInfo, err := types.ConstructCreatePublicPoolTx(c.keyManager, c.chainId, tx, ops)
-	if err != nil {
-		return nil, err
-	}
-	return txInfo, nil
+	return signTx(c, ops, func(key signer.KeyManager, ops *types.TransactOpts) (*txtypes.L2CreatePublicPoolTxInfo, error) {
+		return types.ConstructCreatePublicPoolTx(key, c.chainId, tx, ops)
+	})
 }
 
 func (c *TxClient) GetUpdatePublicPoolTransaction(tx *types.UpdatePublicPoolTxReq, ops *types.TransactOpts) (*txtypes.L2UpdatePublicPoolTxInfo, error) {
-	ops, err := c.FullFillDefaultOps(ops)
-	if err != nil {
-		return nil, err
-	}
-	txInfo, err := types.ConstructUpdatePublicPoolTx(c.keyManager, c.chainId, tx, ops)
-	if err != nil {
-		return nil, err
-	}
-	return txInfo, nil
+	return signTx(c, ops, func(key signer.KeyManager, ops *types.TransactOpts) (*txtypes.L2UpdatePublicPoolTxInfo, error) {
+		return types.ConstructUpdatePublicPoolTx(key, c.chainId, tx, ops)
+	})
 }
 
 func (c *TxClient) GetTransferTransaction(tx *types.TransferTxReq, ops *types.TransactOpts) (*txtypes.L2TransferTxInfo, error) {
-	ops, err := c.FullFillDefaultOps(ops)
-	if err != nil {
-		return nil, err
-	}
-	txInfo, err := types.ConstructTransferTx(c.keyManager, c.chainId, tx, ops)
-	if err != nil {
-		return nil, err
-	}
-	return txInfo, nil
+	return signTx(c, ops, func(key signer.KeyManager, ops *types.TransactOpts) (*txtypes.L2TransferTxInfo, error) {
+		return types.ConstructTransferTx(key, c.chainId, tx, ops)
+	})
 }
 
 func (c *TxClient) GetWithdrawTransaction(tx *types.WithdrawTxReq, ops *types.TransactOpts) (*txtypes.L2WithdrawTxInfo, error) {
-	ops, err := c.FullFillDefaultOps(ops)
-	if err != nil {
-		return nil, err
-	}
-	txInfo, err := types.ConstructWithdrawTx(c.keyManager, c.chainId, tx, ops)
-	if err != nil {
-		return nil, err
-	}
-
-	return txInfo, nil
+	return signTx(c, ops, func(key signer.KeyManager, ops *types.TransactOpts) (*txtypes.L2WithdrawTxInfo, error) {
+		return types.ConstructWithdrawTx(key, c.chainId, tx, ops)
+	})
 }
 
 func (c *TxClient) GetCreateOrderTransaction(tx *types.CreateOrderTxReq, ops *types.TransactOpts) (*txtypes.L2CreateOrderTxInfo, error) {
-	ops, err := c.FullFillDefaultOps(ops)
-	if err != nil {
-		return nil, err
-	}
-	txInfo, err := types.ConstructCreateOrderTx(c.keyManager, c.chainId, tx, ops)
-	if err != nil {
-		return nil, err
-	}
-	return txInfo, nil
+	return signTx(c, ops, func(key signer.KeyManager, ops *types.TransactOpts) (*txtypes.L2CreateOrderTxInfo, error) {
+		return types.ConstructCreateOrderTx(key, c.chainId, tx, ops)
+	})
 }
 
 func (c *TxClient) GetCreateGroupedOrdersTransaction(tx *types.CreateGroupedOrdersTxReq, ops *types.TransactOpts) (*txtypes.L2CreateGroupedOrdersTxInfo, error) {
-	ops, err := c.FullFillDefaultOps(ops)
-	if err != nil {
-		return nil, err
-	}
-	txInfo, err := types.ConstructL2CreateGroupedOrdersTx(c.keyManager, c.chainId, tx, ops)
-	if err != nil {
-		return nil, err
-	}
-	return txInfo, nil
+	return signTx(c, ops, func(key signer.KeyManager, ops *types.TransactOpts) (*txtypes.L2CreateGroupedOrdersTxInfo, error) {
+		return types.ConstructL2CreateGroupedOrdersTx(key, c.chainId, tx, ops)
+	})
 }
 
 func (c *TxClient) GetCancelOrderTransaction(tx *types.CancelOrderTxReq, ops *types.TransactOpts) (*txtypes.L2CancelOrderTxInfo, error) {
-	ops, err := c.FullFillDefaultOps(ops)
-	if err != nil {
-		return nil, err
-	}
-	txInfo, err := types.ConstructL2CancelOrderTx(c.keyManager, c.chainId, tx, ops)
-	if err != nil {
-		return nil, err
-	}
-	return txInfo, nil
+	return signTx(c, ops, func(key signer.KeyManager, ops *types.TransactOpts) (*txtypes.L2CancelOrderTxInfo, error) {
+		return types.ConstructL2CancelOrderTx(key, c.chainId, tx, ops)
+	})
 }
 
 func (c *TxClient) GetModifyOrderTransaction(tx *types.ModifyOrderTxReq, ops *types.TransactOpts) (*txtypes.L2ModifyOrderTxInfo, error) {
-	ops, err := c.FullFillDefaultOps(ops)
-	if err != nil {
-		return nil, err
-	}
-
-	txInfo, err := types.ConstructL2ModifyOrderTx(c.keyManager, c.chainId, tx, ops)
-	if err != nil {
-		return nil, err
-	}
-
-	return txInfo, nil
+	return signTx(c, ops, func(key signer.KeyManager, ops *types.TransactOpts) (*txtypes.L2ModifyOrderTxInfo, error) {
+		return types.ConstructL2ModifyOrderTx(key, c.chainId, tx, ops)
+	})
 }
 
 func (c *TxClient) GetCancelAllOrdersTransaction(tx *types.CancelAllOrdersTxReq, ops *types.TransactOpts) (*txtypes.L2CancelAllOrdersTxInfo, error) {
-	ops, err := c.FullFillDefaultOps(ops)
-	if err != nil {
-		return nil, err
-	}
-	txInfo, err := types.ConstructL2CancelAllOrdersTx(c.keyManager, c.chainId, tx, ops)
-	if err != nil {
-		return nil, err
-	}
-	return txInfo, nil
+	return signTx(c, ops, func(key signer.KeyManager, ops *types.TransactOpts) (*txtypes.L2CancelAllOrdersTxInfo, error) {
+		return types.ConstructL2CancelAllOrdersTx(key, c.chainId, tx, ops)
+	})
 }
 
 func (c *TxClient) GetMintSharesTransaction(tx *types.MintSharesTxReq, ops *types.TransactOpts) (*txtypes.L2MintSharesTxInfo, error) {
-	ops, err := c.FullFillDefaultOps(ops)
-	if err != nil {
-		return nil, err
-	}
-	txInfo, err := types.ConstructMintSharesTx(c.keyManager, c.chainId, tx, ops)
-	if err != nil {
-		return nil, err
-	}
-	return txInfo, nil
+	return signTx(c, ops, func(key signer.KeyManager, ops *types.TransactOpts) (*txtypes.L2MintSharesTxInfo, error) {
+		return types.ConstructMintSharesTx(key, c.chainId, tx, ops)
+	})
 }
 
 func (c *TxClient) GetBurnSharesTransaction(tx *types.BurnSharesTxReq, ops *types.TransactOpts) (*txtypes.L2BurnSharesTxInfo, error) {
-	ops, err := c.FullFillDefaultOps(ops)
-	if err != nil {
-		return nil, err
-	}
-	txInfo, err := types.ConstructBurnSharesTx(c.keyManager, c.chainId, tx, ops)
-	if err != nil {
-		return nil, err
-	}
-	return txInfo, nil
+	return signTx(c, ops, func(key signer.KeyManager, ops *types.TransactOpts) (*txtypes.L2BurnSharesTxInfo, error) {
+		return types.ConstructBurnSharesTx(key, c.chainId, tx, ops)
+	})
 }
 
 func (c *TxClient) GetUpdateLeverageTransaction(tx *types.UpdateLeverageTxReq, ops *types.TransactOpts) (*txtypes.L2UpdateLeverageTxInfo, error) {
-	ops, err := c.FullFillDefaultOps(ops)
-	if err != nil {
-		return nil, err
-	}
-	txInfo, err := types.ConstructUpdateLeverageTx(c.keyManager, c.chainId, tx, ops)
-	if err != nil {
-		return nil, err
-	}
-	return txInfo, nil
+	return signTx(c, ops, func(key signer.KeyManager, ops *types.TransactOpts) (*txtypes.L2UpdateLeverageTxInfo, error) {
+		return types.ConstructUpdateLeverageTx(key, c.chainId, tx, ops)
+	})
 }
 
 func (c *TxClient) GetUpdateMarginTransaction(tx *types.UpdateMarginTxReq, ops *types.TransactOpts) (*txtypes.L2UpdateMarginTxInfo, error) {
-	ops, err := c.FullFillDefaultOps(ops)
-	if err != nil {
-		return nil, err
-	}
-	txInfo, err := types.ConstructUpdateMarginTx(c.keyManager, c.chainId, tx, ops)
-	if err != nil {
-		return nil, err
-	}
-	return txInfo, nil
+	return signTx(c, ops, func(key signer.KeyManager, ops *types.TransactOpts) (*txtypes.L2UpdateMarginTxInfo, error) {
+		return types.ConstructUpdateMarginTx(key, c.chainId, tx, ops)
+	})
 }
@@ -0,0 +1,309 @@
+package ws
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/0xJord4n/lighter-go/types/api"
+	"github.com/bytedance/sonic"
+)
+
+// OrderEvent carries the orders of an account_all, account_market, account_orders or account_all_orders update
+type OrderEvent struct {
+	AccountIndex int64
+	Channel      string
+	Orders       []OrderUpdate
+	Raw          RawMessage // Undecoded payload, for fields not covered by OrderUpdate
+}
+
+// PositionEvent carries the positions of an account_all, account_market or account_all_positions update
+type PositionEvent struct {
+	AccountIndex int64
+	Channel      string
+	Positions    []PositionUpdate
+	Raw          RawMessage
+}
+
+// FillEvent carries the trades of an account_all, account_market or account_all_trades update
+type FillEvent struct {
+	AccountIndex int64
+	Channel      string
+	Fills        []Trade
+	Raw          RawMessage
+}
+
+// BalanceEvent carries the balances of an account_all or account_market update
+type BalanceEvent struct {
+	AccountIndex int64
+	Channel      string
+	Balances     []BalanceUpdate
+	Raw          RawMessage
+}
+
+// AccountTxEvent carries the transactions of an account_tx update
+type AccountTxEvent struct {
+	AccountIndex int64
+	Channel      string
+	Txs          []api.Tx
+	Raw          RawMessage
+}
+
+// UserStatsEvent carries a user_stats update
+type UserStatsEvent struct {
+	AccountIndex int64
+	Channel      string
+	Stats        *UserStats
+	Raw          RawMessage
+}
+
+// PoolEvent carries a pool_data or pool_info update; exactly one of Data and Info is set
+type PoolEvent struct {
+	AccountIndex int64
+	Channel      string
+	Data         *PoolData
+	Info         *PoolInfo
+	Raw          RawMessage
+}
+
+// NotificationEvent carries the notifications of a notification update
+type NotificationEvent struct {
+	AccountIndex  int64
+	Channel       string
+	Notifications []Notification
+	Raw           RawMessage
+}
+
+// accountPayload is the payload of account_all and account_market updates.
+// Each section is decoded by decodeList, so it may be a list, a single object,
+// or a map keyed by market index.
+type accountPayload struct {
+	Orders    RawMessage `json:"orders,omitempty"`
+	Positions RawMessage `json:"positions,omitempty"`
+	Trades    RawMessage `json:"trades,omitempty"`
+	Balances  RawMessage `json:"balances,omitempty"`
+}
+
+// publishAccountEvents decodes an account update into typed events and publishes them.
+// The raw AccountUpdate has already been published, so undecodable payloads are not lost.
+func (c *wsClient) publishAccountEvents(accountIndex int64, channelType ChannelType, data RawMessage) error {
+	channel := string(channelType)
+
+	switch channelType {
+	case ChannelAccountAll, ChannelAccountMarket:
+		var payload accountPayload
+		if err := sonic.Unmarshal(data, &payload); err != nil {
+			return fmt.Errorf("failed to parse %s data: %w", channel, err)
+		}
+		if len(payload.Orders) > 0 {
+			if err := c.publishOrders(accountIndex, channel, payload.Orders); err != nil {
+				return err
+			}
+		}
+		if len(payload.Positions) > 0 {
+			if err := c.publishPositions(accountIndex, channel, payload.Positions); err != nil {
+				return err
+			}
+		}
+		if len(payload.Trades) > 0 {
+			if err := c.publishFills(accountIndex, channel, payload.Trades); err != nil {
+				return err
+			}
+		}
+		if len(payload.Balances) > 0 {
+			balances, err := decodeList[BalanceUpdate](payload.Balances)
+			if err != nil {
+				return fmt.Errorf("failed to parse %s balances: %w", channel, err)
+			}
+			publishEvent(c, c.balancePub, &BalanceEvent{
+				AccountIndex: accountIndex,
+				Channel:      channel,
+				Balances:     balances,
+				Raw:          payload.Balances,
+			}, c.options.OnBalanceEvent)
+		}
+		return nil
+
+	case ChannelAccountOrders, ChannelAccountAllOrders:
+		return c.publishOrders(accountIndex, channel, unwrapSection(data, "orders"))
+
+	case ChannelAccountAllPositions:
+		return c.publishPositions(accountIndex, channel, unwrapSection(data, "positions"))
+
+	case ChannelAccountAllTrades:
+		return c.publishFills(accountIndex, channel, unwrapSection(data, "trades"))
+
+	case ChannelAccountTx:
+		raw := unwrapSection(data, "txs")
+		txs, err := decodeList[api.Tx](raw)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s data: %w", channel, err)
+		}
+		publishEvent(c, c.accountTxPub, &AccountTxEvent{
+			AccountIndex: accountIndex,
+			Channel:      channel,
+			Txs:          txs,
+			Raw:          raw,
+		}, c.options.OnAccountTxEvent)
+
+	case ChannelUserStats:
+		var stats UserStats
+		if err := sonic.Unmarshal(data, &stats); err != nil {
+			return fmt.Errorf("failed to parse %s data: %w", channel, err)
+		}
+		publishEvent(c, c.userStatsPub, &UserStatsEvent{
+			AccountIndex: accountIndex,
+			Channel:      channel,
+			Stats:        &stats,
+			Raw:          data,
+		}, c.options.OnUserStatsEvent)
+
+	case ChannelPoolData, ChannelPoolInfo:
+		event := &PoolEvent{AccountIndex: accountIndex, Channel: channel, Raw: data}
+		if channelType == ChannelPoolData {
+			event.Data = &PoolData{AccountIndex: accountIndex, Data: data}
+		} else {
+			event.Info = &PoolInfo{AccountIndex: accountIndex, Data: data}
+		}
+		publishEvent(c, c.poolPub, event, c.options.OnPoolEvent)
+
+	case ChannelNotification:
+		raw := unwrapSection(data, "notifications")
+		notifications, err := decodeList[Notification](raw)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s data: %w", channel, err)
+		}
+		publishEvent(c, c.notificationPub, &NotificationEvent{
+			AccountIndex:  accountIndex,
+			Channel:       channel,
+			Notifications: notifications,
+			Raw:           raw,
+		}, c.options.OnNotificationEvent)
+	}
+
+	return nil
+}
+
+func (c *wsClient) publishOrders(accountIndex int64, channel string, raw RawMessage) error {
+	orders, err := decodeList[OrderUpdate](raw)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s orders: %w", channel, err)
+	}
+	publishEvent(c, c.orderPub, &OrderEvent{
+		AccountIndex: accountIndex,
+		Channel:      channel,
+		Orders:       orders,
+		Raw:          raw,
+	}, c.options.OnOrderEvent)
+	return nil
+}
+
+func (c *wsClient) publishPositions(accountIndex int64, channel string, raw RawMessage) error {
+	positions, err := decodeList[PositionUpdate](raw)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s positions: %w", channel, err)
+	}
+	publishEvent(c, c.positionPub, &PositionEvent{
+		AccountIndex: accountIndex,
+		Channel:      channel,
+		Positions:    positions,
+		Raw:          raw,
+	}, c.options.OnPositionEvent)
+	return nil
+}
+
+func (c *wsClient) publishFills(accountIndex int64, channel string, raw RawMessage) error {
+	fills, err := decodeList[Trade](raw)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s trades: %w", channel, err)
+	}
+	publishEvent(c, c.fillPub, &FillEvent{
+		AccountIndex: accountIndex,
+		Channel:      channel,
+		Fills:        fills,
+		Raw:          raw,
+	}, c.options.OnFillEvent)
+	return nil
+}
+
+// publishEvent sends v to its channel and callback
+func publishEvent[T any](c *wsClient, pub *publisher[T], v T, callback func(T)) {
+	c.reportDropped(pub.publish(c.ctx, v))
+
+	if callback != nil {
+		callback(v)
+	}
+}
+
+// unwrapSection returns data[key] if data is an object with that key, and data otherwise
+func unwrapSection(data RawMessage, key string) RawMessage {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '{' {
+		return data
+	}
+	var sections map[string]RawMessage
+	if err := sonic.Unmarshal(data, &sections); err != nil {
+		return data
+	}
+	if section, ok := sections[key]; ok {
+		return section
+	}
+	return data
+}
+
+// decodeList decodes a list of T given as a JSON array, a single object,
+// or an object keyed by market index whose values are arrays or objects
+func decodeList[T any](data RawMessage) ([]T, error) {
+	items := make([]T, 0)
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || string(data) == "null" {
+		return items, nil
+	}
+
+	switch data[0] {
+	case '[':
+		if err := sonic.Unmarshal(data, &items); err != nil {
+			return nil, err
+		}
+		return items, nil
+
+	case '{':
+		var byMarket map[string]RawMessage
+		if err := sonic.Unmarshal(data, &byMarket); err == nil && len(byMarket) > 0 {
+			if keys, ok := marketKeys(byMarket); ok {
+				for _, key := range keys {
+					section, err := decodeList[T](byMarket[key])
+					if err != nil {
+						return nil, err
+					}
+					items = append(items, section...)
+				}
+				return items, nil
+			}
+		}
+		var item T
+		if err := sonic.Unmarshal(data, &item); err != nil {
+			return nil, err
+		}
+		return append(items, item), nil
+	}
+
+	return nil, fmt.Errorf("unexpected JSON value %.20q", string(data))
+}
+
+// marketKeys returns the keys of an object keyed by market index, by ascending market index.
+// ok is false if a key is not a market index.
+func marketKeys(m map[string]RawMessage) (keys []string, ok bool) {
+	markets := make(map[string]int64, len(m))
+	for key := range m {
+		idx, err := strconv.ParseInt(key, 10, 16)
+		if err != nil {
+			return nil, false
+		}
+		markets[key] = idx
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return markets[keys[i]] < markets[keys[j]] })
+	return keys, true
+}
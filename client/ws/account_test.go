@@ -0,0 +1,123 @@
+package ws
+
+import (
+	"testing"
+)
+
+func TestAccountEvents_AccountAll(t *testing.T) {
+	c := NewClient("", DefaultOptions()).(*wsClient)
+
+	msg := []byte(`{"type":"update/account_all","channel":"account_all:42","data":{
+		"orders":{"1":[{"order_index":7,"market_index":1,"status":"open","price":"10.5"}],"0":[{"order_index":3,"market_index":0,"status":"filled","price":"2000"}]},
+		"positions":{"0":{"market_index":0,"size":"1.5","side":"long"}},
+		"trades":[{"trade_index":9,"market_index":0,"price":"2000","size":"0.1"}],
+		"balances":[{"asset_index":0,"balance":"100","available":"80"}],
+		"future_field":true}}`)
+	if err := c.handleMessage(msg); err != nil {
+		t.Fatalf("handleMessage failed: %v", err)
+	}
+
+	raw := <-c.AccountUpdates()
+	if raw.AccountIndex != 42 || len(raw.Data) == 0 {
+		t.Errorf("expected raw account update to be kept, got %+v", raw)
+	}
+
+	orders := <-c.OrderUpdates()
+	if orders.AccountIndex != 42 || orders.Channel != string(ChannelAccountAll) {
+		t.Errorf("unexpected order event: %+v", orders)
+	}
+	// Orders keyed by market are flattened by ascending market index
+	if len(orders.Orders) != 2 || orders.Orders[0].OrderIndex != 3 || orders.Orders[1].OrderIndex != 7 {
+		t.Errorf("unexpected orders: %+v", orders.Orders)
+	}
+
+	positions := <-c.PositionUpdates()
+	if len(positions.Positions) != 1 || positions.Positions[0].Size != "1.5" {
+		t.Errorf("unexpected positions: %+v", positions.Positions)
+	}
+
+	fills := <-c.FillUpdates()
+	if len(fills.Fills) != 1 || fills.Fills[0].TradeIndex != 9 {
+		t.Errorf("unexpected fills: %+v", fills.Fills)
+	}
+
+	balances := <-c.BalanceUpdates()
+	if len(balances.Balances) != 1 || balances.Balances[0].Available != "80" {
+		t.Errorf("unexpected balances: %+v", balances.Balances)
+	}
+}
+
+func TestAccountEvents_SingleChannels(t *testing.T) {
+	var notified *NotificationEvent
+	opts := DefaultOptions().WithOnNotificationEvent(func(e *NotificationEvent) { notified = e })
+	c := NewClient("", opts).(*wsClient)
+
+	messages := [][]byte{
+		[]byte(`{"type":"update/account_orders","channel":"account_orders:0:42","data":{"orders":[{"order_index":1,"status":"canceled"}]}}`),
+		[]byte(`{"type":"update/account_all_positions","channel":"account_all_positions:42","data":[{"market_index":2,"size":"3"}]}`),
+		[]byte(`{"type":"update/account_tx","channel":"account_tx:42","data":{"txs":[{"hash":"0xabc","nonce":5,"status":"confirmed"}]}}`),
+		[]byte(`{"type":"update/user_stats","channel":"user_stats:42","data":{"account_index":42,"total_trades":12}}`),
+		[]byte(`{"type":"update/pool_info","channel":"pool_info:42","data":{"shares":"10"}}`),
+		[]byte(`{"type":"update/notification","channel":"notification:42","data":{"type":"liquidation","message":"hi"}}`),
+	}
+	for _, msg := range messages {
+		if err := c.handleMessage(msg); err != nil {
+			t.Fatalf("handleMessage failed: %v", err)
+		}
+	}
+
+	if e := <-c.OrderUpdates(); e.Channel != string(ChannelAccountOrders) || len(e.Orders) != 1 || e.Orders[0].Status != "canceled" {
+		t.Errorf("unexpected order event: %+v", e)
+	}
+	if e := <-c.PositionUpdates(); len(e.Positions) != 1 || e.Positions[0].MarketIndex != 2 {
+		t.Errorf("unexpected position event: %+v", e)
+	}
+	if e := <-c.AccountTxUpdates(); len(e.Txs) != 1 || e.Txs[0].Hash != "0xabc" || e.Txs[0].Nonce != 5 {
+		t.Errorf("unexpected account tx event: %+v", e)
+	}
+	if e := <-c.UserStatsUpdates(); e.Stats == nil || e.Stats.TotalTrades != 12 {
+		t.Errorf("unexpected user stats event: %+v", e)
+	}
+	if e := <-c.PoolUpdates(); e.Info == nil || e.Data != nil || string(e.Info.Data) != `{"shares":"10"}` {
+		t.Errorf("unexpected pool event: %+v", e)
+	}
+	if e := <-c.NotificationUpdates(); len(e.Notifications) != 1 || e.Notifications[0].Type != "liquidation" || notified != e {
+		t.Errorf("unexpected notification event: %+v", e)
+	}
+}
+
+func TestAccountEvents_InitialSnapshot(t *testing.T) {
+	c := NewClient("", DefaultOptions()).(*wsClient)
+
+	msg := []byte(`{"channel":"account_all_trades:42","data":{"trades":{"0":[{"trade_index":1},{"trade_index":2}]}}}`)
+	if err := c.handleMessage(msg); err != nil {
+		t.Fatalf("handleMessage failed: %v", err)
+	}
+
+	if e := <-c.FillUpdates(); len(e.Fills) != 2 {
+		t.Errorf("expected 2 fills from the initial snapshot, got %+v", e)
+	}
+}
+
+func TestAccountEvents_DecodeErrorKeepsRaw(t *testing.T) {
+	c := NewClient("", DefaultOptions()).(*wsClient)
+
+	msg := []byte(`{"type":"update/account_all_orders","channel":"account_all_orders:42","data":[{"order_index":"not a number"}]}`)
+	if err := c.handleMessage(msg); err == nil {
+		t.Error("expected decode error")
+	}
+
+	select {
+	case raw := <-c.AccountUpdates():
+		if raw.Channel != string(ChannelAccountAllOrders) {
+			t.Errorf("unexpected raw update: %+v", raw)
+		}
+	default:
+		t.Error("expected raw update to be published despite the decode error")
+	}
+	select {
+	case e := <-c.OrderUpdates():
+		t.Errorf("expected no order event, got %+v", e)
+	default:
+	}
+}
@@ -0,0 +1,199 @@
+package ws
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// BackpressurePolicy decides what happens to an event when its channel is full
+type BackpressurePolicy int
+
+const (
+	// BackpressureDropNewest discards the new event (default)
+	BackpressureDropNewest BackpressurePolicy = iota
+	// BackpressureDropOldest discards the oldest buffered event to make room
+	BackpressureDropOldest
+	// BackpressureBlock waits until the consumer reads. This stalls the read loop,
+	// and with it every other channel, until there is room.
+	BackpressureBlock
+	// BackpressureCoalesceLatest replaces a buffered event of the same market with
+	// the new one, so the consumer always gets the latest view of each market.
+	// Channels without markets fall back to BackpressureDropOldest.
+	BackpressureCoalesceLatest
+)
+
+// String returns the name of the policy
+func (p BackpressurePolicy) String() string {
+	switch p {
+	case BackpressureDropNewest:
+		return "drop-newest"
+	case BackpressureDropOldest:
+		return "drop-oldest"
+	case BackpressureBlock:
+		return "block"
+	case BackpressureCoalesceLatest:
+		return "coalesce-latest"
+	}
+	return fmt.Sprintf("BackpressurePolicy(%d)", int(p))
+}
+
+// EventChannel identifies an event channel of the client
+type EventChannel string
+
+const (
+	EventOrderBook   EventChannel = "order_book"
+	EventTrade       EventChannel = "trade"
+	EventMarketStats EventChannel = "market_stats"
+	EventHeight      EventChannel = "height"
+	EventAccount     EventChannel = "account"
+	EventTxResult    EventChannel = "tx_result"
+
+	// Typed private account channels
+	EventOrders        EventChannel = "orders"
+	EventPositions     EventChannel = "positions"
+	EventFills         EventChannel = "fills"
+	EventBalances      EventChannel = "balances"
+	EventAccountTx     EventChannel = "account_tx"
+	EventUserStats     EventChannel = "user_stats"
+	EventPool          EventChannel = "pool"
+	EventNotifications EventChannel = "notifications"
+)
+
+// EventStats counts the events an event channel did not deliver
+type EventStats struct {
+	Dropped   uint64 // Events discarded because the channel was full
+	Coalesced uint64 // Events replaced by a newer event of the same market
+}
+
+// publisher delivers events to a channel according to a BackpressurePolicy
+type publisher[T any] struct {
+	mu      sync.Mutex
+	name    EventChannel
+	ch      chan T
+	policy  BackpressurePolicy
+	key     func(T) int64 // Market of an event, for coalescing; nil if the channel has no markets
+	stats   EventStats
+	lagging uint64 // Events dropped since the last delivery without drops
+}
+
+func newPublisher[T any](name EventChannel, size int, policy BackpressurePolicy, key func(T) int64) *publisher[T] {
+	return &publisher[T]{
+		name:   name,
+		ch:     make(chan T, size),
+		policy: policy,
+		key:    key,
+	}
+}
+
+// publish delivers v and returns a *DroppedEventsError when the consumer fell behind and events were lost.
+// Only the first publish of a lagging period reports an error.
+func (p *publisher[T]) publish(ctx context.Context, v T) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	dropped := p.deliver(ctx, v)
+	if dropped == 0 {
+		p.lagging = 0
+		return nil
+	}
+
+	p.stats.Dropped += dropped
+	first := p.lagging == 0
+	p.lagging += dropped
+	if !first {
+		return nil
+	}
+	return &DroppedEventsError{Channel: p.name, Policy: p.policy, Total: p.stats.Dropped}
+}
+
+// deliver sends v and returns the number of dropped events
+func (p *publisher[T]) deliver(ctx context.Context, v T) uint64 {
+	select {
+	case p.ch <- v:
+		return 0
+	default:
+	}
+	if cap(p.ch) == 0 && p.policy != BackpressureBlock {
+		return 1
+	}
+
+	switch p.policy {
+	case BackpressureBlock:
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		select {
+		case p.ch <- v:
+			return 0
+		case <-ctx.Done():
+			return 1
+		}
+
+	case BackpressureCoalesceLatest:
+		if p.key != nil {
+			return p.coalesce(v)
+		}
+		return p.dropOldest(v)
+
+	case BackpressureDropOldest:
+		return p.dropOldest(v)
+	}
+	return 1
+}
+
+func (p *publisher[T]) dropOldest(v T) uint64 {
+	var dropped uint64
+	for {
+		select {
+		case p.ch <- v:
+			return dropped
+		default:
+		}
+		select {
+		case <-p.ch:
+			dropped++
+		default:
+		}
+	}
+}
+
+// coalesce removes the buffered events of v's market and enqueues v.
+// Buffered events of other markets keep their order.
+func (p *publisher[T]) coalesce(v T) uint64 {
+	market := p.key(v)
+	buffered := make([]T, 0, cap(p.ch))
+drain:
+	for {
+		select {
+		case e := <-p.ch:
+			if p.key(e) == market {
+				p.stats.Coalesced++
+				continue
+			}
+			buffered = append(buffered, e)
+		default:
+			break drain
+		}
+	}
+	buffered = append(buffered, v)
+
+	// Nothing else sends while the lock is held, so there is room for all but
+	// the oldest events if no event of this market was buffered
+	var dropped uint64
+	for len(buffered) > cap(p.ch) {
+		buffered = buffered[1:]
+		dropped++
+	}
+	for _, e := range buffered {
+		p.ch <- e
+	}
+	return dropped
+}
+
+// snapshot returns the counters of the publisher
+func (p *publisher[T]) snapshot() EventStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stats
+}
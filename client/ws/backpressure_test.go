@@ -0,0 +1,167 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type testEvent struct {
+	market int64
+	seq    int
+}
+
+func testEventMarket(e testEvent) int64 { return e.market }
+
+func drainEvents(ch chan testEvent) []testEvent {
+	var events []testEvent
+	for {
+		select {
+		case e := <-ch:
+			events = append(events, e)
+		default:
+			return events
+		}
+	}
+}
+
+func TestPublisher_DropNewest(t *testing.T) {
+	p := newPublisher[testEvent](EventTrade, 2, BackpressureDropNewest, nil)
+
+	for i := 0; i < 2; i++ {
+		if err := p.publish(nil, testEvent{seq: i}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	err := p.publish(nil, testEvent{seq: 2})
+	var dropErr *DroppedEventsError
+	if !errors.As(err, &dropErr) || !errors.Is(err, ErrEventsDropped) || dropErr.Channel != EventTrade {
+		t.Fatalf("expected DroppedEventsError for trade channel, got %v", err)
+	}
+
+	// Only the first drop of a lagging period is reported
+	if err := p.publish(nil, testEvent{seq: 3}); err != nil {
+		t.Errorf("expected no error while still lagging, got %v", err)
+	}
+
+	events := drainEvents(p.ch)
+	if len(events) != 2 || events[0].seq != 0 || events[1].seq != 1 {
+		t.Errorf("expected first events to be kept, got %v", events)
+	}
+	if stats := p.snapshot(); stats.Dropped != 2 {
+		t.Errorf("expected 2 dropped events, got %d", stats.Dropped)
+	}
+
+	// Delivering again ends the lagging period
+	p.publish(nil, testEvent{seq: 4})
+	p.publish(nil, testEvent{seq: 5})
+	if err := p.publish(nil, testEvent{seq: 6}); err == nil {
+		t.Error("expected a new drop to be reported")
+	}
+}
+
+func TestPublisher_DropOldest(t *testing.T) {
+	p := newPublisher[testEvent](EventAccount, 2, BackpressureDropOldest, nil)
+
+	for i := 0; i < 4; i++ {
+		p.publish(nil, testEvent{seq: i})
+	}
+
+	events := drainEvents(p.ch)
+	if len(events) != 2 || events[0].seq != 2 || events[1].seq != 3 {
+		t.Errorf("expected latest events to be kept, got %v", events)
+	}
+	if stats := p.snapshot(); stats.Dropped != 2 {
+		t.Errorf("expected 2 dropped events, got %d", stats.Dropped)
+	}
+}
+
+func TestPublisher_CoalesceLatest(t *testing.T) {
+	p := newPublisher(EventOrderBook, 3, BackpressureCoalesceLatest, testEventMarket)
+
+	p.publish(nil, testEvent{market: 0, seq: 0})
+	p.publish(nil, testEvent{market: 1, seq: 1})
+	p.publish(nil, testEvent{market: 0, seq: 2})
+	if err := p.publish(nil, testEvent{market: 0, seq: 3}); err != nil {
+		t.Errorf("coalescing should not report dropped events, got %v", err)
+	}
+
+	events := drainEvents(p.ch)
+	if len(events) != 2 || events[0] != (testEvent{1, 1}) || events[1] != (testEvent{0, 3}) {
+		t.Errorf("expected latest event per market in order, got %v", events)
+	}
+	if stats := p.snapshot(); stats.Coalesced != 2 || stats.Dropped != 0 {
+		t.Errorf("expected 2 coalesced and 0 dropped events, got %+v", stats)
+	}
+}
+
+func TestPublisher_CoalesceLatest_AllMarketsBuffered(t *testing.T) {
+	p := newPublisher(EventMarketStats, 2, BackpressureCoalesceLatest, testEventMarket)
+
+	p.publish(nil, testEvent{market: 0, seq: 0})
+	p.publish(nil, testEvent{market: 1, seq: 1})
+	if err := p.publish(nil, testEvent{market: 2, seq: 2}); err == nil {
+		t.Error("expected dropped event to be reported")
+	}
+
+	events := drainEvents(p.ch)
+	if len(events) != 2 || events[0].market != 1 || events[1].market != 2 {
+		t.Errorf("expected oldest market to be dropped, got %v", events)
+	}
+}
+
+func TestPublisher_Block(t *testing.T) {
+	p := newPublisher[testEvent](EventTxResult, 1, BackpressureBlock, nil)
+	p.publish(nil, testEvent{seq: 0})
+
+	done := make(chan error, 1)
+	go func() { done <- p.publish(context.Background(), testEvent{seq: 1}) }()
+
+	select {
+	case <-done:
+		t.Fatal("publish should block while the channel is full")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	<-p.ch
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if e := <-p.ch; e.seq != 1 {
+		t.Errorf("expected blocked event to be delivered, got %v", e)
+	}
+
+	// A cancelled context stops waiting and counts the event as dropped
+	p.publish(nil, testEvent{seq: 2})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := p.publish(ctx, testEvent{seq: 3}); !errors.Is(err, ErrEventsDropped) {
+		t.Errorf("expected ErrEventsDropped, got %v", err)
+	}
+}
+
+func TestClient_DroppedEventsReported(t *testing.T) {
+	opts := DefaultOptions()
+	opts.TradeBufferSize = 1
+	c := NewClient("", opts).(*wsClient)
+
+	msg := []byte(`{"type":"update/trade","channel":"trade:0","data":[{"trade_index":1,"price":"1","size":"1"}]}`)
+	for i := 0; i < 2; i++ {
+		if err := c.handleMessage(msg); err != nil {
+			t.Fatalf("handleMessage failed: %v", err)
+		}
+	}
+
+	select {
+	case err := <-c.Errors():
+		if !errors.Is(err, ErrEventsDropped) {
+			t.Errorf("expected ErrEventsDropped, got %v", err)
+		}
+	default:
+		t.Error("expected dropped trade update to be reported on Errors()")
+	}
+	if stats := c.EventStats()[EventTrade]; stats.Dropped != 1 {
+		t.Errorf("expected 1 dropped trade update, got %d", stats.Dropped)
+	}
+}
@@ -5,20 +5,23 @@
 //   - Trade streaming
 //   - Market stats streaming
 //   - Block height streaming
-//   - Account updates streaming (requires auth)
+//   - Account updates streaming (requires auth), raw and decoded into typed events
 //   - Transaction sending via WebSocket
-//   - Automatic reconnection with exponential backoff
-//   - Ping/pong keepalive
+//   - Supervised reconnection with jittered exponential backoff and confirmed resubscription
+//   - Ping/pong keepalive and a per-subscription silence watchdog that drop dead connections
 //   - Both channel-based and callback-based APIs
 package ws
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/0xJord4n/lighter-go/ratelimit"
+	"github.com/0xJord4n/lighter-go/types/txtypes"
 	"github.com/bytedance/sonic"
 	"github.com/coder/websocket"
 )
@@ -42,7 +45,7 @@ type Client interface {
 	SubscribeHeight() error
 	UnsubscribeHeight() error
 
-	// Private channel subscriptions (require auth token)
+	// Private channel subscriptions (require an auth token; empty uses Options.AuthProvider)
 	SubscribeAccountAll(accountIndex int64, authToken string) error
 	UnsubscribeAccountAll(accountIndex int64) error
 	SubscribeAccountMarket(marketIndex int16, accountIndex int64, authToken string) error
@@ -69,6 +72,8 @@ type Client interface {
 	// Transaction sending via WebSocket
 	SendTx(tx interface{}) error
 	SendTxBatch(txs []interface{}) error
+	SendTxAndWait(ctx context.Context, tx txtypes.TxInfo) (*TxResult, error)
+	SendTxBatchAndWait(ctx context.Context, txs []txtypes.TxInfo) ([]*TxResult, error)
 
 	// Event channels (Go-idiomatic)
 	OrderBookUpdates() <-chan *OrderBookUpdate
@@ -77,13 +82,28 @@ type Client interface {
 	HeightUpdates() <-chan *HeightUpdate
 	AccountUpdates() <-chan *AccountUpdate
 	TxResults() <-chan *TxResult
+
+	// Typed private account channels, decoded from the account channels.
+	// AccountUpdates still receives the raw payload of every account message.
+	OrderUpdates() <-chan *OrderEvent
+	PositionUpdates() <-chan *PositionEvent
+	FillUpdates() <-chan *FillEvent
+	BalanceUpdates() <-chan *BalanceEvent
+	AccountTxUpdates() <-chan *AccountTxEvent
+	UserStatsUpdates() <-chan *UserStatsEvent
+	PoolUpdates() <-chan *PoolEvent
+	NotificationUpdates() <-chan *NotificationEvent
 	Errors() <-chan error
 
 	// State access
 	GetOrderBookState(marketIndex int16) (*OrderBookState, error)
 
+	// EventStats returns the dropped and coalesced event counters of each event channel
+	EventStats() map[EventChannel]EventStats
+
 	// Status
 	IsConnected() bool
+	State() ConnectionState
 }
 
 type wsClient struct {
@@ -101,27 +121,26 @@ type wsClient struct {
 
 	// Order book state
 	orderBooks  map[int16]*OrderBookState
+	bookStreams map[int16]*bookStream
 	orderBookMu sync.RWMutex
 
-	// Event channels
-	orderBookCh   chan *OrderBookUpdate
-	tradeCh       chan *TradeUpdate
-	marketStatsCh chan *MarketStatsUpdate
-	heightCh      chan *HeightUpdate
-	accountCh     chan *AccountUpdate
-	txResultCh    chan *TxResult
-	errorCh       chan error
+	// Event channels, shared by the connections of a Pool
+	*clientEvents
+
+	// Transactions waiting for their result
+	txWaiters *txWaiters
 
 	// Lifecycle
+	state     atomic.Int32 // ConnectionState
 	done      chan struct{}
+	lost      chan struct{} // Closed when the read loop of the current connection exits
 	wg        sync.WaitGroup
 	ctx       context.Context
-	cancel    context.CancelFunc
-	readyCh   chan struct{} // Signals when "connected" message received
+	cancel    context.CancelCauseFunc // Cancels the connection; a cause other than nil drops it as dead
+	readyCh   chan struct{}           // Signals when "connected" message received
 	readyOnce sync.Once
 
-	// Reconnection
-	reconnectAttempts int
+	replaying bool // Fed by a Replayer, outbound messages are discarded
 }
 
 // NewClient creates a new WebSocket client
@@ -130,23 +149,85 @@ func NewClient(endpoint string, options *Options) Client {
 		options = DefaultOptions()
 	}
 
+	return newClient(endpoint, options, newClientEvents(options))
+}
+
+// newClient creates a client publishing to the given event channels
+func newClient(endpoint string, options *Options, events *clientEvents) *wsClient {
 	return &wsClient{
 		endpoint:      endpoint,
 		options:       options,
 		subscriptions: newSubscriptionManager(),
 		orderBooks:    make(map[int16]*OrderBookState),
-		orderBookCh:   make(chan *OrderBookUpdate, options.OrderBookBufferSize),
-		tradeCh:       make(chan *TradeUpdate, options.TradeBufferSize),
-		marketStatsCh: make(chan *MarketStatsUpdate, options.MarketStatsBufferSize),
-		heightCh:      make(chan *HeightUpdate, options.HeightBufferSize),
-		accountCh:     make(chan *AccountUpdate, options.AccountBufferSize),
-		txResultCh:    make(chan *TxResult, options.TxResultBufferSize),
-		errorCh:       make(chan error, options.ErrorBufferSize),
+		bookStreams:   make(map[int16]*bookStream),
+		clientEvents:  events,
+		txWaiters:     newTxWaiters(),
 	}
 }
 
+// clientEvents holds the event channels of a client
+type clientEvents struct {
+	orderBookPub   *publisher[*OrderBookUpdate]
+	tradePub       *publisher[*TradeUpdate]
+	marketStatsPub *publisher[*MarketStatsUpdate]
+	heightPub      *publisher[*HeightUpdate]
+	accountPub     *publisher[*AccountUpdate]
+	txResultPub    *publisher[*TxResult]
+	errorCh        chan error
+
+	// Typed account event channels
+	orderPub        *publisher[*OrderEvent]
+	positionPub     *publisher[*PositionEvent]
+	fillPub         *publisher[*FillEvent]
+	balancePub      *publisher[*BalanceEvent]
+	accountTxPub    *publisher[*AccountTxEvent]
+	userStatsPub    *publisher[*UserStatsEvent]
+	poolPub         *publisher[*PoolEvent]
+	notificationPub *publisher[*NotificationEvent]
+}
+
+func newClientEvents(options *Options) *clientEvents {
+	c := &clientEvents{
+		errorCh: make(chan error, options.ErrorBufferSize),
+	}
+
+	byMarket := func(u *OrderBookUpdate) int64 { return int64(u.MarketIndex) }
+	statsByMarket := func(u *MarketStatsUpdate) int64 { return int64(u.MarketIndex) }
+	c.orderBookPub = newPublisher(EventOrderBook, options.OrderBookBufferSize, options.backpressure(EventOrderBook), byMarket)
+	c.tradePub = newPublisher[*TradeUpdate](EventTrade, options.TradeBufferSize, options.backpressure(EventTrade), nil)
+	c.marketStatsPub = newPublisher(EventMarketStats, options.MarketStatsBufferSize, options.backpressure(EventMarketStats), statsByMarket)
+	c.heightPub = newPublisher[*HeightUpdate](EventHeight, options.HeightBufferSize, options.backpressure(EventHeight), nil)
+	c.accountPub = newPublisher[*AccountUpdate](EventAccount, options.AccountBufferSize, options.backpressure(EventAccount), nil)
+	c.txResultPub = newPublisher[*TxResult](EventTxResult, options.TxResultBufferSize, options.backpressure(EventTxResult), nil)
+
+	size := options.AccountBufferSize
+	c.orderPub = newPublisher[*OrderEvent](EventOrders, size, options.backpressure(EventOrders), nil)
+	c.positionPub = newPublisher[*PositionEvent](EventPositions, size, options.backpressure(EventPositions), nil)
+	c.fillPub = newPublisher[*FillEvent](EventFills, size, options.backpressure(EventFills), nil)
+	c.balancePub = newPublisher[*BalanceEvent](EventBalances, size, options.backpressure(EventBalances), nil)
+	c.accountTxPub = newPublisher[*AccountTxEvent](EventAccountTx, size, options.backpressure(EventAccountTx), nil)
+	c.userStatsPub = newPublisher[*UserStatsEvent](EventUserStats, size, options.backpressure(EventUserStats), nil)
+	c.poolPub = newPublisher[*PoolEvent](EventPool, size, options.backpressure(EventPool), nil)
+	c.notificationPub = newPublisher[*NotificationEvent](EventNotifications, size, options.backpressure(EventNotifications), nil)
+
+	return c
+}
+
 // Connect establishes the WebSocket connection
 func (c *wsClient) Connect(ctx context.Context) error {
+	return c.connect(ctx, false)
+}
+
+// connect dials the server. A reconnect fails with ErrConnectionClosed once Close was called.
+func (c *wsClient) connect(ctx context.Context, reconnecting bool) error {
+	aborted := false
+	defer func() {
+		if aborted {
+			// Wait for the read loop of the aborted connection once connMu is released
+			c.wg.Wait()
+		}
+	}()
+
 	c.connMu.Lock()
 	defer c.connMu.Unlock()
 
@@ -154,8 +235,18 @@ func (c *wsClient) Connect(ctx context.Context) error {
 		return ErrAlreadyConnected
 	}
 
+	if reconnecting {
+		if c.State() == StateClosed {
+			return ErrConnectionClosed
+		}
+		c.setState(StateConnecting)
+	} else {
+		c.state.Store(int32(StateConnecting))
+	}
+
 	conn, _, err := websocket.Dial(ctx, c.endpoint, nil)
 	if err != nil {
+		c.setState(StateDisconnected)
 		return &ConnectionError{Err: err}
 	}
 
@@ -163,28 +254,48 @@ func (c *wsClient) Connect(ctx context.Context) error {
 	conn.SetReadLimit(10 * 1024 * 1024)
 
 	c.conn = conn
-	c.ctx, c.cancel = context.WithCancel(ctx)
+	c.ctx, c.cancel = context.WithCancelCause(ctx)
 	c.done = make(chan struct{})
+	c.lost = make(chan struct{})
 	c.readyCh = make(chan struct{})
 	c.readyOnce = sync.Once{}
 
 	// Start read loop
 	c.wg.Add(1)
-	go c.readLoop()
+	go c.readLoop(c.ctx, conn, c.done, c.lost)
+
+	if c.options.OrderBookStaleAfter > 0 {
+		c.wg.Add(1)
+		go c.staleBookLoop(c.done)
+	}
+
+	// Dead connection detection; cancelling the connection context with a cause
+	// makes the read loop fail and report it
+	if c.options.PingInterval > 0 && c.options.PongTimeout > 0 {
+		c.wg.Add(1)
+		go c.keepaliveLoop(c.ctx, c.cancel, conn, c.done)
+	}
+	if c.watchdogInterval() > 0 {
+		c.wg.Add(1)
+		go c.watchdogLoop(c.ctx, c.cancel, c.done)
+	}
 
 	// Wait for "connected" message from server
 	select {
 	case <-c.readyCh:
 		// Server acknowledged connection
 	case <-time.After(10 * time.Second):
-		c.conn.Close(websocket.StatusGoingAway, "connection timeout") //nolint:errcheck // Already returning timeout error
+		c.abortConnect("connection timeout")
+		aborted = true
 		return ErrConnectionTimeout
 	case <-ctx.Done():
-		c.conn.Close(websocket.StatusGoingAway, "context cancelled") //nolint:errcheck // Already returning context error
+		c.abortConnect("context cancelled")
+		aborted = true
 		return ctx.Err()
 	}
 
 	c.connected.Store(true)
+	c.setState(StateConnected)
 
 	// Notify connect callback
 	if c.options.OnConnect != nil {
@@ -194,23 +305,47 @@ func (c *wsClient) Connect(ctx context.Context) error {
 	return nil
 }
 
-// Close closes the WebSocket connection
+// abortConnect closes a connection the server never acknowledged.
+// It must be called with connMu held; the read loop exits on its own.
+func (c *wsClient) abortConnect(reason string) {
+	c.conn.Close(websocket.StatusGoingAway, reason) //nolint:errcheck // Already returning the connect error
+	c.conn = nil
+	c.cancel(nil)
+	close(c.done)
+	c.done = nil
+	c.setState(StateDisconnected)
+}
+
+// Close closes the WebSocket connection. The client does not reconnect after Close.
 func (c *wsClient) Close() error {
-	c.connMu.Lock()
-	defer c.connMu.Unlock()
+	wasConnected := c.connected.Load()
+	c.setState(StateClosed)
 
-	if !c.connected.Load() && c.conn == nil {
-		return nil
+	err := c.teardown()
+
+	// Clear subscriptions
+	c.subscriptions.Clear()
+
+	// Notify disconnect callback
+	if wasConnected && c.options.OnDisconnect != nil {
+		c.options.OnDisconnect(nil)
 	}
 
+	return err
+}
+
+// teardown stops the current connection and waits for its goroutines, keeping subscriptions
+func (c *wsClient) teardown() error {
+	c.connMu.Lock()
 	c.connected.Store(false)
 
 	if c.cancel != nil {
-		c.cancel()
+		c.cancel(nil)
 	}
 
 	if c.done != nil {
 		close(c.done)
+		c.done = nil
 	}
 
 	var err error
@@ -218,37 +353,19 @@ func (c *wsClient) Close() error {
 		err = c.conn.Close(websocket.StatusNormalClosure, "client closing")
 		c.conn = nil
 	}
+	c.connMu.Unlock()
 
 	c.wg.Wait()
-
-	// Clear subscriptions
-	c.subscriptions.Clear()
-
-	// Notify disconnect callback
-	if c.options.OnDisconnect != nil {
-		c.options.OnDisconnect(nil)
-	}
-
 	return err
 }
 
-// Run connects and blocks until context is cancelled or connection fails
+// Run connects and blocks until the context is cancelled, the client is closed,
+// or reconnecting fails. Lost connections are re-established and resubscribed.
 func (c *wsClient) Run(ctx context.Context) error {
 	if err := c.Connect(ctx); err != nil {
 		return err
 	}
-
-	// Block until context cancelled or connection closed
-	select {
-	case <-ctx.Done():
-		return c.Close()
-	case <-c.done:
-		if c.options.MaxReconnectAttempts == 0 ||
-			c.reconnectAttempts < c.options.MaxReconnectAttempts {
-			return c.reconnect(ctx)
-		}
-		return ErrMaxReconnectAttemptsExceeded
-	}
+	return c.supervise(ctx)
 }
 
 // subscribe is a helper for subscribing to channels
@@ -263,6 +380,14 @@ func (c *wsClient) subscribe(channel, authToken string) error {
 	return c.sendJSON(req)
 }
 
+// resolveAuthToken returns authToken, or a token from the auth provider if it is empty
+func (c *wsClient) resolveAuthToken(authToken string) (string, error) {
+	if authToken != "" || c.options.AuthProvider == nil {
+		return authToken, nil
+	}
+	return c.options.AuthProvider.Token()
+}
+
 // unsubscribe is a helper for unsubscribing from channels
 func (c *wsClient) unsubscribe(channel string) error {
 	req := SubscribeRequest{
@@ -500,6 +625,11 @@ func (c *wsClient) SubscribeAccountAll(accountIndex int64, authToken string) err
 		return ErrNotConnected
 	}
 
+	authToken, err := c.resolveAuthToken(authToken)
+	if err != nil {
+		return err
+	}
+
 	confirmChan, err := c.subscriptions.AddAccount(accountIndex, authToken)
 	if err != nil {
 		return err
@@ -545,6 +675,11 @@ func (c *wsClient) SubscribeAccountMarket(marketIndex int16, accountIndex int64,
 		return ErrNotConnected
 	}
 
+	authToken, err := c.resolveAuthToken(authToken)
+	if err != nil {
+		return err
+	}
+
 	confirmChan, err := c.subscriptions.AddAccountMarket(marketIndex, accountIndex, authToken)
 	if err != nil {
 		return err
@@ -590,6 +725,11 @@ func (c *wsClient) SubscribeAccountOrders(marketIndex int16, accountIndex int64,
 		return ErrNotConnected
 	}
 
+	authToken, err := c.resolveAuthToken(authToken)
+	if err != nil {
+		return err
+	}
+
 	confirmChan, err := c.subscriptions.AddAccountOrders(marketIndex, accountIndex, authToken)
 	if err != nil {
 		return err
@@ -635,6 +775,11 @@ func (c *wsClient) SubscribeAccountAllOrders(accountIndex int64, authToken strin
 		return ErrNotConnected
 	}
 
+	authToken, err := c.resolveAuthToken(authToken)
+	if err != nil {
+		return err
+	}
+
 	confirmChan, err := c.subscriptions.AddAccountAllOrders(accountIndex, authToken)
 	if err != nil {
 		return err
@@ -680,6 +825,11 @@ func (c *wsClient) SubscribeAccountAllTrades(accountIndex int64, authToken strin
 		return ErrNotConnected
 	}
 
+	authToken, err := c.resolveAuthToken(authToken)
+	if err != nil {
+		return err
+	}
+
 	confirmChan, err := c.subscriptions.AddAccountAllTrades(accountIndex, authToken)
 	if err != nil {
 		return err
@@ -725,6 +875,11 @@ func (c *wsClient) SubscribeAccountAllPositions(accountIndex int64, authToken st
 		return ErrNotConnected
 	}
 
+	authToken, err := c.resolveAuthToken(authToken)
+	if err != nil {
+		return err
+	}
+
 	confirmChan, err := c.subscriptions.AddAccountAllPositions(accountIndex, authToken)
 	if err != nil {
 		return err
@@ -770,6 +925,11 @@ func (c *wsClient) SubscribeAccountTx(accountIndex int64, authToken string) erro
 		return ErrNotConnected
 	}
 
+	authToken, err := c.resolveAuthToken(authToken)
+	if err != nil {
+		return err
+	}
+
 	confirmChan, err := c.subscriptions.AddAccountTx(accountIndex, authToken)
 	if err != nil {
 		return err
@@ -815,6 +975,11 @@ func (c *wsClient) SubscribeUserStats(accountIndex int64, authToken string) erro
 		return ErrNotConnected
 	}
 
+	authToken, err := c.resolveAuthToken(authToken)
+	if err != nil {
+		return err
+	}
+
 	confirmChan, err := c.subscriptions.AddUserStats(accountIndex, authToken)
 	if err != nil {
 		return err
@@ -860,6 +1025,11 @@ func (c *wsClient) SubscribePoolData(accountIndex int64, authToken string) error
 		return ErrNotConnected
 	}
 
+	authToken, err := c.resolveAuthToken(authToken)
+	if err != nil {
+		return err
+	}
+
 	confirmChan, err := c.subscriptions.AddPoolData(accountIndex, authToken)
 	if err != nil {
 		return err
@@ -905,6 +1075,11 @@ func (c *wsClient) SubscribePoolInfo(accountIndex int64, authToken string) error
 		return ErrNotConnected
 	}
 
+	authToken, err := c.resolveAuthToken(authToken)
+	if err != nil {
+		return err
+	}
+
 	confirmChan, err := c.subscriptions.AddPoolInfo(accountIndex, authToken)
 	if err != nil {
 		return err
@@ -950,6 +1125,11 @@ func (c *wsClient) SubscribeNotification(accountIndex int64, authToken string) e
 		return ErrNotConnected
 	}
 
+	authToken, err := c.resolveAuthToken(authToken)
+	if err != nil {
+		return err
+	}
+
 	confirmChan, err := c.subscriptions.AddNotification(accountIndex, authToken)
 	if err != nil {
 		return err
@@ -995,6 +1175,10 @@ func (c *wsClient) SendTx(tx interface{}) error {
 		return ErrNotConnected
 	}
 
+	if err := c.acquire(ratelimit.EndpointWsSendTx, 1); err != nil {
+		return err
+	}
+
 	req := SendTxRequest{
 		Type: "jsonapi/sendtx",
 		Data: tx,
@@ -1008,10 +1192,14 @@ func (c *wsClient) SendTxBatch(txs []interface{}) error {
 		return ErrNotConnected
 	}
 
-	if len(txs) > 50 {
+	if len(txs) > maxTxBatchSize {
 		return ErrBatchTooLarge
 	}
 
+	if err := c.acquire(ratelimit.EndpointWsSendTxBatch, len(txs)); err != nil {
+		return err
+	}
+
 	req := SendTxBatchRequest{
 		Type: "jsonapi/sendtxbatch",
 		Data: txs,
@@ -1019,38 +1207,94 @@ func (c *wsClient) SendTxBatch(txs []interface{}) error {
 	return c.sendJSON(req)
 }
 
+// acquire charges a frame of txCount transactions against the rate limiter, if one is configured
+func (c *wsClient) acquire(endpoint string, txCount int) error {
+	if c.options.RateLimiter == nil {
+		return nil
+	}
+
+	// connect replaces the context on every reconnect
+	c.connMu.RLock()
+	ctx := c.ctx
+	c.connMu.RUnlock()
+	if ctx == nil {
+		return ErrNotConnected
+	}
+	return c.options.RateLimiter.AcquireN(ctx, endpoint, c.options.RateLimitAccountIndex, txCount)
+}
+
 // OrderBookUpdates returns the channel for order book updates
-func (c *wsClient) OrderBookUpdates() <-chan *OrderBookUpdate {
-	return c.orderBookCh
+func (c *clientEvents) OrderBookUpdates() <-chan *OrderBookUpdate {
+	return c.orderBookPub.ch
 }
 
 // TradeUpdates returns the channel for trade updates
-func (c *wsClient) TradeUpdates() <-chan *TradeUpdate {
-	return c.tradeCh
+func (c *clientEvents) TradeUpdates() <-chan *TradeUpdate {
+	return c.tradePub.ch
 }
 
 // MarketStatsUpdates returns the channel for market stats updates
-func (c *wsClient) MarketStatsUpdates() <-chan *MarketStatsUpdate {
-	return c.marketStatsCh
+func (c *clientEvents) MarketStatsUpdates() <-chan *MarketStatsUpdate {
+	return c.marketStatsPub.ch
 }
 
 // HeightUpdates returns the channel for height updates
-func (c *wsClient) HeightUpdates() <-chan *HeightUpdate {
-	return c.heightCh
+func (c *clientEvents) HeightUpdates() <-chan *HeightUpdate {
+	return c.heightPub.ch
 }
 
 // AccountUpdates returns the channel for account updates
-func (c *wsClient) AccountUpdates() <-chan *AccountUpdate {
-	return c.accountCh
+func (c *clientEvents) AccountUpdates() <-chan *AccountUpdate {
+	return c.accountPub.ch
 }
 
 // TxResults returns the channel for transaction results
-func (c *wsClient) TxResults() <-chan *TxResult {
-	return c.txResultCh
+func (c *clientEvents) TxResults() <-chan *TxResult {
+	return c.txResultPub.ch
+}
+
+// OrderUpdates returns the channel for decoded order updates
+func (c *clientEvents) OrderUpdates() <-chan *OrderEvent {
+	return c.orderPub.ch
+}
+
+// PositionUpdates returns the channel for decoded position updates
+func (c *clientEvents) PositionUpdates() <-chan *PositionEvent {
+	return c.positionPub.ch
+}
+
+// FillUpdates returns the channel for decoded account trades
+func (c *clientEvents) FillUpdates() <-chan *FillEvent {
+	return c.fillPub.ch
+}
+
+// BalanceUpdates returns the channel for decoded balance updates
+func (c *clientEvents) BalanceUpdates() <-chan *BalanceEvent {
+	return c.balancePub.ch
+}
+
+// AccountTxUpdates returns the channel for decoded account transactions
+func (c *clientEvents) AccountTxUpdates() <-chan *AccountTxEvent {
+	return c.accountTxPub.ch
+}
+
+// UserStatsUpdates returns the channel for decoded user stats
+func (c *clientEvents) UserStatsUpdates() <-chan *UserStatsEvent {
+	return c.userStatsPub.ch
+}
+
+// PoolUpdates returns the channel for pool data and pool info updates
+func (c *clientEvents) PoolUpdates() <-chan *PoolEvent {
+	return c.poolPub.ch
+}
+
+// NotificationUpdates returns the channel for decoded notifications
+func (c *clientEvents) NotificationUpdates() <-chan *NotificationEvent {
+	return c.notificationPub.ch
 }
 
 // Errors returns the channel for errors
-func (c *wsClient) Errors() <-chan error {
+func (c *clientEvents) Errors() <-chan error {
 	return c.errorCh
 }
 
@@ -1067,6 +1311,27 @@ func (c *wsClient) GetOrderBookState(marketIndex int16) (*OrderBookState, error)
 	return state.Clone(), nil
 }
 
+// EventStats returns the dropped and coalesced event counters of each event channel
+func (c *clientEvents) EventStats() map[EventChannel]EventStats {
+	return map[EventChannel]EventStats{
+		EventOrderBook:   c.orderBookPub.snapshot(),
+		EventTrade:       c.tradePub.snapshot(),
+		EventMarketStats: c.marketStatsPub.snapshot(),
+		EventHeight:      c.heightPub.snapshot(),
+		EventAccount:     c.accountPub.snapshot(),
+		EventTxResult:    c.txResultPub.snapshot(),
+
+		EventOrders:        c.orderPub.snapshot(),
+		EventPositions:     c.positionPub.snapshot(),
+		EventFills:         c.fillPub.snapshot(),
+		EventBalances:      c.balancePub.snapshot(),
+		EventAccountTx:     c.accountTxPub.snapshot(),
+		EventUserStats:     c.userStatsPub.snapshot(),
+		EventPool:          c.poolPub.snapshot(),
+		EventNotifications: c.notificationPub.snapshot(),
+	}
+}
+
 // IsConnected returns true if connected
 func (c *wsClient) IsConnected() bool {
 	return c.connected.Load()
@@ -1074,23 +1339,33 @@ func (c *wsClient) IsConnected() bool {
 
 // Internal methods
 
-func (c *wsClient) readLoop() {
+func (c *wsClient) readLoop(ctx context.Context, conn *websocket.Conn, done <-chan struct{}, lost chan struct{}) {
 	defer c.wg.Done()
-	defer c.handleDisconnect(nil)
+	defer close(lost)
 
 	for {
 		select {
-		case <-c.done:
+		case <-done:
+			c.handleDisconnect(nil)
 			return
 		default:
 		}
 
-		_, msg, err := c.conn.Read(c.ctx)
+		_, msg, err := conn.Read(ctx)
 		if err != nil {
+			// Report why the connection was dropped as dead, rather than the read error
+			if cause := context.Cause(ctx); cause != nil && !errors.Is(cause, context.Canceled) {
+				err = cause
+			}
 			c.handleDisconnect(err)
 			return
 		}
 
+		if c.options.Recorder != nil {
+			if err := c.options.Recorder.Record(msg); err != nil {
+				c.sendError(err)
+			}
+		}
 		if err := c.handleMessage(msg); err != nil {
 			c.sendError(err)
 		}
@@ -1103,6 +1378,11 @@ func (c *wsClient) sendPong() error {
 }
 
 func (c *wsClient) sendJSON(v interface{}) error {
+	if c.replaying {
+		// Replies such as pongs have no server to go to
+		return nil
+	}
+
 	c.connMu.RLock()
 	defer c.connMu.RUnlock()
 
@@ -1118,48 +1398,23 @@ func (c *wsClient) sendJSON(v interface{}) error {
 	return c.conn.Write(c.ctx, websocket.MessageText, data)
 }
 
+// handleDisconnect runs when the read loop exits. Callbacks and state resets only
+// apply to an established connection that was lost, not to Close or a failed Connect.
 func (c *wsClient) handleDisconnect(err error) {
-	c.connected.Store(false)
+	c.txWaiters.failAll(ErrConnectionClosed)
 
-	if c.options.OnDisconnect != nil {
-		c.options.OnDisconnect(err)
-	}
-}
-
-func (c *wsClient) reconnect(ctx context.Context) error {
-	c.reconnectAttempts++
-	delay := c.calculateBackoff()
-
-	select {
-	case <-ctx.Done():
-		return ctx.Err()
-	case <-time.After(delay):
+	if !c.connected.Swap(false) {
+		return
 	}
+	c.state.CompareAndSwap(int32(StateConnected), int32(StateDisconnected))
+	c.state.CompareAndSwap(int32(StateResubscribing), int32(StateDisconnected))
 
-	if err := c.Connect(ctx); err != nil {
-		return c.reconnect(ctx)
-	}
+	// Books are rebuilt from the snapshots sent when resubscribing
+	c.invalidateOrderBooks()
 
-	// Resubscribe to all active subscriptions
-	return c.resubscribeAll()
-}
-
-func (c *wsClient) calculateBackoff() time.Duration {
-	delay := c.options.ReconnectDelay * time.Duration(1<<c.reconnectAttempts)
-	if delay > c.options.MaxReconnectDelay {
-		delay = c.options.MaxReconnectDelay
-	}
-	return delay
-}
-
-func (c *wsClient) resubscribeAll() error {
-	subs := c.subscriptions.GetAll()
-	for _, sub := range subs {
-		if err := c.subscribe(sub.channel, sub.authToken); err != nil {
-			c.sendError(err)
-		}
+	if c.options.OnDisconnect != nil {
+		c.options.OnDisconnect(err)
 	}
-	return nil
 }
 
 // Ensure wsClient implements Client
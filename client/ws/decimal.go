@@ -0,0 +1,228 @@
+package ws
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// MaxDecimalScale is the maximum number of fractional digits of a Decimal
+const MaxDecimalScale = 18
+
+var (
+	// ErrInvalidDecimal is returned when parsing a malformed decimal string
+	ErrInvalidDecimal = errors.New("invalid decimal")
+	// ErrDecimalOverflow is returned when a decimal result does not fit in 18 significant digits
+	ErrDecimalOverflow = errors.New("decimal overflow")
+)
+
+var pow10 = [...]int64{
+	1, 1e1, 1e2, 1e3, 1e4, 1e5, 1e6, 1e7, 1e8, 1e9,
+	1e10, 1e11, 1e12, 1e13, 1e14, 1e15, 1e16, 1e17, 1e18,
+}
+
+// Decimal is an exact fixed-point decimal number, as used for prices and sizes.
+// Its value is coef * 10^-scale; trailing fractional zeros are always stripped,
+// so equal values have equal representations.
+type Decimal struct {
+	coef  int64
+	scale uint8
+}
+
+// ParseDecimal parses a decimal string such as "1234.5678" or "-0.01"
+func ParseDecimal(s string) (Decimal, error) {
+	str := s
+	neg := false
+	if str != "" && (str[0] == '-' || str[0] == '+') {
+		neg = str[0] == '-'
+		str = str[1:]
+	}
+
+	intPart, fracPart, _ := strings.Cut(str, ".")
+	if intPart == "" && fracPart == "" {
+		return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+	}
+	fracPart = strings.TrimRight(fracPart, "0")
+	if len(fracPart) > MaxDecimalScale {
+		return Decimal{}, fmt.Errorf("%w: %q has more than %d fractional digits", ErrInvalidDecimal, s, MaxDecimalScale)
+	}
+
+	var coef int64
+	for _, part := range [2]string{intPart, fracPart} {
+		for i := 0; i < len(part); i++ {
+			c := part[i]
+			if c < '0' || c > '9' {
+				return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+			}
+			if coef > (math.MaxInt64-int64(c-'0'))/10 {
+				return Decimal{}, fmt.Errorf("%w: %q", ErrDecimalOverflow, s)
+			}
+			coef = coef*10 + int64(c-'0')
+		}
+	}
+	if neg {
+		coef = -coef
+	}
+	return newDecimal(coef, uint8(len(fracPart))), nil
+}
+
+// MustParseDecimal is like ParseDecimal but panics on error
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// NewDecimal returns coef * 10^-scale
+func NewDecimal(coef int64, scale uint8) (Decimal, error) {
+	if scale > MaxDecimalScale {
+		return Decimal{}, fmt.Errorf("%w: scale %d exceeds %d", ErrInvalidDecimal, scale, MaxDecimalScale)
+	}
+	return newDecimal(coef, scale), nil
+}
+
+// newDecimal normalizes coef * 10^-scale by stripping trailing zeros
+func newDecimal(coef int64, scale uint8) Decimal {
+	for scale > 0 && coef%10 == 0 {
+		coef /= 10
+		scale--
+	}
+	return Decimal{coef: coef, scale: scale}
+}
+
+// Coefficient returns the unscaled value
+func (d Decimal) Coefficient() int64 {
+	return d.coef
+}
+
+// Scale returns the number of fractional digits
+func (d Decimal) Scale() uint8 {
+	return d.scale
+}
+
+// Sign returns -1, 0 or 1
+func (d Decimal) Sign() int {
+	switch {
+	case d.coef < 0:
+		return -1
+	case d.coef > 0:
+		return 1
+	}
+	return 0
+}
+
+// IsZero reports whether d is zero
+func (d Decimal) IsZero() bool {
+	return d.coef == 0
+}
+
+// Cmp compares d and o and returns -1, 0 or 1
+func (d Decimal) Cmp(o Decimal) int {
+	if d.scale == o.scale {
+		return cmpInt64(d.coef, o.coef)
+	}
+	if a, b, ok := align(d, o); ok {
+		return cmpInt64(a, b)
+	}
+
+	// The aligned coefficients overflow int64, compare exactly with big integers
+	a := new(big.Int).Mul(big.NewInt(d.coef), big.NewInt(pow10[o.scale]))
+	b := new(big.Int).Mul(big.NewInt(o.coef), big.NewInt(pow10[d.scale]))
+	return a.Cmp(b)
+}
+
+// Equal reports whether d and o have the same value
+func (d Decimal) Equal(o Decimal) bool {
+	return d == o
+}
+
+// Add returns d + o
+func (d Decimal) Add(o Decimal) (Decimal, error) {
+	a, b, ok := align(d, o)
+	sum := a + b
+	if !ok || (a > 0 && b > 0 && sum < 0) || (a < 0 && b < 0 && sum >= 0) {
+		return Decimal{}, ErrDecimalOverflow
+	}
+	return newDecimal(sum, max(d.scale, o.scale)), nil
+}
+
+// Sub returns d - o
+func (d Decimal) Sub(o Decimal) (Decimal, error) {
+	if o.coef == math.MinInt64 {
+		return Decimal{}, ErrDecimalOverflow
+	}
+	return d.Add(Decimal{coef: -o.coef, scale: o.scale})
+}
+
+// Half returns d / 2, which is always exact with one more fractional digit
+func (d Decimal) Half() (Decimal, error) {
+	if d.coef%2 == 0 {
+		return newDecimal(d.coef/2, d.scale), nil
+	}
+	if d.scale == MaxDecimalScale || d.coef > math.MaxInt64/5 || d.coef < math.MinInt64/5 {
+		return Decimal{}, ErrDecimalOverflow
+	}
+	return newDecimal(d.coef*5, d.scale+1), nil
+}
+
+// Float64 returns the nearest float64 value of d
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// String returns the canonical decimal representation of d, e.g. "9.5"
+func (d Decimal) String() string {
+	s := strconv.FormatInt(d.coef, 10)
+	if d.scale == 0 {
+		return s
+	}
+
+	neg := d.coef < 0
+	if neg {
+		s = s[1:]
+	}
+	if len(s) <= int(d.scale) {
+		s = strings.Repeat("0", int(d.scale)-len(s)+1) + s
+	}
+	s = s[:len(s)-int(d.scale)] + "." + s[len(s)-int(d.scale):]
+	if neg {
+		s = "-" + s
+	}
+	return s
+}
+
+// align returns the coefficients of d and o at their common scale
+func align(d, o Decimal) (int64, int64, bool) {
+	a, b := d.coef, o.coef
+	var ok bool
+	if d.scale < o.scale {
+		a, ok = mulPow10(a, o.scale-d.scale)
+	} else {
+		b, ok = mulPow10(b, d.scale-o.scale)
+	}
+	return a, b, ok
+}
+
+func mulPow10(v int64, n uint8) (int64, bool) {
+	p := pow10[n]
+	if v > math.MaxInt64/p || v < math.MinInt64/p {
+		return 0, false
+	}
+	return v * p, true
+}
+
+func cmpInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -3,6 +3,7 @@ package ws
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
 // Common WebSocket errors
@@ -17,11 +18,20 @@ var (
 	ErrInvalidMessage               = errors.New("invalid message format")
 	ErrSequenceGap                  = errors.New("order book sequence gap detected")
 	ErrOrderBookNotFound            = errors.New("order book state not found")
+	ErrEmptyBookSide                = errors.New("order book side is empty")
+	ErrEventsDropped                = errors.New("events dropped because the consumer fell behind")
+	ErrStaleOrderBook               = errors.New("order book received no update within the stale timeout")
 	ErrAuthTokenRequired            = errors.New("auth token required for private channel subscription")
 	ErrSubscriptionTimeout          = errors.New("subscription confirmation timeout")
 	ErrAlreadySubscribed            = errors.New("already subscribed")
 	ErrNotSubscribed                = errors.New("not subscribed")
 	ErrBatchTooLarge                = errors.New("transaction batch exceeds maximum of 50")
+	ErrTxNotSigned                  = errors.New("transaction is not signed")
+	ErrTxRejected                   = errors.New("transaction rejected")
+	ErrPongTimeout                  = errors.New("no pong received within the pong timeout")
+	ErrSubscriptionSilent           = errors.New("subscription received no message within the silence timeout")
+	ErrPoolFull                     = errors.New("every pool connection is at its subscription limit")
+	ErrInvalidRecording             = errors.New("not a websocket session recording")
 )
 
 // WsError represents an error from the WebSocket server
@@ -75,3 +85,52 @@ func (e *ConnectionError) Error() string {
 func (e *ConnectionError) Unwrap() error {
 	return e.Err
 }
+
+// DroppedEventsError reports that a consumer fell behind on an event channel and events were discarded
+type DroppedEventsError struct {
+	Channel EventChannel
+	Policy  BackpressurePolicy
+	Total   uint64 // Events dropped on this channel since the client was created
+}
+
+// Error implements the error interface
+func (e *DroppedEventsError) Error() string {
+	return fmt.Sprintf("%s events dropped (%s, %d total): consumer fell behind", e.Channel, e.Policy, e.Total)
+}
+
+// Unwrap returns ErrEventsDropped
+func (e *DroppedEventsError) Unwrap() error {
+	return ErrEventsDropped
+}
+
+// TxRejectedError reports a transaction the server answered with an unsuccessful result
+type TxRejectedError struct {
+	TxHash  string
+	Message string
+}
+
+// Error implements the error interface
+func (e *TxRejectedError) Error() string {
+	return fmt.Sprintf("tx %s rejected: %s", e.TxHash, e.Message)
+}
+
+// Unwrap returns ErrTxRejected
+func (e *TxRejectedError) Unwrap() error {
+	return ErrTxRejected
+}
+
+// SilenceError reports a subscription whose channel went quiet, taken as a sign of a dead connection
+type SilenceError struct {
+	Key     string        // Subscription key, e.g. "order_book:0"
+	Silence time.Duration // Time since the last message on the channel
+}
+
+// Error implements the error interface
+func (e *SilenceError) Error() string {
+	return fmt.Sprintf("no message on %s for %v", e.Key, e.Silence.Round(time.Millisecond))
+}
+
+// Unwrap returns ErrSubscriptionSilent
+func (e *SilenceError) Unwrap() error {
+	return ErrSubscriptionSilent
+}
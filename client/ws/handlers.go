@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/bytedance/sonic"
 )
@@ -15,6 +16,10 @@ func (c *wsClient) handleMessage(msg []byte) error {
 		return fmt.Errorf("failed to parse message: %w", err)
 	}
 
+	if base.Channel != "" && len(c.options.SilenceTimeouts) > 0 {
+		c.subscriptions.Touch(base.Channel, time.Now())
+	}
+
 	// Handle messages with explicit type field
 	if base.Type != "" {
 		return c.handleTypedMessage(base)
@@ -169,28 +174,17 @@ func (c *wsClient) handleChannelMessage(base BaseMessage, rawMsg []byte) error {
 		}
 		return nil
 
-	case "account_all":
-		return c.handleSubscribedAccount(base.Channel, ChannelAccountAll)
-	case "account_market":
-		return c.handleSubscribedAccount(base.Channel, ChannelAccountMarket)
-	case "account_orders":
-		return c.handleSubscribedAccount(base.Channel, ChannelAccountOrders)
-	case "account_all_orders":
-		return c.handleSubscribedAccount(base.Channel, ChannelAccountAllOrders)
-	case "account_all_trades":
-		return c.handleSubscribedAccount(base.Channel, ChannelAccountAllTrades)
-	case "account_all_positions":
-		return c.handleSubscribedAccount(base.Channel, ChannelAccountAllPositions)
-	case "account_tx":
-		return c.handleSubscribedAccount(base.Channel, ChannelAccountTx)
-	case "user_stats":
-		return c.handleSubscribedAccount(base.Channel, ChannelUserStats)
-	case "pool_data":
-		return c.handleSubscribedAccount(base.Channel, ChannelPoolData)
-	case "pool_info":
-		return c.handleSubscribedAccount(base.Channel, ChannelPoolInfo)
-	case "notification":
-		return c.handleSubscribedAccount(base.Channel, ChannelNotification)
+	case "account_all", "account_market", "account_orders", "account_all_orders", "account_all_trades",
+		"account_all_positions", "account_tx", "user_stats", "pool_data", "pool_info", "notification":
+		// Initial account subscription, with the current state as data
+		channelType := ChannelType(channelPrefix)
+		if err := c.handleSubscribedAccount(base.Channel, channelType); err != nil {
+			return err
+		}
+		if len(base.Data) > 0 {
+			return c.handleAccountUpdate(base.Channel, channelType, base.Data)
+		}
+		return nil
 
 	default:
 		return nil
@@ -198,8 +192,6 @@ func (c *wsClient) handleChannelMessage(base BaseMessage, rawMsg []byte) error {
 }
 
 func (c *wsClient) handleConnected() error {
-	c.reconnectAttempts = 0
-
 	// Signal that we received the connected message
 	c.readyOnce.Do(func() {
 		close(c.readyCh)
@@ -375,80 +367,113 @@ func (c *wsClient) handleOrderBookUpdate(channel string, data RawMessage) error
 }
 
 func (c *wsClient) handleOrderBookData(marketIndex int16, data RawMessage, isInitial bool) error {
-	// Parse order book data - format: {"bids": [{"price": "...", "size": "..."}, ...], "asks": [...]}
-	var obData struct {
-		Bids []OrderBookLevel `json:"bids"`
-		Asks []OrderBookLevel `json:"asks"`
-	}
+	// Parse order book data - format: {"bids": [{"price": "...", "size": "..."}, ...], "asks": [...], "offset": ..., "nonce": ..., "begin_nonce": ...}
+	var obData orderBookMessage
 	if err := sonic.Unmarshal(data, &obData); err != nil {
 		return fmt.Errorf("failed to parse order book data: %w", err)
 	}
 
-	c.orderBookMu.Lock()
-	state, exists := c.orderBooks[marketIndex]
-	if !exists {
-		state = NewOrderBookState(marketIndex)
-		c.orderBooks[marketIndex] = state
-	}
-	c.orderBookMu.Unlock()
+	state := c.orderBookState(marketIndex)
+	stream := c.bookStream(marketIndex)
 
 	bids := obData.Bids
 	asks := obData.Asks
 
+	stream.mu.Lock()
+	var update *OrderBookUpdate
 	if isInitial {
 		// Apply as snapshot
 		snapshot := &OrderBookSnapshot{
 			MarketIndex: marketIndex,
+			Sequence:    obData.Offset,
 			Bids:        bids,
 			Asks:        asks,
 		}
 		if err := state.ApplySnapshot(snapshot); err != nil {
+			stream.mu.Unlock()
 			return err
 		}
-
-		update := &OrderBookUpdate{
-			MarketIndex: marketIndex,
-			IsSnapshot:  true,
-			Snapshot:    snapshot,
-			State:       state.Clone(),
+		stream.offset, stream.nonce = 0, 0
+		stream.advance(&obData)
+
+		if stream.resyncing {
+			update = c.finishResync(marketIndex, stream, state, snapshot, ResyncSourceResubscribe)
+		} else {
+			update = &OrderBookUpdate{
+				MarketIndex: marketIndex,
+				IsSnapshot:  true,
+				Snapshot:    snapshot,
+				State:       state.Clone(),
+			}
 		}
-
-		select {
-		case c.orderBookCh <- update:
-		default:
+	} else {
+		if stream.resyncing {
+			// Keep deltas until the book is rebuilt
+			c.bufferDelta(stream, obData)
+			stream.mu.Unlock()
+			return nil
 		}
-
-		if c.options.OnOrderBookUpdate != nil {
-			c.options.OnOrderBookUpdate(update)
+		ok, stale := stream.follows(&obData)
+		if stale {
+			stream.mu.Unlock()
+			return nil
 		}
-	} else {
+		if !ok {
+			c.startResync(marketIndex, stream, ErrSequenceGap)
+			c.bufferDelta(stream, obData)
+			stream.mu.Unlock()
+			return nil
+		}
+
 		// Apply as delta - merge updates
 		delta := &OrderBookDelta{
 			MarketIndex: marketIndex,
+			Sequence:    obData.Offset,
 			BidUpdates:  bids,
 			AskUpdates:  asks,
 		}
 
-		state.MergeUpdates(bids, asks)
+		if err := state.MergeUpdates(bids, asks); err != nil {
+			stream.mu.Unlock()
+			return err
+		}
+		stream.advance(&obData)
 
-		update := &OrderBookUpdate{
+		update = &OrderBookUpdate{
 			MarketIndex: marketIndex,
 			IsSnapshot:  false,
 			Delta:       delta,
 			State:       state.Clone(),
 		}
+	}
+	stream.mu.Unlock()
 
-		select {
-		case c.orderBookCh <- update:
-		default:
-		}
+	c.emitOrderBookUpdate(update)
+	return nil
+}
 
-		if c.options.OnOrderBookUpdate != nil {
-			c.options.OnOrderBookUpdate(update)
-		}
+// orderBookState returns the state of a market, creating it if needed
+func (c *wsClient) orderBookState(marketIndex int16) *OrderBookState {
+	c.orderBookMu.Lock()
+	defer c.orderBookMu.Unlock()
+
+	state, exists := c.orderBooks[marketIndex]
+	if !exists {
+		state = NewOrderBookState(marketIndex)
+		c.orderBooks[marketIndex] = state
 	}
+	return state
+}
 
-	return nil
+func (c *wsClient) emitOrderBookUpdate(update *OrderBookUpdate) {
+	c.reportDropped(c.orderBookPub.publish(c.ctx, update))
+
+	if c.options.OnOrderBookUpdate != nil {
+		c.options.OnOrderBookUpdate(update)
+	}
+	if update.Resync != nil && c.options.OnOrderBookResync != nil {
+		c.options.OnOrderBookResync(update.Resync)
+	}
 }
 
 // Trade handlers
@@ -485,10 +510,7 @@ func (c *wsClient) handleTradeUpdate(channel string, data RawMessage) error {
 		Trades:      trades,
 	}
 
-	select {
-	case c.tradeCh <- update:
-	default:
-	}
+	c.reportDropped(c.tradePub.publish(c.ctx, update))
 
 	if c.options.OnTradeUpdate != nil {
 		c.options.OnTradeUpdate(update)
@@ -544,10 +566,7 @@ func (c *wsClient) handleMarketStatsUpdate(channel string, data RawMessage) erro
 		}
 	}
 
-	select {
-	case c.marketStatsCh <- update:
-	default:
-	}
+	c.reportDropped(c.marketStatsPub.publish(c.ctx, update))
 
 	if c.options.OnMarketStatsUpdate != nil {
 		c.options.OnMarketStatsUpdate(update)
@@ -569,10 +588,7 @@ func (c *wsClient) handleHeightUpdate(data RawMessage) error {
 		return fmt.Errorf("failed to parse height data: %w", err)
 	}
 
-	select {
-	case c.heightCh <- &update:
-	default:
-	}
+	c.reportDropped(c.heightPub.publish(c.ctx, &update))
 
 	if c.options.OnHeightUpdate != nil {
 		c.options.OnHeightUpdate(&update)
@@ -601,16 +617,13 @@ func (c *wsClient) handleAccountUpdate(channel string, channelType ChannelType,
 		Data:         data,
 	}
 
-	select {
-	case c.accountCh <- update:
-	default:
-	}
+	c.reportDropped(c.accountPub.publish(c.ctx, update))
 
 	if c.options.OnAccountUpdate != nil {
 		c.options.OnAccountUpdate(update)
 	}
 
-	return nil
+	return c.publishAccountEvents(accountIndex, channelType, data)
 }
 
 // Transaction result handlers
@@ -621,10 +634,8 @@ func (c *wsClient) handleTxResult(data RawMessage) error {
 		return fmt.Errorf("failed to parse tx result: %w", err)
 	}
 
-	select {
-	case c.txResultCh <- &result:
-	default:
-	}
+	c.txWaiters.resolve(&result)
+	c.reportDropped(c.txResultPub.publish(c.ctx, &result))
 
 	if c.options.OnTxResult != nil {
 		c.options.OnTxResult(&result)
@@ -642,10 +653,8 @@ func (c *wsClient) handleTxBatchResult(data RawMessage) error {
 	// Send each result individually
 	for _, result := range batchResult.Results {
 		r := result // avoid closure issue
-		select {
-		case c.txResultCh <- &r:
-		default:
-		}
+		c.txWaiters.resolve(&r)
+		c.reportDropped(c.txResultPub.publish(c.ctx, &r))
 
 		if c.options.OnTxResult != nil {
 			c.options.OnTxResult(&r)
@@ -673,6 +682,13 @@ func (c *wsClient) handleError(data RawMessage) error {
 	return nil
 }
 
+// reportDropped sends the error of a publisher that dropped events
+func (c *wsClient) reportDropped(err error) {
+	if err != nil {
+		c.sendError(err)
+	}
+}
+
 func (c *wsClient) sendError(err error) {
 	select {
 	case c.errorCh <- err:
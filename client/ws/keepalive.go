@@ -0,0 +1,70 @@
+package ws
+
+import (
+	"context"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+// keepaliveLoop pings the server every PingInterval and drops the connection
+// when a pong does not arrive within PongTimeout, e.g. on a half-open TCP connection
+func (c *wsClient) keepaliveLoop(ctx context.Context, drop context.CancelCauseFunc, conn *websocket.Conn, done <-chan struct{}) {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.options.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		pingCtx, cancel := context.WithTimeout(ctx, c.options.PongTimeout)
+		err := conn.Ping(pingCtx)
+		cancel()
+		if err != nil {
+			if ctx.Err() == nil {
+				drop(ErrPongTimeout)
+			}
+			return
+		}
+	}
+}
+
+// watchdogLoop drops the connection when a watched subscription goes quiet for its silence timeout
+func (c *wsClient) watchdogLoop(ctx context.Context, drop context.CancelCauseFunc, done <-chan struct{}) {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.watchdogInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := c.subscriptions.Silent(now, c.options.SilenceTimeouts); err != nil {
+				drop(err)
+				return
+			}
+		}
+	}
+}
+
+// watchdogInterval checks twice per shortest silence timeout
+func (c *wsClient) watchdogInterval() time.Duration {
+	var interval time.Duration
+	for _, timeout := range c.options.SilenceTimeouts {
+		if timeout > 0 && (interval == 0 || timeout < interval) {
+			interval = timeout
+		}
+	}
+	return interval / 2
+}
@@ -0,0 +1,126 @@
+package ws
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+// newDeafServer starts a server that acknowledges connections but never reads,
+// so pings go unanswered as on a half-open connection
+func newDeafServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.CloseNow()
+
+		conn.Write(r.Context(), websocket.MessageText, []byte(`{"type":"connected"}`))
+		select {
+		case <-release:
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(release) })
+	return srv
+}
+
+func TestKeepalive_PongTimeout(t *testing.T) {
+	srv := newDeafServer(t)
+	disconnected := make(chan error, 1)
+	opts := DefaultOptions().
+		WithPingInterval(20 * time.Millisecond).
+		WithPongTimeout(50 * time.Millisecond).
+		WithOnDisconnect(func(err error) {
+			select {
+			case disconnected <- err:
+			default:
+			}
+		})
+	c := NewClient("ws"+strings.TrimPrefix(srv.URL, "http"), opts)
+	runClient(t, c)
+	defer c.Close()
+
+	select {
+	case err := <-disconnected:
+		if !errors.Is(err, ErrPongTimeout) {
+			t.Errorf("expected ErrPongTimeout, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("dead connection was not detected")
+	}
+}
+
+func TestKeepalive_HealthyConnection(t *testing.T) {
+	srv := newFlakyServer(t)
+	opts := supervisorOptions().
+		WithPingInterval(20 * time.Millisecond).
+		WithPongTimeout(50 * time.Millisecond)
+	c := NewClient(srv.url(), opts)
+	runClient(t, c)
+	defer c.Close()
+
+	time.Sleep(200 * time.Millisecond)
+	if c.State() != StateConnected || srv.dialCount() != 1 {
+		t.Errorf("expected the answered pings to keep the connection, got %s after %d dials", c.State(), srv.dialCount())
+	}
+}
+
+func TestWatchdog_SilentSubscription(t *testing.T) {
+	srv := newFlakyServer(t)
+	disconnected := make(chan error, 1)
+	resubscribed := make(chan *ResubscribeResult, 1)
+	opts := supervisorOptions().
+		WithSilenceTimeout(ChannelOrderBook, 100*time.Millisecond).
+		WithOnDisconnect(func(err error) {
+			select {
+			case disconnected <- err:
+			default:
+			}
+		}).
+		WithOnResubscribed(func(r *ResubscribeResult) {
+			select {
+			case resubscribed <- r:
+			default:
+			}
+		})
+	c := NewClient(srv.url(), opts)
+	runClient(t, c)
+	defer c.Close()
+
+	// Trades are not watched, so only the order book can trip the watchdog
+	if err := c.SubscribeTrades(0); err != nil {
+		t.Fatalf("subscribe trades: %v", err)
+	}
+	if err := c.SubscribeOrderBook(0); err != nil {
+		t.Fatalf("subscribe order book: %v", err)
+	}
+
+	select {
+	case err := <-disconnected:
+		var silence *SilenceError
+		if !errors.As(err, &silence) || !errors.Is(err, ErrSubscriptionSilent) || silence.Key != orderBookKey(0) {
+			t.Errorf("expected a SilenceError for the order book, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("silent subscription was not detected")
+	}
+
+	select {
+	case r := <-resubscribed:
+		if len(r.Confirmed) != 2 {
+			t.Errorf("expected both subscriptions restored, got %+v", r)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("client did not reconnect")
+	}
+}
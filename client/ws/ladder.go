@@ -0,0 +1,202 @@
+package ws
+
+// ladderNode is a node of a persistent treap. Nodes are never modified once
+// they are part of a ladder: updates copy the path from the root to the
+// changed node, so older ladders stay valid and can be read concurrently.
+type ladderNode struct {
+	price       Decimal
+	size        Decimal
+	level       OrderBookLevel
+	priority    uint64
+	count       int // Number of nodes in this subtree
+	left, right *ladderNode
+}
+
+func nodeCount(n *ladderNode) int {
+	if n == nil {
+		return 0
+	}
+	return n.count
+}
+
+func (n *ladderNode) withChildren(left, right *ladderNode) *ladderNode {
+	c := *n
+	c.left, c.right = left, right
+	c.count = 1 + nodeCount(left) + nodeCount(right)
+	return &c
+}
+
+// ladder is an immutable side of an order book, sorted from the best price outwards
+type ladder struct {
+	root       *ladderNode
+	descending bool // Bids are sorted by price descending, asks ascending
+}
+
+// before reports whether price a sorts before price b on this side of the book
+func (l ladder) before(a, b Decimal) bool {
+	if l.descending {
+		return a.Cmp(b) > 0
+	}
+	return a.Cmp(b) < 0
+}
+
+// Len returns the number of levels
+func (l ladder) Len() int {
+	return nodeCount(l.root)
+}
+
+// get returns the node at price, or nil
+func (l ladder) get(price Decimal) *ladderNode {
+	n := l.root
+	for n != nil {
+		switch {
+		case price.Equal(n.price):
+			return n
+		case l.before(price, n.price):
+			n = n.left
+		default:
+			n = n.right
+		}
+	}
+	return nil
+}
+
+// best returns the node with the best price, or nil
+func (l ladder) best() *ladderNode {
+	n := l.root
+	if n == nil {
+		return nil
+	}
+	for n.left != nil {
+		n = n.left
+	}
+	return n
+}
+
+// set returns a ladder where the level at the price of p is added, replaced, or removed
+func (l ladder) set(p parsedLevel) ladder {
+	if p.remove {
+		return ladder{root: l.remove(l.root, p.price), descending: l.descending}
+	}
+	node := &ladderNode{
+		price:    p.price,
+		size:     p.size,
+		level:    p.level,
+		priority: pricePriority(p.price),
+		count:    1,
+	}
+	return ladder{root: l.insert(l.root, node), descending: l.descending}
+}
+
+// insert returns a copy of n with node added or replacing the node at the same price.
+// Only nodes on the path to node are copied; the copies are fresh, so they may be rotated in place.
+func (l ladder) insert(n, node *ladderNode) *ladderNode {
+	if n == nil {
+		return node
+	}
+	if node.price.Equal(n.price) {
+		// Equal prices have equal priorities, so the shape does not change
+		return node.withChildren(n.left, n.right)
+	}
+
+	if l.before(node.price, n.price) {
+		c := n.withChildren(l.insert(n.left, node), n.right)
+		if c.left.priority > c.priority {
+			return rotateRight(c)
+		}
+		return c
+	}
+	c := n.withChildren(n.left, l.insert(n.right, node))
+	if c.right.priority > c.priority {
+		return rotateLeft(c)
+	}
+	return c
+}
+
+// remove returns a copy of n without the node at price
+func (l ladder) remove(n *ladderNode, price Decimal) *ladderNode {
+	if n == nil {
+		return nil
+	}
+	switch {
+	case price.Equal(n.price):
+		return merge(n.left, n.right)
+	case l.before(price, n.price):
+		left := l.remove(n.left, price)
+		if left == n.left {
+			return n
+		}
+		return n.withChildren(left, n.right)
+	default:
+		right := l.remove(n.right, price)
+		if right == n.right {
+			return n
+		}
+		return n.withChildren(n.left, right)
+	}
+}
+
+// rotateRight lifts the fresh left child of the fresh node n
+func rotateRight(n *ladderNode) *ladderNode {
+	top := n.left
+	n.left = top.right
+	n.count = 1 + nodeCount(n.left) + nodeCount(n.right)
+	top.right = n
+	top.count = 1 + nodeCount(top.left) + nodeCount(n)
+	return top
+}
+
+// rotateLeft lifts the fresh right child of the fresh node n
+func rotateLeft(n *ladderNode) *ladderNode {
+	top := n.right
+	n.right = top.left
+	n.count = 1 + nodeCount(n.left) + nodeCount(n.right)
+	top.left = n
+	top.count = 1 + nodeCount(n) + nodeCount(top.right)
+	return top
+}
+
+// merge joins two treaps where every node of a sorts before every node of b
+func merge(a, b *ladderNode) *ladderNode {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if a.priority > b.priority {
+		return a.withChildren(a.left, merge(a.right, b))
+	}
+	return b.withChildren(merge(a, b.left), b.right)
+}
+
+// walk calls fn for the first n levels (all if n <= 0) from the best price outwards, until fn returns false
+func (l ladder) walk(n int, fn func(*ladderNode) bool) {
+	if n <= 0 || n > l.Len() {
+		n = l.Len()
+	}
+	stack := make([]*ladderNode, 0, 32)
+	node := l.root
+	for n > 0 && (node != nil || len(stack) > 0) {
+		for node != nil {
+			stack = append(stack, node)
+			node = node.left
+		}
+		node = stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !fn(node) {
+			return
+		}
+		n--
+		node = node.right
+	}
+}
+
+// pricePriority derives a treap priority from the price (splitmix64), so that
+// the shape of a ladder only depends on its prices
+func pricePriority(price Decimal) uint64 {
+	z := uint64(price.coef) ^ uint64(price.scale)<<58 + 0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
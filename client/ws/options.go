@@ -2,26 +2,67 @@ package ws
 
 import (
 	"time"
+
+	"github.com/0xJord4n/lighter-go/auth"
+	"github.com/0xJord4n/lighter-go/nonce"
+	"github.com/0xJord4n/lighter-go/ratelimit"
 )
 
 // Options configures the WebSocket client behavior
 type Options struct {
 	// Connection settings
-	PingInterval         time.Duration // Default: 30s
-	PongTimeout          time.Duration // Default: 10s
+	PingInterval         time.Duration // Default: 30s (0 = no client pings)
+	PongTimeout          time.Duration // Default: 10s, the connection is dropped if a ping is not answered in time
 	ReconnectDelay       time.Duration // Default: 1s
 	MaxReconnectDelay    time.Duration // Default: 30s
 	MaxReconnectAttempts int           // Default: 10 (0 = unlimited)
+	ReconnectJitter      float64       // Default: 0.2, fraction of each reconnect delay that is randomized
+	ResubscribeTimeout   time.Duration // Default: 10s, to confirm the subscriptions restored after a reconnect
+
+	// Silence watchdog (optional). The connection is dropped as dead when an active
+	// subscription of a listed channel type receives no message for its timeout;
+	// Run then reconnects. Default: no channel type is watched.
+	SilenceTimeouts map[ChannelType]time.Duration
 
 	// Channel buffer sizes
 	OrderBookBufferSize   int // Default: 100
 	TradeBufferSize       int // Default: 100
 	MarketStatsBufferSize int // Default: 100
 	HeightBufferSize      int // Default: 10
-	AccountBufferSize     int // Default: 100
+	AccountBufferSize     int // Default: 100, also used by each typed account channel
 	TxResultBufferSize    int // Default: 100
 	ErrorBufferSize       int // Default: 10
 
+	// Backpressure policy per event channel, applied when a consumer falls behind
+	// (default: BackpressureDropNewest). Dropped events are counted in
+	// Client.EventStats and reported on Errors() as a *DroppedEventsError.
+	Backpressure map[EventChannel]BackpressurePolicy
+
+	// Rate limiting (optional). SendTx and SendTxBatch are charged against
+	// RateLimiter, using RateLimitAccountIndex as the account budget.
+	RateLimiter           *ratelimit.Limiter
+	RateLimitAccountIndex int64
+
+	// Nonce manager (optional). SendTxAndWait and SendTxBatchAndWait acknowledge
+	// the nonce of each transaction with its result.
+	NonceManager nonce.Manager
+
+	// Auth token provider (optional). Private subscriptions made without a token
+	// use one from AuthProvider, and every private subscription is renewed with a
+	// fresh token when it is restored after a reconnect.
+	AuthProvider auth.Provider
+
+	// Order book resync (optional). When a gap is detected in a market's order
+	// book stream, deltas are buffered while the book is rebuilt from
+	// OrderBookFetcher, or by resubscribing if it is nil.
+	OrderBookFetcher      OrderBookFetcher
+	OrderBookStaleAfter   time.Duration // Default: 0 (disabled), resync books without updates for this long
+	OrderBookResyncBuffer int           // Default: 1000 deltas buffered during a resync
+
+	// Session recording (optional). Every raw inbound frame is written to Recorder,
+	// to be fed back through a Replayer.
+	Recorder *Recorder
+
 	// Callbacks (optional, for Python-style usage)
 	OnConnect           func()
 	OnDisconnect        func(error)
@@ -31,7 +72,20 @@ type Options struct {
 	OnHeightUpdate      func(*HeightUpdate)
 	OnAccountUpdate     func(*AccountUpdate)
 	OnTxResult          func(*TxResult)
+	OnOrderBookResync   func(*OrderBookResync)
+	OnReconnect         func(attempt int)
+	OnResubscribed      func(*ResubscribeResult)
 	OnError             func(error)
+
+	// Typed account callbacks (optional)
+	OnOrderEvent        func(*OrderEvent)
+	OnPositionEvent     func(*PositionEvent)
+	OnFillEvent         func(*FillEvent)
+	OnBalanceEvent      func(*BalanceEvent)
+	OnAccountTxEvent    func(*AccountTxEvent)
+	OnUserStatsEvent    func(*UserStatsEvent)
+	OnPoolEvent         func(*PoolEvent)
+	OnNotificationEvent func(*NotificationEvent)
 }
 
 // DefaultOptions returns the default WebSocket client options
@@ -42,6 +96,8 @@ func DefaultOptions() *Options {
 		ReconnectDelay:        1 * time.Second,
 		MaxReconnectDelay:     30 * time.Second,
 		MaxReconnectAttempts:  10,
+		ReconnectJitter:       0.2,
+		ResubscribeTimeout:    10 * time.Second,
 		OrderBookBufferSize:   100,
 		TradeBufferSize:       100,
 		MarketStatsBufferSize: 100,
@@ -49,21 +105,32 @@ func DefaultOptions() *Options {
 		AccountBufferSize:     100,
 		TxResultBufferSize:    100,
 		ErrorBufferSize:       10,
+		OrderBookResyncBuffer: 1000,
 	}
 }
 
-// WithPingInterval sets the ping interval
+// WithPingInterval sets how often the client pings the server to check the connection
 func (o *Options) WithPingInterval(d time.Duration) *Options {
 	o.PingInterval = d
 	return o
 }
 
-// WithPongTimeout sets the pong timeout
+// WithPongTimeout sets how long to wait for the pong before dropping the connection
 func (o *Options) WithPongTimeout(d time.Duration) *Options {
 	o.PongTimeout = d
 	return o
 }
 
+// WithSilenceTimeout drops the connection when an active subscription of the given
+// channel type receives no message for d, e.g. an order book of a liquid market
+func (o *Options) WithSilenceTimeout(channelType ChannelType, d time.Duration) *Options {
+	if o.SilenceTimeouts == nil {
+		o.SilenceTimeouts = make(map[ChannelType]time.Duration)
+	}
+	o.SilenceTimeouts[channelType] = d
+	return o
+}
+
 // WithReconnectDelay sets the initial reconnect delay
 func (o *Options) WithReconnectDelay(d time.Duration) *Options {
 	o.ReconnectDelay = d
@@ -82,6 +149,31 @@ func (o *Options) WithMaxReconnectAttempts(n int) *Options {
 	return o
 }
 
+// WithReconnectJitter sets the fraction of each reconnect delay that is randomized
+func (o *Options) WithReconnectJitter(j float64) *Options {
+	o.ReconnectJitter = j
+	return o
+}
+
+// WithResubscribeTimeout sets how long to wait for subscriptions to be confirmed after a reconnect
+func (o *Options) WithResubscribeTimeout(d time.Duration) *Options {
+	o.ResubscribeTimeout = d
+	return o
+}
+
+// WithBackpressure sets the backpressure policy of an event channel
+func (o *Options) WithBackpressure(ch EventChannel, policy BackpressurePolicy) *Options {
+	if o.Backpressure == nil {
+		o.Backpressure = make(map[EventChannel]BackpressurePolicy)
+	}
+	o.Backpressure[ch] = policy
+	return o
+}
+
+func (o *Options) backpressure(ch EventChannel) BackpressurePolicy {
+	return o.Backpressure[ch]
+}
+
 // WithOnConnect sets the connect callback
 func (o *Options) WithOnConnect(fn func()) *Options {
 	o.OnConnect = fn
@@ -124,14 +216,122 @@ func (o *Options) WithOnAccountUpdate(fn func(*AccountUpdate)) *Options {
 	return o
 }
 
+// WithOnOrderEvent sets the decoded order update callback
+func (o *Options) WithOnOrderEvent(fn func(*OrderEvent)) *Options {
+	o.OnOrderEvent = fn
+	return o
+}
+
+// WithOnPositionEvent sets the decoded position update callback
+func (o *Options) WithOnPositionEvent(fn func(*PositionEvent)) *Options {
+	o.OnPositionEvent = fn
+	return o
+}
+
+// WithOnFillEvent sets the decoded account trade callback
+func (o *Options) WithOnFillEvent(fn func(*FillEvent)) *Options {
+	o.OnFillEvent = fn
+	return o
+}
+
+// WithOnBalanceEvent sets the decoded balance update callback
+func (o *Options) WithOnBalanceEvent(fn func(*BalanceEvent)) *Options {
+	o.OnBalanceEvent = fn
+	return o
+}
+
+// WithOnAccountTxEvent sets the decoded account transaction callback
+func (o *Options) WithOnAccountTxEvent(fn func(*AccountTxEvent)) *Options {
+	o.OnAccountTxEvent = fn
+	return o
+}
+
+// WithOnUserStatsEvent sets the decoded user stats callback
+func (o *Options) WithOnUserStatsEvent(fn func(*UserStatsEvent)) *Options {
+	o.OnUserStatsEvent = fn
+	return o
+}
+
+// WithOnPoolEvent sets the pool data and pool info callback
+func (o *Options) WithOnPoolEvent(fn func(*PoolEvent)) *Options {
+	o.OnPoolEvent = fn
+	return o
+}
+
+// WithOnNotificationEvent sets the decoded notification callback
+func (o *Options) WithOnNotificationEvent(fn func(*NotificationEvent)) *Options {
+	o.OnNotificationEvent = fn
+	return o
+}
+
 // WithOnTxResult sets the transaction result callback
 func (o *Options) WithOnTxResult(fn func(*TxResult)) *Options {
 	o.OnTxResult = fn
 	return o
 }
 
+// WithOnReconnect sets the callback run when a lost connection is re-established,
+// before its subscriptions are restored
+func (o *Options) WithOnReconnect(fn func(attempt int)) *Options {
+	o.OnReconnect = fn
+	return o
+}
+
+// WithOnResubscribed sets the callback run once the subscriptions are restored after a reconnect
+func (o *Options) WithOnResubscribed(fn func(*ResubscribeResult)) *Options {
+	o.OnResubscribed = fn
+	return o
+}
+
+// WithOnOrderBookResync sets the order book resync callback
+func (o *Options) WithOnOrderBookResync(fn func(*OrderBookResync)) *Options {
+	o.OnOrderBookResync = fn
+	return o
+}
+
+// WithOrderBookFetcher rebuilds order books from REST snapshots after a gap,
+// e.g. with httpClient.Order()
+func (o *Options) WithOrderBookFetcher(f OrderBookFetcher) *Options {
+	o.OrderBookFetcher = f
+	return o
+}
+
+// WithOrderBookStaleAfter resyncs order books that received no update for d
+func (o *Options) WithOrderBookStaleAfter(d time.Duration) *Options {
+	o.OrderBookStaleAfter = d
+	return o
+}
+
+// WithNonceManager acknowledges the nonces of transactions sent with SendTxAndWait
+// and SendTxBatchAndWait. Use the signer client's manager so both stay in sync.
+func (o *Options) WithNonceManager(m nonce.Manager) *Options {
+	o.NonceManager = m
+	return o
+}
+
+// WithAuthProvider supplies the auth tokens of private subscriptions.
+// Share it with the HTTP client so that both use the same cached token.
+func (o *Options) WithAuthProvider(p auth.Provider) *Options {
+	o.AuthProvider = p
+	return o
+}
+
+// WithRecorder records every raw inbound frame, e.g. to reproduce an order book bug offline
+func (o *Options) WithRecorder(r *Recorder) *Options {
+	o.Recorder = r
+	return o
+}
+
 // WithOnError sets the error callback
 func (o *Options) WithOnError(fn func(error)) *Options {
 	o.OnError = fn
 	return o
 }
+
+// WithRateLimiter throttles SendTx and SendTxBatch, charging them to the given account.
+// Share the limiter with the HTTP client so that both draw from the same budget.
+func (o *Options) WithRateLimiter(l *ratelimit.Limiter, accountIndex int64) *Options {
+	o.RateLimiter = l
+	o.RateLimitAccountIndex = accountIndex
+	return o
+}
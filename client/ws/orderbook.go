@@ -1,283 +1,492 @@
 package ws
 
 import (
-	"sort"
+	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
-// OrderBookState maintains the current state of an order book
-type OrderBookState struct {
-	mu          sync.RWMutex
+// DepthLevel is an order book level with exact decimal values
+type DepthLevel struct {
+	Price      Decimal
+	Size       Decimal
+	Cumulative Decimal // Total size from the top of the book down to this level
+}
+
+// OrderBookView is an immutable version of an order book.
+//
+// Each update of an OrderBookState produces a new view that shares all
+// unchanged levels with the previous one, so taking a view is O(1) and a view
+// can be read from any goroutine without locking. Levels are sorted by exact
+// decimal price from the best price outwards: best bid/ask and depth reads do
+// not scan or sort the book.
+type OrderBookView struct {
 	MarketIndex int16
 	Sequence    int64
-	Bids        map[string]OrderBookLevel // price -> level
-	Asks        map[string]OrderBookLevel // price -> level
 	LastUpdate  time.Time
+	Version     uint64 // Incremented on every update of the OrderBookState
+	bids        ladder
+	asks        ladder
+}
+
+// OrderBookState maintains the current state of an order book.
+// Writers are serialized; readers load the current OrderBookView atomically.
+type OrderBookState struct {
+	mu          sync.Mutex // Serializes updates
+	MarketIndex int16
+	view        atomic.Pointer[OrderBookView]
 }
 
 // NewOrderBookState creates a new order book state
 func NewOrderBookState(marketIndex int16) *OrderBookState {
-	return &OrderBookState{
+	obs := &OrderBookState{MarketIndex: marketIndex}
+	obs.view.Store(&OrderBookView{
 		MarketIndex: marketIndex,
-		Bids:        make(map[string]OrderBookLevel),
-		Asks:        make(map[string]OrderBookLevel),
+		bids:        ladder{descending: true},
+		asks:        ladder{},
+	})
+	return obs
+}
+
+// View returns the current version of the order book
+func (obs *OrderBookState) View() *OrderBookView {
+	return obs.view.Load()
+}
+
+// parsedLevel is a level update whose price and size were validated
+type parsedLevel struct {
+	level  OrderBookLevel
+	price  Decimal
+	size   Decimal
+	remove bool
+}
+
+func parseLevels(levels []OrderBookLevel) ([]parsedLevel, error) {
+	parsed := make([]parsedLevel, len(levels))
+	for i, level := range levels {
+		price, err := ParseDecimal(level.Price)
+		if err != nil {
+			return nil, fmt.Errorf("order book level price: %w", err)
+		}
+		parsed[i] = parsedLevel{level: level, price: price, remove: level.Size == ""}
+		if parsed[i].remove {
+			continue
+		}
+		if parsed[i].size, err = ParseDecimal(level.Size); err != nil {
+			return nil, fmt.Errorf("order book level size: %w", err)
+		}
+		parsed[i].remove = parsed[i].size.IsZero()
 	}
+	return parsed, nil
 }
 
 // ApplySnapshot replaces the entire order book state with a snapshot
 func (obs *OrderBookState) ApplySnapshot(snapshot *OrderBookSnapshot) error {
+	bids, err := parseLevels(snapshot.Bids)
+	if err != nil {
+		return err
+	}
+	asks, err := parseLevels(snapshot.Asks)
+	if err != nil {
+		return err
+	}
+
 	obs.mu.Lock()
 	defer obs.mu.Unlock()
 
-	// Clear existing state
-	obs.Bids = make(map[string]OrderBookLevel)
-	obs.Asks = make(map[string]OrderBookLevel)
-
-	// Apply snapshot
-	for _, level := range snapshot.Bids {
-		obs.Bids[level.Price] = level
-	}
-	for _, level := range snapshot.Asks {
-		obs.Asks[level.Price] = level
+	prev := obs.view.Load()
+	next := &OrderBookView{
+		MarketIndex: obs.MarketIndex,
+		Sequence:    snapshot.Sequence,
+		LastUpdate:  time.Now(),
+		Version:     prev.Version + 1,
+		bids:        ladder{descending: true},
+		asks:        ladder{},
 	}
-
-	obs.Sequence = snapshot.Sequence
-	obs.LastUpdate = time.Now()
+	next.apply(bids, asks)
+	obs.view.Store(next)
 	return nil
 }
 
 // ApplyDelta applies an incremental update to the order book
 func (obs *OrderBookState) ApplyDelta(delta *OrderBookDelta) error {
+	bids, err := parseLevels(delta.BidUpdates)
+	if err != nil {
+		return err
+	}
+	asks, err := parseLevels(delta.AskUpdates)
+	if err != nil {
+		return err
+	}
+
 	obs.mu.Lock()
 	defer obs.mu.Unlock()
 
+	prev := obs.view.Load()
+
 	// Check for sequence gap
-	if delta.Sequence != obs.Sequence+1 && obs.Sequence != 0 {
+	if delta.Sequence != prev.Sequence+1 && prev.Sequence != 0 {
 		return ErrSequenceGap
 	}
 
-	// Apply bid updates
-	for _, level := range delta.BidUpdates {
-		if level.Size == "0" || level.Size == "" {
-			delete(obs.Bids, level.Price)
-		} else {
-			obs.Bids[level.Price] = level
-		}
-	}
-
-	// Apply ask updates
-	for _, level := range delta.AskUpdates {
-		if level.Size == "0" || level.Size == "" {
-			delete(obs.Asks, level.Price)
-		} else {
-			obs.Asks[level.Price] = level
-		}
-	}
-
-	obs.Sequence = delta.Sequence
-	obs.LastUpdate = time.Now()
+	next := prev.next()
+	next.Sequence = delta.Sequence
+	next.apply(bids, asks)
+	obs.view.Store(next)
 	return nil
 }
 
 // MergeUpdates merges bid and ask updates into the order book
 // This is used for updates that don't have sequence numbers
-func (obs *OrderBookState) MergeUpdates(bids, asks []OrderBookLevel) {
+func (obs *OrderBookState) MergeUpdates(bids, asks []OrderBookLevel) error {
+	parsedBids, err := parseLevels(bids)
+	if err != nil {
+		return err
+	}
+	parsedAsks, err := parseLevels(asks)
+	if err != nil {
+		return err
+	}
+
 	obs.mu.Lock()
 	defer obs.mu.Unlock()
 
-	// Apply bid updates
-	for _, level := range bids {
-		if level.Size == "0" || level.Size == "" {
-			delete(obs.Bids, level.Price)
-		} else {
-			obs.Bids[level.Price] = level
-		}
-	}
+	next := obs.view.Load().next()
+	next.apply(parsedBids, parsedAsks)
+	obs.view.Store(next)
+	return nil
+}
 
-	// Apply ask updates
-	for _, level := range asks {
-		if level.Size == "0" || level.Size == "" {
-			delete(obs.Asks, level.Price)
-		} else {
-			obs.Asks[level.Price] = level
-		}
-	}
+// next returns a copy of the view for the following version
+func (v *OrderBookView) next() *OrderBookView {
+	next := *v
+	next.Version++
+	next.LastUpdate = time.Now()
+	return &next
+}
 
-	obs.LastUpdate = time.Now()
+// apply must only be called on a view that was not published yet
+func (v *OrderBookView) apply(bids, asks []parsedLevel) {
+	for _, l := range bids {
+		v.bids = v.bids.set(l)
+	}
+	for _, l := range asks {
+		v.asks = v.asks.set(l)
+	}
 }
 
 // GetBestBid returns the highest bid price level
-func (obs *OrderBookState) GetBestBid() *OrderBookLevel {
-	obs.mu.RLock()
-	defer obs.mu.RUnlock()
+func (v *OrderBookView) GetBestBid() *OrderBookLevel {
+	return bestLevel(v.bids)
+}
 
-	if len(obs.Bids) == 0 {
+// GetBestAsk returns the lowest ask price level
+func (v *OrderBookView) GetBestAsk() *OrderBookLevel {
+	return bestLevel(v.asks)
+}
+
+func bestLevel(l ladder) *OrderBookLevel {
+	n := l.best()
+	if n == nil {
 		return nil
 	}
+	level := n.level
+	return &level
+}
 
-	var bestPrice string
-	for price := range obs.Bids {
-		if bestPrice == "" || comparePrices(price, bestPrice) > 0 {
-			bestPrice = price
-		}
-	}
+// GetBids returns a copy of all bid levels sorted by price descending
+func (v *OrderBookView) GetBids() []OrderBookLevel {
+	return sortedLevels(v.bids)
+}
 
-	if bestPrice == "" {
-		return nil
+// GetAsks returns a copy of all ask levels sorted by price ascending
+func (v *OrderBookView) GetAsks() []OrderBookLevel {
+	return sortedLevels(v.asks)
+}
+
+func sortedLevels(l ladder) []OrderBookLevel {
+	levels := make([]OrderBookLevel, 0, l.Len())
+	l.walk(0, func(n *ladderNode) bool {
+		levels = append(levels, n.level)
+		return true
+	})
+	return levels
+}
+
+// Bid returns the bid level at price, which may use any number of decimal places
+func (v *OrderBookView) Bid(price string) (OrderBookLevel, bool) {
+	return levelAt(v.bids, price)
+}
+
+// Ask returns the ask level at price, which may use any number of decimal places
+func (v *OrderBookView) Ask(price string) (OrderBookLevel, bool) {
+	return levelAt(v.asks, price)
+}
+
+func levelAt(l ladder, price string) (OrderBookLevel, bool) {
+	p, err := ParseDecimal(price)
+	if err != nil {
+		return OrderBookLevel{}, false
+	}
+	n := l.get(p)
+	if n == nil {
+		return OrderBookLevel{}, false
 	}
+	return n.level, true
+}
 
-	level := obs.Bids[bestPrice]
-	return &level
+// BidCount returns the number of bid levels
+func (v *OrderBookView) BidCount() int {
+	return v.bids.Len()
 }
 
-// GetBestAsk returns the lowest ask price level
-func (obs *OrderBookState) GetBestAsk() *OrderBookLevel {
-	obs.mu.RLock()
-	defer obs.mu.RUnlock()
+// AskCount returns the number of ask levels
+func (v *OrderBookView) AskCount() int {
+	return v.asks.Len()
+}
 
-	if len(obs.Asks) == 0 {
-		return nil
+// BestBidPrice returns the highest bid price
+func (v *OrderBookView) BestBidPrice() (Decimal, bool) {
+	if n := v.bids.best(); n != nil {
+		return n.price, true
 	}
+	return Decimal{}, false
+}
 
-	var bestPrice string
-	for price := range obs.Asks {
-		if bestPrice == "" || comparePrices(price, bestPrice) < 0 {
-			bestPrice = price
-		}
+// BestAskPrice returns the lowest ask price
+func (v *OrderBookView) BestAskPrice() (Decimal, bool) {
+	if n := v.asks.best(); n != nil {
+		return n.price, true
 	}
+	return Decimal{}, false
+}
 
-	if bestPrice == "" {
-		return nil
+// Spread returns the best ask minus the best bid.
+// It returns ErrEmptyBookSide if either side of the book is empty.
+func (v *OrderBookView) Spread() (Decimal, error) {
+	bid, ask := v.bids.best(), v.asks.best()
+	if bid == nil || ask == nil {
+		return Decimal{}, ErrEmptyBookSide
 	}
+	return ask.price.Sub(bid.price)
+}
 
-	level := obs.Asks[bestPrice]
-	return &level
+// MidPrice returns the average of the best bid and the best ask.
+// It returns ErrEmptyBookSide if either side of the book is empty.
+func (v *OrderBookView) MidPrice() (Decimal, error) {
+	bid, ask := v.bids.best(), v.asks.best()
+	if bid == nil || ask == nil {
+		return Decimal{}, ErrEmptyBookSide
+	}
+	sum, err := ask.price.Add(bid.price)
+	if err != nil {
+		return Decimal{}, err
+	}
+	return sum.Half()
 }
 
-// GetBids returns a copy of all bid levels sorted by price descending
-func (obs *OrderBookState) GetBids() []OrderBookLevel {
-	obs.mu.RLock()
-	defer obs.mu.RUnlock()
+// BidDepth returns the best n bid levels with their cumulative size, or all levels if n <= 0
+func (v *OrderBookView) BidDepth(n int) ([]DepthLevel, error) {
+	return depth(v.bids, n)
+}
+
+// AskDepth returns the best n ask levels with their cumulative size, or all levels if n <= 0
+func (v *OrderBookView) AskDepth(n int) ([]DepthLevel, error) {
+	return depth(v.asks, n)
+}
+
+// CumulativeBidSize returns the total size of the best n bid levels, or of all levels if n <= 0
+func (v *OrderBookView) CumulativeBidSize(n int) (Decimal, error) {
+	return cumulativeSize(v.bids, n)
+}
+
+// CumulativeAskSize returns the total size of the best n ask levels, or of all levels if n <= 0
+func (v *OrderBookView) CumulativeAskSize(n int) (Decimal, error) {
+	return cumulativeSize(v.asks, n)
+}
 
-	levels := make([]OrderBookLevel, 0, len(obs.Bids))
-	for _, level := range obs.Bids {
-		levels = append(levels, level)
+func depth(l ladder, n int) ([]DepthLevel, error) {
+	if n <= 0 || n > l.Len() {
+		n = l.Len()
 	}
+	levels := make([]DepthLevel, 0, n)
+	var cumulative Decimal
+	var err error
+	l.walk(n, func(node *ladderNode) bool {
+		if cumulative, err = cumulative.Add(node.size); err != nil {
+			return false
+		}
+		levels = append(levels, DepthLevel{Price: node.price, Size: node.size, Cumulative: cumulative})
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return levels, nil
+}
 
-	// Sort by price descending (highest first)
-	sort.Slice(levels, func(i, j int) bool {
-		return comparePrices(levels[i].Price, levels[j].Price) > 0
+func cumulativeSize(l ladder, n int) (Decimal, error) {
+	var total Decimal
+	var err error
+	l.walk(n, func(node *ladderNode) bool {
+		total, err = total.Add(node.size)
+		return err == nil
 	})
+	if err != nil {
+		return Decimal{}, err
+	}
+	return total, nil
+}
 
-	return levels
+// GetSpread returns the bid-ask spread as a decimal string, or "" if either side is empty
+func (v *OrderBookView) GetSpread() (string, error) {
+	spread, err := v.Spread()
+	if err == ErrEmptyBookSide {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return spread.String(), nil
+}
+
+// GetMidPrice returns the mid price as a decimal string, or "" if either side is empty
+func (v *OrderBookView) GetMidPrice() string {
+	mid, err := v.MidPrice()
+	if err != nil {
+		return ""
+	}
+	return mid.String()
+}
+
+// GetBestBid returns the highest bid price level
+func (obs *OrderBookState) GetBestBid() *OrderBookLevel {
+	return obs.View().GetBestBid()
+}
+
+// GetBestAsk returns the lowest ask price level
+func (obs *OrderBookState) GetBestAsk() *OrderBookLevel {
+	return obs.View().GetBestAsk()
+}
+
+// GetBids returns a copy of all bid levels sorted by price descending
+func (obs *OrderBookState) GetBids() []OrderBookLevel {
+	return obs.View().GetBids()
 }
 
 // GetAsks returns a copy of all ask levels sorted by price ascending
 func (obs *OrderBookState) GetAsks() []OrderBookLevel {
-	obs.mu.RLock()
-	defer obs.mu.RUnlock()
+	return obs.View().GetAsks()
+}
 
-	levels := make([]OrderBookLevel, 0, len(obs.Asks))
-	for _, level := range obs.Asks {
-		levels = append(levels, level)
-	}
+// Bid returns the bid level at price
+func (obs *OrderBookState) Bid(price string) (OrderBookLevel, bool) {
+	return obs.View().Bid(price)
+}
 
-	// Sort by price ascending (lowest first)
-	sort.Slice(levels, func(i, j int) bool {
-		return comparePrices(levels[i].Price, levels[j].Price) < 0
-	})
+// Ask returns the ask level at price
+func (obs *OrderBookState) Ask(price string) (OrderBookLevel, bool) {
+	return obs.View().Ask(price)
+}
 
-	return levels
+// Bids returns a copy of the bid levels keyed by price, as sent by Lighter.
+//
+// Deprecated: the Bids field was replaced by sorted levels. Use Bid, GetBids or View instead.
+func (obs *OrderBookState) Bids() map[string]OrderBookLevel {
+	return levelMap(obs.View().bids)
 }
 
-// GetSpread returns the bid-ask spread
-func (obs *OrderBookState) GetSpread() (string, error) {
-	bestBid := obs.GetBestBid()
-	bestAsk := obs.GetBestAsk()
+// Asks returns a copy of the ask levels keyed by price, as sent by Lighter.
+//
+// Deprecated: the Asks field was replaced by sorted levels. Use Ask, GetAsks or View instead.
+func (obs *OrderBookState) Asks() map[string]OrderBookLevel {
+	return levelMap(obs.View().asks)
+}
 
-	if bestBid == nil || bestAsk == nil {
-		return "", nil
-	}
+func levelMap(l ladder) map[string]OrderBookLevel {
+	levels := make(map[string]OrderBookLevel, l.Len())
+	l.walk(0, func(n *ladderNode) bool {
+		levels[n.level.Price] = n.level
+		return true
+	})
+	return levels
+}
 
-	// Simple string-based spread calculation
-	// In a real implementation, you'd use proper decimal arithmetic
-	return subtractPrices(bestAsk.Price, bestBid.Price), nil
+// BidCount returns the number of bid levels
+func (obs *OrderBookState) BidCount() int {
+	return obs.View().BidCount()
 }
 
-// GetMidPrice returns the mid price
-func (obs *OrderBookState) GetMidPrice() string {
-	bestBid := obs.GetBestBid()
-	bestAsk := obs.GetBestAsk()
+// AskCount returns the number of ask levels
+func (obs *OrderBookState) AskCount() int {
+	return obs.View().AskCount()
+}
 
-	if bestBid == nil || bestAsk == nil {
-		return ""
-	}
+// BestBidPrice returns the highest bid price
+func (obs *OrderBookState) BestBidPrice() (Decimal, bool) {
+	return obs.View().BestBidPrice()
+}
 
-	return averagePrices(bestBid.Price, bestAsk.Price)
+// BestAskPrice returns the lowest ask price
+func (obs *OrderBookState) BestAskPrice() (Decimal, bool) {
+	return obs.View().BestAskPrice()
 }
 
-// GetSequence returns the current sequence number
-func (obs *OrderBookState) GetSequence() int64 {
-	obs.mu.RLock()
-	defer obs.mu.RUnlock()
-	return obs.Sequence
+// Spread returns the best ask minus the best bid
+func (obs *OrderBookState) Spread() (Decimal, error) {
+	return obs.View().Spread()
 }
 
-// GetLastUpdate returns the time of the last update
-func (obs *OrderBookState) GetLastUpdate() time.Time {
-	obs.mu.RLock()
-	defer obs.mu.RUnlock()
-	return obs.LastUpdate
+// MidPrice returns the average of the best bid and the best ask
+func (obs *OrderBookState) MidPrice() (Decimal, error) {
+	return obs.View().MidPrice()
 }
 
-// Clone returns a deep copy of the order book state
-func (obs *OrderBookState) Clone() *OrderBookState {
-	obs.mu.RLock()
-	defer obs.mu.RUnlock()
+// BidDepth returns the best n bid levels with their cumulative size
+func (obs *OrderBookState) BidDepth(n int) ([]DepthLevel, error) {
+	return obs.View().BidDepth(n)
+}
 
-	clone := &OrderBookState{
-		MarketIndex: obs.MarketIndex,
-		Sequence:    obs.Sequence,
-		Bids:        make(map[string]OrderBookLevel),
-		Asks:        make(map[string]OrderBookLevel),
-		LastUpdate:  obs.LastUpdate,
-	}
+// AskDepth returns the best n ask levels with their cumulative size
+func (obs *OrderBookState) AskDepth(n int) ([]DepthLevel, error) {
+	return obs.View().AskDepth(n)
+}
 
-	for k, v := range obs.Bids {
-		clone.Bids[k] = v
-	}
-	for k, v := range obs.Asks {
-		clone.Asks[k] = v
-	}
+// CumulativeBidSize returns the total size of the best n bid levels
+func (obs *OrderBookState) CumulativeBidSize(n int) (Decimal, error) {
+	return obs.View().CumulativeBidSize(n)
+}
 
-	return clone
+// CumulativeAskSize returns the total size of the best n ask levels
+func (obs *OrderBookState) CumulativeAskSize(n int) (Decimal, error) {
+	return obs.View().CumulativeAskSize(n)
 }
 
-// Helper functions for price comparison
-// These are simplified implementations - in production use proper decimal math
+// GetSpread returns the bid-ask spread as a decimal string
+func (obs *OrderBookState) GetSpread() (string, error) {
+	return obs.View().GetSpread()
+}
 
-func comparePrices(a, b string) int {
-	// Simple string comparison that works for numeric strings
-	// In production, use a decimal library
-	if len(a) != len(b) {
-		return len(a) - len(b)
-	}
-	if a < b {
-		return -1
-	}
-	if a > b {
-		return 1
-	}
-	return 0
+// GetMidPrice returns the mid price as a decimal string
+func (obs *OrderBookState) GetMidPrice() string {
+	return obs.View().GetMidPrice()
+}
+
+// GetSequence returns the current sequence number
+func (obs *OrderBookState) GetSequence() int64 {
+	return obs.View().Sequence
 }
 
-func subtractPrices(a, b string) string {
-	// Placeholder - in production use decimal math
-	return a + "-" + b
+// GetLastUpdate returns the time of the last update
+func (obs *OrderBookState) GetLastUpdate() time.Time {
+	return obs.View().LastUpdate
 }
 
-func averagePrices(a, b string) string {
-	// Placeholder - in production use decimal math
-	return "(" + a + "+" + b + ")/2"
+// Clone returns an independent copy of the order book state.
+// It shares the current immutable view, so it is O(1).
+func (obs *OrderBookState) Clone() *OrderBookState {
+	clone := &OrderBookState{MarketIndex: obs.MarketIndex}
+	clone.view.Store(obs.View())
+	return clone
 }
@@ -1,6 +1,10 @@
 package ws
 
 import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
 	"sync"
 	"testing"
 )
@@ -12,12 +16,12 @@ func TestNewOrderBookState(t *testing.T) {
 		t.Errorf("expected MarketIndex 0, got %d", obs.MarketIndex)
 	}
 
-	if len(obs.Bids) != 0 {
-		t.Errorf("expected empty Bids, got %d", len(obs.Bids))
+	if len(obs.Bids()) != 0 {
+		t.Errorf("expected empty Bids, got %d", len(obs.Bids()))
 	}
 
-	if len(obs.Asks) != 0 {
-		t.Errorf("expected empty Asks, got %d", len(obs.Asks))
+	if len(obs.Asks()) != 0 {
+		t.Errorf("expected empty Asks, got %d", len(obs.Asks()))
 	}
 }
 
@@ -46,22 +50,22 @@ func TestOrderBookState_ApplySnapshot(t *testing.T) {
 		t.Errorf("expected sequence 100, got %d", obs.GetSequence())
 	}
 
-	if len(obs.Bids) != 2 {
-		t.Errorf("expected 2 bids, got %d", len(obs.Bids))
+	if len(obs.Bids()) != 2 {
+		t.Errorf("expected 2 bids, got %d", len(obs.Bids()))
 	}
 
-	if len(obs.Asks) != 2 {
-		t.Errorf("expected 2 asks, got %d", len(obs.Asks))
+	if len(obs.Asks()) != 2 {
+		t.Errorf("expected 2 asks, got %d", len(obs.Asks()))
 	}
 
 	// Verify bid levels
-	bid, exists := obs.Bids["100"]
+	bid, exists := obs.Bids()["100"]
 	if !exists || bid.Size != "10" {
 		t.Errorf("expected bid at 100 with size 10, got %+v", bid)
 	}
 
 	// Verify ask levels
-	ask, exists := obs.Asks["101"]
+	ask, exists := obs.Asks()["101"]
 	if !exists || ask.Size != "15" {
 		t.Errorf("expected ask at 101 with size 15, got %+v", ask)
 	}
@@ -101,13 +105,13 @@ func TestOrderBookState_ApplySnapshot_ClearsExisting(t *testing.T) {
 	}
 
 	// Old prices should be gone
-	_, exists := obs.Bids["100"]
+	_, exists := obs.Bids()["100"]
 	if exists {
 		t.Error("old bid at 100 should have been removed")
 	}
 
 	// New prices should be present
-	bid, exists := obs.Bids["105"]
+	bid, exists := obs.Bids()["105"]
 	if !exists || bid.Size != "5" {
 		t.Errorf("expected bid at 105 with size 5, got %+v", bid)
 	}
@@ -154,19 +158,19 @@ func TestOrderBookState_ApplyDelta(t *testing.T) {
 	}
 
 	// Check updated bid
-	bid, exists := obs.Bids["100"]
+	bid, exists := obs.Bids()["100"]
 	if !exists || bid.Size != "20" {
 		t.Errorf("expected bid at 100 with size 20, got %+v", bid)
 	}
 
 	// Check new bid
-	bid, exists = obs.Bids["99"]
+	bid, exists = obs.Bids()["99"]
 	if !exists || bid.Size != "30" {
 		t.Errorf("expected bid at 99 with size 30, got %+v", bid)
 	}
 
 	// Check removed ask
-	_, exists = obs.Asks["101"]
+	_, exists = obs.Asks()["101"]
 	if exists {
 		t.Error("ask at 101 should have been removed")
 	}
@@ -231,7 +235,7 @@ func TestOrderBookState_ApplyDelta_EmptySize(t *testing.T) {
 		t.Fatalf("Failed to apply delta: %v", err)
 	}
 
-	_, exists := obs.Bids["99"]
+	_, exists := obs.Bids()["99"]
 	if exists {
 		t.Error("bid at 99 should have been removed with empty size")
 	}
@@ -268,23 +272,23 @@ func TestOrderBookState_MergeUpdates(t *testing.T) {
 	obs.MergeUpdates(bids, asks)
 
 	// Check bids
-	bid, exists := obs.Bids["100"]
+	bid, exists := obs.Bids()["100"]
 	if !exists || bid.Size != "25" {
 		t.Errorf("expected bid at 100 with size 25, got %+v", bid)
 	}
 
-	bid, exists = obs.Bids["98"]
+	bid, exists = obs.Bids()["98"]
 	if !exists || bid.Size != "50" {
 		t.Errorf("expected bid at 98 with size 50, got %+v", bid)
 	}
 
 	// Check asks
-	_, exists = obs.Asks["101"]
+	_, exists = obs.Asks()["101"]
 	if exists {
 		t.Error("ask at 101 should have been removed")
 	}
 
-	ask, exists := obs.Asks["103"]
+	ask, exists := obs.Asks()["103"]
 	if !exists || ask.Size != "40" {
 		t.Errorf("expected ask at 103 with size 40, got %+v", ask)
 	}
@@ -449,7 +453,7 @@ func TestOrderBookState_Clone(t *testing.T) {
 	obs.MergeUpdates([]OrderBookLevel{{Price: "100", Size: "999"}}, nil)
 
 	// Clone should be unaffected
-	bid := clone.Bids["100"]
+	bid := clone.Bids()["100"]
 	if bid.Size != "10" {
 		t.Errorf("clone should not be affected by original changes, got size %s", bid.Size)
 	}
@@ -512,7 +516,7 @@ func TestOrderBookState_ConcurrentAccess(t *testing.T) {
 	wg.Wait()
 }
 
-func TestComparePrices(t *testing.T) {
+func TestDecimal_Cmp(t *testing.T) {
 	tests := []struct {
 		a, b     string
 		expected int
@@ -520,21 +524,310 @@ func TestComparePrices(t *testing.T) {
 		{"100", "100", 0},
 		{"100", "99", 1},
 		{"99", "100", -1},
-		{"1000", "100", 1},  // Longer string wins
-		{"100", "1000", -1}, // Shorter string loses
+		{"1000", "100", 1},
+		{"100", "1000", -1},
+		{"9.5", "10.25", -1}, // Shorter string, but smaller value
+		{"10.25", "9.5", 1},
+		{"100.00", "100", 0},
+		{"0.1", "0.09", 1},
+		{"-1.5", "-1.25", -1},
+		{"92233720368.54775807", "92233720368.547758", 1}, // Aligned coefficients overflow int64
 	}
 
 	for _, tt := range tests {
-		result := comparePrices(tt.a, tt.b)
-		// Normalize to -1, 0, 1
-		if result < 0 {
-			result = -1
-		} else if result > 0 {
-			result = 1
+		result := MustParseDecimal(tt.a).Cmp(MustParseDecimal(tt.b))
+		if result != tt.expected {
+			t.Errorf("Cmp(%s, %s) = %d, expected %d", tt.a, tt.b, result, tt.expected)
 		}
+	}
+}
 
-		if result != tt.expected {
-			t.Errorf("comparePrices(%s, %s) = %d, expected %d", tt.a, tt.b, result, tt.expected)
+func TestDecimal_ParseAndString(t *testing.T) {
+	tests := []struct {
+		in, out string
+	}{
+		{"100", "100"},
+		{"100.00", "100"},
+		{"9.50", "9.5"},
+		{"0.001", "0.001"},
+		{".5", "0.5"},
+		{"-0.25", "-0.25"},
+		{"+3", "3"},
+	}
+	for _, tt := range tests {
+		d, err := ParseDecimal(tt.in)
+		if err != nil {
+			t.Fatalf("ParseDecimal(%q) failed: %v", tt.in, err)
+		}
+		if d.String() != tt.out {
+			t.Errorf("ParseDecimal(%q) = %s, expected %s", tt.in, d, tt.out)
+		}
+	}
+
+	for _, in := range []string{"", ".", "-", "1.2.3", "1e5", "abc", "99999999999999999999"} {
+		if _, err := ParseDecimal(in); err == nil {
+			t.Errorf("ParseDecimal(%q) should fail", in)
+		}
+	}
+}
+
+func TestDecimal_Arithmetic(t *testing.T) {
+	sum, err := MustParseDecimal("9.5").Add(MustParseDecimal("10.25"))
+	if err != nil || sum.String() != "19.75" {
+		t.Errorf("expected 19.75, got %s (%v)", sum, err)
+	}
+
+	diff, err := MustParseDecimal("9.5").Sub(MustParseDecimal("10.25"))
+	if err != nil || diff.String() != "-0.75" {
+		t.Errorf("expected -0.75, got %s (%v)", diff, err)
+	}
+
+	half, err := MustParseDecimal("19.75").Half()
+	if err != nil || half.String() != "9.875" {
+		t.Errorf("expected 9.875, got %s (%v)", half, err)
+	}
+
+	if _, err := MustParseDecimal("9223372036854775807").Add(MustParseDecimal("1")); err != ErrDecimalOverflow {
+		t.Errorf("expected ErrDecimalOverflow, got %v", err)
+	}
+}
+
+func TestOrderBookState_MixedDecimalPlaces(t *testing.T) {
+	obs := NewOrderBookState(0)
+
+	snapshot := &OrderBookSnapshot{
+		Bids: []OrderBookLevel{
+			{Price: "9.5", Size: "1"},
+			{Price: "10.25", Size: "2.5"},
+			{Price: "8.125", Size: "3"},
+		},
+		Asks: []OrderBookLevel{
+			{Price: "11", Size: "4"},
+			{Price: "10.5", Size: "0.5"},
+		},
+	}
+	if err := obs.ApplySnapshot(snapshot); err != nil {
+		t.Fatalf("Failed to apply snapshot: %v", err)
+	}
+
+	if best := obs.GetBestBid(); best == nil || best.Price != "10.25" {
+		t.Errorf("expected best bid 10.25, got %+v", best)
+	}
+	if best := obs.GetBestAsk(); best == nil || best.Price != "10.5" {
+		t.Errorf("expected best ask 10.5, got %+v", best)
+	}
+
+	expectedBids := []string{"10.25", "9.5", "8.125"}
+	for i, bid := range obs.GetBids() {
+		if bid.Price != expectedBids[i] {
+			t.Errorf("bid[%d]: expected price %s, got %s", i, expectedBids[i], bid.Price)
+		}
+	}
+
+	// A level sent with more decimal places updates the same level
+	if err := obs.MergeUpdates([]OrderBookLevel{{Price: "9.50", Size: "0.000"}}, nil); err != nil {
+		t.Fatalf("MergeUpdates failed: %v", err)
+	}
+	if len(obs.GetBids()) != 2 {
+		t.Errorf("expected bid at 9.5 to be removed, got %+v", obs.GetBids())
+	}
+}
+
+func TestOrderBookState_SpreadAndMid(t *testing.T) {
+	obs := NewOrderBookState(0)
+
+	if _, err := obs.Spread(); err != ErrEmptyBookSide {
+		t.Errorf("expected ErrEmptyBookSide, got %v", err)
+	}
+	if spread, err := obs.GetSpread(); spread != "" || err != nil {
+		t.Errorf("expected empty spread, got %q (%v)", spread, err)
+	}
+
+	snapshot := &OrderBookSnapshot{
+		Bids: []OrderBookLevel{{Price: "9.5", Size: "1"}},
+		Asks: []OrderBookLevel{{Price: "10.25", Size: "1"}},
+	}
+	if err := obs.ApplySnapshot(snapshot); err != nil {
+		t.Fatalf("Failed to apply snapshot: %v", err)
+	}
+
+	if spread, err := obs.GetSpread(); spread != "0.75" || err != nil {
+		t.Errorf("expected spread 0.75, got %q (%v)", spread, err)
+	}
+	if mid := obs.GetMidPrice(); mid != "9.875" {
+		t.Errorf("expected mid 9.875, got %q", mid)
+	}
+}
+
+func TestOrderBookState_Depth(t *testing.T) {
+	obs := NewOrderBookState(0)
+
+	snapshot := &OrderBookSnapshot{
+		Asks: []OrderBookLevel{
+			{Price: "101", Size: "1.5"},
+			{Price: "100.5", Size: "0.25"},
+			{Price: "102", Size: "3"},
+		},
+	}
+	if err := obs.ApplySnapshot(snapshot); err != nil {
+		t.Fatalf("Failed to apply snapshot: %v", err)
+	}
+
+	levels, err := obs.AskDepth(2)
+	if err != nil {
+		t.Fatalf("AskDepth failed: %v", err)
+	}
+	if len(levels) != 2 {
+		t.Fatalf("expected 2 levels, got %d", len(levels))
+	}
+	if levels[0].Price.String() != "100.5" || levels[1].Cumulative.String() != "1.75" {
+		t.Errorf("unexpected depth: %+v", levels)
+	}
+
+	total, err := obs.CumulativeAskSize(0)
+	if err != nil || total.String() != "4.75" {
+		t.Errorf("expected cumulative size 4.75, got %s (%v)", total, err)
+	}
+	if total, _ := obs.CumulativeBidSize(5); !total.IsZero() {
+		t.Errorf("expected empty bid side to have zero size, got %s", total)
+	}
+}
+
+func TestOrderBookState_InvalidLevel(t *testing.T) {
+	obs := NewOrderBookState(0)
+
+	err := obs.MergeUpdates([]OrderBookLevel{{Price: "100", Size: "1"}, {Price: "abc", Size: "1"}}, nil)
+	if !errors.Is(err, ErrInvalidDecimal) {
+		t.Errorf("expected ErrInvalidDecimal, got %v", err)
+	}
+	if obs.BidCount() != 0 {
+		t.Error("invalid update should not be partially applied")
+	}
+}
+
+func TestOrderBookState_ViewIsImmutable(t *testing.T) {
+	obs := NewOrderBookState(0)
+	if err := obs.MergeUpdates([]OrderBookLevel{{Price: "100", Size: "10"}}, nil); err != nil {
+		t.Fatalf("MergeUpdates failed: %v", err)
+	}
+
+	view := obs.View()
+	if err := obs.MergeUpdates([]OrderBookLevel{{Price: "100", Size: "0"}, {Price: "101", Size: "5"}}, nil); err != nil {
+		t.Fatalf("MergeUpdates failed: %v", err)
+	}
+
+	if best := view.GetBestBid(); best == nil || best.Price != "100" || best.Size != "10" {
+		t.Errorf("old view should be unaffected, got %+v", best)
+	}
+	if best := obs.GetBestBid(); best == nil || best.Price != "101" {
+		t.Errorf("expected best bid 101, got %+v", best)
+	}
+	if obs.View().Version != view.Version+1 {
+		t.Errorf("expected version %d, got %d", view.Version+1, obs.View().Version)
+	}
+}
+
+func TestOrderBookState_RandomUpdatesStaySorted(t *testing.T) {
+	obs := NewOrderBookState(0)
+	rng := rand.New(rand.NewSource(1))
+	reference := make(map[int]int)
+
+	for i := 0; i < 5000; i++ {
+		tick := rng.Intn(500)
+		size := rng.Intn(4) // 0 removes the level
+		price := fmt.Sprintf("%d.%02d", tick/100, tick%100)
+		if err := obs.MergeUpdates([]OrderBookLevel{{Price: price, Size: strconv.Itoa(size)}}, nil); err != nil {
+			t.Fatalf("MergeUpdates failed: %v", err)
+		}
+		if size == 0 {
+			delete(reference, tick)
+		} else {
+			reference[tick] = size
 		}
 	}
+
+	bids := obs.GetBids()
+	if len(bids) != len(reference) {
+		t.Fatalf("expected %d levels, got %d", len(reference), len(bids))
+	}
+	for i := 1; i < len(bids); i++ {
+		if MustParseDecimal(bids[i-1].Price).Cmp(MustParseDecimal(bids[i].Price)) <= 0 {
+			t.Fatalf("bids not sorted descending at %d: %s, %s", i, bids[i-1].Price, bids[i].Price)
+		}
+	}
+	for _, bid := range bids {
+		p := MustParseDecimal(bid.Price)
+		tick := int(p.Coefficient())
+		for s := p.Scale(); s < 2; s++ {
+			tick *= 10
+		}
+		if strconv.Itoa(reference[tick]) != bid.Size {
+			t.Errorf("level %s: expected size %d, got %s", bid.Price, reference[tick], bid.Size)
+		}
+	}
+}
+
+// newDeepOrderBook returns a book with levels on each side
+func newDeepOrderBook(b *testing.B, levels int) *OrderBookState {
+	b.Helper()
+	snapshot := &OrderBookSnapshot{}
+	for i := 0; i < levels; i++ {
+		snapshot.Bids = append(snapshot.Bids, OrderBookLevel{Price: fmt.Sprintf("%d.%02d", 1000-i/100, i%100), Size: "1.5"})
+		snapshot.Asks = append(snapshot.Asks, OrderBookLevel{Price: fmt.Sprintf("%d.%02d", 1001+i/100, i%100), Size: "2.5"})
+	}
+	obs := NewOrderBookState(0)
+	if err := obs.ApplySnapshot(snapshot); err != nil {
+		b.Fatalf("Failed to apply snapshot: %v", err)
+	}
+	return obs
+}
+
+func BenchmarkOrderBookState_MergeUpdates(b *testing.B) {
+	obs := newDeepOrderBook(b, 5000)
+	updates := make([][]OrderBookLevel, 1024)
+	rng := rand.New(rand.NewSource(1))
+	for i := range updates {
+		tick := rng.Intn(5000)
+		updates[i] = []OrderBookLevel{{Price: fmt.Sprintf("%d.%02d", 1000-tick/100, tick%100), Size: strconv.Itoa(rng.Intn(3))}}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := obs.MergeUpdates(updates[i%len(updates)], nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkOrderBookState_GetBestBid(b *testing.B) {
+	obs := newDeepOrderBook(b, 5000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = obs.GetBestBid()
+	}
+}
+
+func BenchmarkOrderBookState_BidDepth10(b *testing.B) {
+	obs := newDeepOrderBook(b, 5000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := obs.BidDepth(10); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkOrderBookState_Clone(b *testing.B) {
+	obs := newDeepOrderBook(b, 5000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = obs.Clone()
+	}
 }
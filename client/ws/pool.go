@@ -0,0 +1,753 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/0xJord4n/lighter-go/types/txtypes"
+)
+
+// ShardPolicy decides which connection of a Pool carries a subscription
+type ShardPolicy int
+
+const (
+	// ShardByMarket keeps the subscriptions of a market on one connection;
+	// account-wide subscriptions are spread by account
+	ShardByMarket ShardPolicy = iota
+	// ShardByAccount keeps the subscriptions of an account on one connection;
+	// public subscriptions are spread by market
+	ShardByAccount
+	// ShardRoundRobin hands each new subscription to the next connection
+	ShardRoundRobin
+)
+
+// String returns the name of the policy
+func (p ShardPolicy) String() string {
+	switch p {
+	case ShardByMarket:
+		return "by-market"
+	case ShardByAccount:
+		return "by-account"
+	case ShardRoundRobin:
+		return "round-robin"
+	}
+	return fmt.Sprintf("ShardPolicy(%d)", int(p))
+}
+
+// PoolOptions configures how a Pool spreads subscriptions over its connections
+type PoolOptions struct {
+	Connections                   int           // Default: 4
+	Policy                        ShardPolicy   // Default: ShardByMarket
+	MaxSubscriptionsPerConnection int           // Default: 0 (unlimited)
+	RebalanceAfter                time.Duration // Default: 10s, how long a connection may be down before its subscriptions move
+
+	// OnRebalance is called after the subscriptions of a lost connection were moved
+	OnRebalance func(*RebalanceResult)
+}
+
+// DefaultPoolOptions returns the default pool options
+func DefaultPoolOptions() *PoolOptions {
+	return &PoolOptions{
+		Connections:    4,
+		Policy:         ShardByMarket,
+		RebalanceAfter: 10 * time.Second,
+	}
+}
+
+// WithConnections sets the number of connections
+func (o *PoolOptions) WithConnections(n int) *PoolOptions {
+	o.Connections = n
+	return o
+}
+
+// WithPolicy sets the sharding policy
+func (o *PoolOptions) WithPolicy(p ShardPolicy) *PoolOptions {
+	o.Policy = p
+	return o
+}
+
+// WithMaxSubscriptionsPerConnection caps the subscriptions of each connection,
+// e.g. to stay below the server's per-connection limit
+func (o *PoolOptions) WithMaxSubscriptionsPerConnection(n int) *PoolOptions {
+	o.MaxSubscriptionsPerConnection = n
+	return o
+}
+
+// WithRebalanceAfter sets how long a connection may be down before its subscriptions move
+func (o *PoolOptions) WithRebalanceAfter(d time.Duration) *PoolOptions {
+	o.RebalanceAfter = d
+	return o
+}
+
+// WithOnRebalance sets the rebalance callback
+func (o *PoolOptions) WithOnRebalance(fn func(*RebalanceResult)) *PoolOptions {
+	o.OnRebalance = fn
+	return o
+}
+
+// RebalanceResult reports the subscriptions moved off a lost connection
+type RebalanceResult struct {
+	From   int              // Connection that was lost
+	Moved  map[string]int   // Subscription keys and the connections they moved to
+	Failed map[string]error // Subscription keys that could not be moved; they are dropped
+}
+
+// poolSubscription is a subscription of a Pool and the connection carrying it
+type poolSubscription struct {
+	member    int
+	market    int64 // -1 if the channel has no market
+	account   int64 // -1 if the channel has no account
+	authToken string
+	subscribe func(c *wsClient, authToken string) error
+}
+
+// Pool spreads subscriptions over several connections, to stay below the server's
+// per-connection subscription limits and to read the streams in parallel. It implements
+// Client: events of all connections are merged into one set of event channels, and
+// transactions are sent on the first live connection.
+//
+// Run supervises every connection. When one stays down for RebalanceAfter, its
+// subscriptions are moved to the live connections; it keeps reconnecting and takes
+// new subscriptions once it is back.
+type Pool struct {
+	*clientEvents
+
+	options     *Options
+	poolOptions *PoolOptions
+	members     []*wsClient
+
+	mu       sync.Mutex
+	assigned map[string]*poolSubscription
+	counts   []int
+	next     int // Round robin cursor
+
+	closed    atomic.Bool
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewPool creates a pool of connections to endpoint. The options are shared by all
+// connections, so connection callbacks such as OnConnect are called for each of them.
+func NewPool(endpoint string, options *Options, poolOptions *PoolOptions) *Pool {
+	if options == nil {
+		options = DefaultOptions()
+	}
+	if poolOptions == nil {
+		poolOptions = DefaultPoolOptions()
+	}
+	n := poolOptions.Connections
+	if n < 1 {
+		n = 1
+	}
+
+	p := &Pool{
+		clientEvents: newClientEvents(options),
+		options:      options,
+		poolOptions:  poolOptions,
+		members:      make([]*wsClient, n),
+		assigned:     make(map[string]*poolSubscription),
+		counts:       make([]int, n),
+		done:         make(chan struct{}),
+	}
+	for i := range p.members {
+		p.members[i] = newClient(endpoint, options, p.clientEvents)
+	}
+	return p
+}
+
+// Connect establishes every connection; if one fails, the others are closed
+func (p *Pool) Connect(ctx context.Context) error {
+	for i, m := range p.members {
+		if err := m.Connect(ctx); err != nil {
+			p.Close() //nolint:errcheck // Returning the connect error
+			return fmt.Errorf("pool connection %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Close closes every connection
+func (p *Pool) Close() error {
+	p.closed.Store(true)
+	p.closeOnce.Do(func() { close(p.done) })
+
+	var errs []error
+	for _, m := range p.members {
+		if err := m.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	p.mu.Lock()
+	p.assigned = make(map[string]*poolSubscription)
+	p.counts = make([]int, len(p.members))
+	p.mu.Unlock()
+
+	return errors.Join(errs...)
+}
+
+// Run connects and blocks until the context is cancelled or the pool is closed,
+// reconnecting lost connections and rebalancing their subscriptions
+func (p *Pool) Run(ctx context.Context) error {
+	if err := p.Connect(ctx); err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	for i, m := range p.members {
+		wg.Add(1)
+		go func(i int, m *wsClient) {
+			defer wg.Done()
+			p.superviseMember(ctx, i, m)
+		}(i, m)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		p.rebalanceLoop(ctx)
+	}()
+	wg.Wait()
+
+	return p.Close()
+}
+
+// superviseMember keeps a connection up until the pool is closed. Unlike a single
+// client, a connection that exhausts its reconnect attempts keeps retrying, since
+// its subscriptions have been moved to the other connections by then.
+func (p *Pool) superviseMember(ctx context.Context, i int, m *wsClient) {
+	err := m.supervise(ctx)
+	for err != nil && m.State() != StateClosed {
+		m.sendError(fmt.Errorf("pool connection %d: %w", i, err))
+		if err = m.reconnect(ctx); err == nil {
+			err = m.supervise(ctx)
+		}
+	}
+}
+
+// rebalanceLoop moves the subscriptions of connections that stay down for RebalanceAfter
+func (p *Pool) rebalanceLoop(ctx context.Context) {
+	after := p.poolOptions.RebalanceAfter
+	if after <= 0 {
+		return
+	}
+	ticker := time.NewTicker(after / 2)
+	defer ticker.Stop()
+
+	down := make([]time.Time, len(p.members))
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.done:
+			return
+		case now := <-ticker.C:
+			for i, m := range p.members {
+				switch {
+				case m.IsConnected():
+					down[i] = time.Time{}
+				case down[i].IsZero():
+					down[i] = now
+				case now.Sub(down[i]) >= after:
+					p.rebalance(i)
+				}
+			}
+		}
+	}
+}
+
+// rebalance moves the subscriptions of connection i to the live connections
+func (p *Pool) rebalance(i int) {
+	p.mu.Lock()
+	var keys []string
+	for key, sub := range p.assigned {
+		if sub.member == i {
+			keys = append(keys, key)
+		}
+	}
+	p.mu.Unlock()
+	if len(keys) == 0 {
+		return
+	}
+
+	old := p.members[i]
+	result := &RebalanceResult{From: i, Moved: make(map[string]int), Failed: make(map[string]error)}
+	for _, key := range keys {
+		p.mu.Lock()
+		sub, ok := p.assigned[key]
+		if !ok || sub.member != i {
+			p.mu.Unlock()
+			continue
+		}
+		j, err := p.pickLocked(sub.market, sub.account, i)
+		if err != nil {
+			// Nowhere to move to; the subscription waits for its connection
+			p.mu.Unlock()
+			break
+		}
+		sub.member = j
+		p.counts[i]--
+		p.counts[j]++
+		p.mu.Unlock()
+
+		// Keep the lost connection from restoring the subscription
+		var channel string
+		if s, ok := old.subscriptions.GetSubscription(key); ok {
+			channel = s.channel
+		}
+		old.subscriptions.Remove(key) //nolint:errcheck // May already be gone
+
+		if err := sub.subscribe(p.members[j], p.renewAuthToken(sub)); err != nil {
+			p.release(key)
+			result.Failed[key] = err
+			p.members[j].sendError(fmt.Errorf("rebalance %s: %w", key, err))
+			continue
+		}
+		result.Moved[key] = j
+
+		// The lost connection may have come back in the meantime
+		if channel != "" && old.IsConnected() {
+			old.unsubscribe(channel) //nolint:errcheck // Best effort
+		}
+	}
+
+	if len(result.Moved)+len(result.Failed) > 0 && p.poolOptions.OnRebalance != nil {
+		p.poolOptions.OnRebalance(result)
+	}
+}
+
+// renewAuthToken returns the token to move a subscription with; an empty token
+// makes the connection fetch a fresh one from the auth provider
+func (p *Pool) renewAuthToken(sub *poolSubscription) string {
+	if p.options.AuthProvider != nil {
+		return ""
+	}
+	return sub.authToken
+}
+
+// pickLocked returns the live connection for a subscription under the shard policy,
+// skipping the excluded connection and those at their subscription limit
+func (p *Pool) pickLocked(market, account int64, exclude int) (int, error) {
+	n := len(p.members)
+	var start int
+	switch p.poolOptions.Policy {
+	case ShardByAccount:
+		start = shardOf(account, market, n)
+	case ShardRoundRobin:
+		start = p.next % n
+		p.next++
+	default:
+		start = shardOf(market, account, n)
+	}
+
+	err := ErrNotConnected
+	for k := 0; k < n; k++ {
+		i := (start + k) % n
+		if i == exclude || !p.members[i].IsConnected() {
+			continue
+		}
+		if max := p.poolOptions.MaxSubscriptionsPerConnection; max > 0 && p.counts[i] >= max {
+			err = ErrPoolFull
+			continue
+		}
+		return i, nil
+	}
+	return -1, err
+}
+
+// shardOf maps a subscription to a connection by its primary index, or by its secondary
+// index if it has no primary one; subscriptions with neither go to the first connection
+func shardOf(primary, secondary int64, n int) int {
+	switch {
+	case primary >= 0:
+		return int(primary % int64(n))
+	case secondary >= 0:
+		return int(secondary % int64(n))
+	}
+	return 0
+}
+
+// add subscribes on the connection picked for the subscription
+func (p *Pool) add(key string, market, account int64, authToken string, subscribe func(c *wsClient, authToken string) error) error {
+	p.mu.Lock()
+	if _, exists := p.assigned[key]; exists {
+		p.mu.Unlock()
+		return ErrAlreadySubscribed
+	}
+	i, err := p.pickLocked(market, account, -1)
+	if err != nil {
+		p.mu.Unlock()
+		return err
+	}
+	sub := &poolSubscription{member: i, market: market, account: account, authToken: authToken, subscribe: subscribe}
+	p.assigned[key] = sub
+	p.counts[i]++
+	p.mu.Unlock()
+
+	if err := subscribe(p.members[i], authToken); err != nil {
+		p.release(key)
+		return err
+	}
+	return nil
+}
+
+// remove unsubscribes on the connection carrying the subscription.
+// The subscription of a lost connection is dropped without contacting the server.
+func (p *Pool) remove(key string, unsubscribe func(c *wsClient) error) error {
+	m, ok := p.memberOf(key)
+	if !ok {
+		return ErrNotSubscribed
+	}
+	if !m.IsConnected() {
+		m.subscriptions.Remove(key) //nolint:errcheck // May already be gone
+	} else if err := unsubscribe(m); err != nil {
+		return err
+	}
+	p.release(key)
+	return nil
+}
+
+func (p *Pool) release(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if sub, ok := p.assigned[key]; ok {
+		p.counts[sub.member]--
+		delete(p.assigned, key)
+	}
+}
+
+func (p *Pool) memberOf(key string) (*wsClient, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	sub, ok := p.assigned[key]
+	if !ok {
+		return nil, false
+	}
+	return p.members[sub.member], true
+}
+
+// txMember returns the connection transactions are sent on
+func (p *Pool) txMember() (*wsClient, error) {
+	for _, m := range p.members {
+		if m.IsConnected() {
+			return m, nil
+		}
+	}
+	return nil, ErrNotConnected
+}
+
+// SubscriptionCounts returns the number of subscriptions carried by each connection
+func (p *Pool) SubscriptionCounts() []int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]int(nil), p.counts...)
+}
+
+// ConnectionStates returns the state of each connection
+func (p *Pool) ConnectionStates() []ConnectionState {
+	states := make([]ConnectionState, len(p.members))
+	for i, m := range p.members {
+		states[i] = m.State()
+	}
+	return states
+}
+
+// IsConnected reports whether every connection is up
+func (p *Pool) IsConnected() bool {
+	for _, m := range p.members {
+		if !m.IsConnected() {
+			return false
+		}
+	}
+	return true
+}
+
+// State returns StateClosed once the pool is closed, and otherwise the least
+// healthy state of its connections
+func (p *Pool) State() ConnectionState {
+	if p.closed.Load() {
+		return StateClosed
+	}
+	rank := map[ConnectionState]int{StateConnected: 0, StateResubscribing: 1, StateConnecting: 2, StateDisconnected: 3, StateClosed: 4}
+	worst := StateConnected
+	for _, m := range p.members {
+		if s := m.State(); rank[s] > rank[worst] {
+			worst = s
+		}
+	}
+	return worst
+}
+
+// GetOrderBookState returns a copy of the order book state kept by the connection carrying the market
+func (p *Pool) GetOrderBookState(marketIndex int16) (*OrderBookState, error) {
+	m, ok := p.memberOf(orderBookKey(marketIndex))
+	if !ok {
+		return nil, ErrOrderBookNotFound
+	}
+	return m.GetOrderBookState(marketIndex)
+}
+
+// SubscribeOrderBook subscribes to order book updates for a market
+func (p *Pool) SubscribeOrderBook(marketIndex int16) error {
+	return p.add(orderBookKey(marketIndex), int64(marketIndex), -1, "", func(c *wsClient, _ string) error {
+		return c.SubscribeOrderBook(marketIndex)
+	})
+}
+
+// UnsubscribeOrderBook unsubscribes from order book updates
+func (p *Pool) UnsubscribeOrderBook(marketIndex int16) error {
+	return p.remove(orderBookKey(marketIndex), func(c *wsClient) error {
+		return c.UnsubscribeOrderBook(marketIndex)
+	})
+}
+
+// SubscribeTrades subscribes to trade updates for a market
+func (p *Pool) SubscribeTrades(marketIndex int16) error {
+	return p.add(tradeKey(marketIndex), int64(marketIndex), -1, "", func(c *wsClient, _ string) error {
+		return c.SubscribeTrades(marketIndex)
+	})
+}
+
+// UnsubscribeTrades unsubscribes from trade updates
+func (p *Pool) UnsubscribeTrades(marketIndex int16) error {
+	return p.remove(tradeKey(marketIndex), func(c *wsClient) error {
+		return c.UnsubscribeTrades(marketIndex)
+	})
+}
+
+// SubscribeMarketStats subscribes to market stats for a market
+func (p *Pool) SubscribeMarketStats(marketIndex int16) error {
+	return p.add(marketStatsKey(marketIndex), int64(marketIndex), -1, "", func(c *wsClient, _ string) error {
+		return c.SubscribeMarketStats(marketIndex)
+	})
+}
+
+// SubscribeAllMarketStats subscribes to market stats for all markets
+func (p *Pool) SubscribeAllMarketStats() error {
+	return p.add(marketStatsAllKey(), -1, -1, "", func(c *wsClient, _ string) error {
+		return c.SubscribeAllMarketStats()
+	})
+}
+
+// UnsubscribeMarketStats unsubscribes from market stats for a market
+func (p *Pool) UnsubscribeMarketStats(marketIndex int16) error {
+	return p.remove(marketStatsKey(marketIndex), func(c *wsClient) error {
+		return c.UnsubscribeMarketStats(marketIndex)
+	})
+}
+
+// UnsubscribeAllMarketStats unsubscribes from market stats for all markets
+func (p *Pool) UnsubscribeAllMarketStats() error {
+	return p.remove(marketStatsAllKey(), func(c *wsClient) error {
+		return c.UnsubscribeAllMarketStats()
+	})
+}
+
+// SubscribeHeight subscribes to block height updates
+func (p *Pool) SubscribeHeight() error {
+	return p.add(heightKey(), -1, -1, "", func(c *wsClient, _ string) error {
+		return c.SubscribeHeight()
+	})
+}
+
+// UnsubscribeHeight unsubscribes from block height updates
+func (p *Pool) UnsubscribeHeight() error {
+	return p.remove(heightKey(), func(c *wsClient) error {
+		return c.UnsubscribeHeight()
+	})
+}
+
+// SubscribeAccountAll subscribes to all account updates
+func (p *Pool) SubscribeAccountAll(accountIndex int64, authToken string) error {
+	return p.add(accountKey(accountIndex), -1, accountIndex, authToken, func(c *wsClient, authToken string) error {
+		return c.SubscribeAccountAll(accountIndex, authToken)
+	})
+}
+
+// UnsubscribeAccountAll unsubscribes from all account updates
+func (p *Pool) UnsubscribeAccountAll(accountIndex int64) error {
+	return p.remove(accountKey(accountIndex), func(c *wsClient) error {
+		return c.UnsubscribeAccountAll(accountIndex)
+	})
+}
+
+// SubscribeAccountMarket subscribes to account updates for a specific market
+func (p *Pool) SubscribeAccountMarket(marketIndex int16, accountIndex int64, authToken string) error {
+	return p.add(accountMarketKey(marketIndex, accountIndex), int64(marketIndex), accountIndex, authToken, func(c *wsClient, authToken string) error {
+		return c.SubscribeAccountMarket(marketIndex, accountIndex, authToken)
+	})
+}
+
+// UnsubscribeAccountMarket unsubscribes from account updates for a specific market
+func (p *Pool) UnsubscribeAccountMarket(marketIndex int16, accountIndex int64) error {
+	return p.remove(accountMarketKey(marketIndex, accountIndex), func(c *wsClient) error {
+		return c.UnsubscribeAccountMarket(marketIndex, accountIndex)
+	})
+}
+
+// SubscribeAccountOrders subscribes to account orders for a specific market
+func (p *Pool) SubscribeAccountOrders(marketIndex int16, accountIndex int64, authToken string) error {
+	return p.add(accountOrdersKey(marketIndex, accountIndex), int64(marketIndex), accountIndex, authToken, func(c *wsClient, authToken string) error {
+		return c.SubscribeAccountOrders(marketIndex, accountIndex, authToken)
+	})
+}
+
+// UnsubscribeAccountOrders unsubscribes from account orders for a specific market
+func (p *Pool) UnsubscribeAccountOrders(marketIndex int16, accountIndex int64) error {
+	return p.remove(accountOrdersKey(marketIndex, accountIndex), func(c *wsClient) error {
+		return c.UnsubscribeAccountOrders(marketIndex, accountIndex)
+	})
+}
+
+// SubscribeAccountAllOrders subscribes to account orders across all markets
+func (p *Pool) SubscribeAccountAllOrders(accountIndex int64, authToken string) error {
+	return p.add(accountAllOrdersKey(accountIndex), -1, accountIndex, authToken, func(c *wsClient, authToken string) error {
+		return c.SubscribeAccountAllOrders(accountIndex, authToken)
+	})
+}
+
+// UnsubscribeAccountAllOrders unsubscribes from account orders across all markets
+func (p *Pool) UnsubscribeAccountAllOrders(accountIndex int64) error {
+	return p.remove(accountAllOrdersKey(accountIndex), func(c *wsClient) error {
+		return c.UnsubscribeAccountAllOrders(accountIndex)
+	})
+}
+
+// SubscribeAccountAllTrades subscribes to account trades across all markets
+func (p *Pool) SubscribeAccountAllTrades(accountIndex int64, authToken string) error {
+	return p.add(accountAllTradesKey(accountIndex), -1, accountIndex, authToken, func(c *wsClient, authToken string) error {
+		return c.SubscribeAccountAllTrades(accountIndex, authToken)
+	})
+}
+
+// UnsubscribeAccountAllTrades unsubscribes from account trades across all markets
+func (p *Pool) UnsubscribeAccountAllTrades(accountIndex int64) error {
+	return p.remove(accountAllTradesKey(accountIndex), func(c *wsClient) error {
+		return c.UnsubscribeAccountAllTrades(accountIndex)
+	})
+}
+
+// SubscribeAccountAllPositions subscribes to account positions across all markets
+func (p *Pool) SubscribeAccountAllPositions(accountIndex int64, authToken string) error {
+	return p.add(accountAllPositionsKey(accountIndex), -1, accountIndex, authToken, func(c *wsClient, authToken string) error {
+		return c.SubscribeAccountAllPositions(accountIndex, authToken)
+	})
+}
+
+// UnsubscribeAccountAllPositions unsubscribes from account positions across all markets
+func (p *Pool) UnsubscribeAccountAllPositions(accountIndex int64) error {
+	return p.remove(accountAllPositionsKey(accountIndex), func(c *wsClient) error {
+		return c.UnsubscribeAccountAllPositions(accountIndex)
+	})
+}
+
+// SubscribeAccountTx subscribes to account transactions
+func (p *Pool) SubscribeAccountTx(accountIndex int64, authToken string) error {
+	return p.add(accountTxKey(accountIndex), -1, accountIndex, authToken, func(c *wsClient, authToken string) error {
+		return c.SubscribeAccountTx(accountIndex, authToken)
+	})
+}
+
+// UnsubscribeAccountTx unsubscribes from account transactions
+func (p *Pool) UnsubscribeAccountTx(accountIndex int64) error {
+	return p.remove(accountTxKey(accountIndex), func(c *wsClient) error {
+		return c.UnsubscribeAccountTx(accountIndex)
+	})
+}
+
+// SubscribeUserStats subscribes to user stats
+func (p *Pool) SubscribeUserStats(accountIndex int64, authToken string) error {
+	return p.add(userStatsKey(accountIndex), -1, accountIndex, authToken, func(c *wsClient, authToken string) error {
+		return c.SubscribeUserStats(accountIndex, authToken)
+	})
+}
+
+// UnsubscribeUserStats unsubscribes from user stats
+func (p *Pool) UnsubscribeUserStats(accountIndex int64) error {
+	return p.remove(userStatsKey(accountIndex), func(c *wsClient) error {
+		return c.UnsubscribeUserStats(accountIndex)
+	})
+}
+
+// SubscribePoolData subscribes to pool data
+func (p *Pool) SubscribePoolData(accountIndex int64, authToken string) error {
+	return p.add(poolDataKey(accountIndex), -1, accountIndex, authToken, func(c *wsClient, authToken string) error {
+		return c.SubscribePoolData(accountIndex, authToken)
+	})
+}
+
+// UnsubscribePoolData unsubscribes from pool data
+func (p *Pool) UnsubscribePoolData(accountIndex int64) error {
+	return p.remove(poolDataKey(accountIndex), func(c *wsClient) error {
+		return c.UnsubscribePoolData(accountIndex)
+	})
+}
+
+// SubscribePoolInfo subscribes to pool info
+func (p *Pool) SubscribePoolInfo(accountIndex int64, authToken string) error {
+	return p.add(poolInfoKey(accountIndex), -1, accountIndex, authToken, func(c *wsClient, authToken string) error {
+		return c.SubscribePoolInfo(accountIndex, authToken)
+	})
+}
+
+// UnsubscribePoolInfo unsubscribes from pool info
+func (p *Pool) UnsubscribePoolInfo(accountIndex int64) error {
+	return p.remove(poolInfoKey(accountIndex), func(c *wsClient) error {
+		return c.UnsubscribePoolInfo(accountIndex)
+	})
+}
+
+// SubscribeNotification subscribes to notifications
+func (p *Pool) SubscribeNotification(accountIndex int64, authToken string) error {
+	return p.add(notificationKey(accountIndex), -1, accountIndex, authToken, func(c *wsClient, authToken string) error {
+		return c.SubscribeNotification(accountIndex, authToken)
+	})
+}
+
+// UnsubscribeNotification unsubscribes from notifications
+func (p *Pool) UnsubscribeNotification(accountIndex int64) error {
+	return p.remove(notificationKey(accountIndex), func(c *wsClient) error {
+		return c.UnsubscribeNotification(accountIndex)
+	})
+}
+
+// SendTx sends a transaction on the first live connection
+func (p *Pool) SendTx(tx interface{}) error {
+	m, err := p.txMember()
+	if err != nil {
+		return err
+	}
+	return m.SendTx(tx)
+}
+
+// SendTxBatch sends a batch of transactions on the first live connection
+func (p *Pool) SendTxBatch(txs []interface{}) error {
+	m, err := p.txMember()
+	if err != nil {
+		return err
+	}
+	return m.SendTxBatch(txs)
+}
+
+// SendTxAndWait submits a signed transaction on the first live connection and waits for its result
+func (p *Pool) SendTxAndWait(ctx context.Context, tx txtypes.TxInfo) (*TxResult, error) {
+	m, err := p.txMember()
+	if err != nil {
+		return nil, err
+	}
+	return m.SendTxAndWait(ctx, tx)
+}
+
+// SendTxBatchAndWait submits signed transactions on the first live connection and waits for their results
+func (p *Pool) SendTxBatchAndWait(ctx context.Context, txs []txtypes.TxInfo) ([]*TxResult, error) {
+	m, err := p.txMember()
+	if err != nil {
+		return nil, err
+	}
+	return m.SendTxBatchAndWait(ctx, txs)
+}
+
+var _ Client = (*Pool)(nil)
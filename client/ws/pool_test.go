@@ -0,0 +1,133 @@
+package ws
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// dropConn closes the i-th connection accepted by the server
+func (s *flakyServer) dropConn(i int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.conns[i].CloseNow()
+}
+
+func TestPool_ShardsByMarket(t *testing.T) {
+	srv := newFlakyServer(t)
+	p := NewPool(srv.url(), supervisorOptions(), DefaultPoolOptions().WithConnections(3))
+	runClient(t, p)
+	defer p.Close()
+
+	for market := int16(0); market < 6; market++ {
+		if err := p.SubscribeOrderBook(market); err != nil {
+			t.Fatalf("subscribe order book %d: %v", market, err)
+		}
+	}
+	if err := p.SubscribeTrades(4); err != nil {
+		t.Fatalf("subscribe trades: %v", err)
+	}
+	if err := p.SubscribeOrderBook(4); !errors.Is(err, ErrAlreadySubscribed) {
+		t.Errorf("expected ErrAlreadySubscribed, got %v", err)
+	}
+
+	if counts := p.SubscriptionCounts(); counts[0] != 2 || counts[1] != 3 || counts[2] != 2 {
+		t.Errorf("expected markets spread by index, got %v", counts)
+	}
+	if m, _ := p.memberOf(tradeKey(4)); m != p.members[1] {
+		t.Error("expected the trades of market 4 next to its order book")
+	}
+
+	// Snapshots of every connection arrive on the merged channel
+	seen := make(map[int16]bool)
+	for len(seen) < 6 {
+		select {
+		case update := <-p.OrderBookUpdates():
+			seen[update.MarketIndex] = true
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected snapshots of all markets, got %v", seen)
+		}
+	}
+	if _, err := p.GetOrderBookState(5); err != nil {
+		t.Errorf("GetOrderBookState failed: %v", err)
+	}
+
+	if err := p.UnsubscribeOrderBook(4); err != nil {
+		t.Fatalf("unsubscribe: %v", err)
+	}
+	if counts := p.SubscriptionCounts(); counts[1] != 2 {
+		t.Errorf("expected the subscription released, got %v", counts)
+	}
+}
+
+func TestPool_MaxSubscriptionsPerConnection(t *testing.T) {
+	srv := newFlakyServer(t)
+	p := NewPool(srv.url(), supervisorOptions(), DefaultPoolOptions().
+		WithConnections(2).
+		WithPolicy(ShardRoundRobin).
+		WithMaxSubscriptionsPerConnection(1))
+	runClient(t, p)
+	defer p.Close()
+
+	if err := p.SubscribeTrades(0); err != nil {
+		t.Fatalf("subscribe trades 0: %v", err)
+	}
+	if err := p.SubscribeTrades(1); err != nil {
+		t.Fatalf("subscribe trades 1: %v", err)
+	}
+	if err := p.SubscribeTrades(2); !errors.Is(err, ErrPoolFull) {
+		t.Errorf("expected ErrPoolFull, got %v", err)
+	}
+}
+
+func TestPool_RebalancesLostConnection(t *testing.T) {
+	srv := newFlakyServer(t)
+	rebalanced := make(chan *RebalanceResult, 1)
+	p := NewPool(srv.url(), supervisorOptions(), DefaultPoolOptions().
+		WithConnections(2).
+		WithRebalanceAfter(50*time.Millisecond).
+		WithOnRebalance(func(r *RebalanceResult) {
+			select {
+			case rebalanced <- r:
+			default:
+			}
+		}))
+	runClient(t, p)
+	defer p.Close()
+
+	for market := int16(0); market < 4; market++ {
+		if err := p.SubscribeTrades(market); err != nil {
+			t.Fatalf("subscribe trades %d: %v", market, err)
+		}
+	}
+
+	// Keep the second connection down
+	srv.mu.Lock()
+	srv.refuse = true
+	srv.mu.Unlock()
+	srv.dropConn(1)
+
+	select {
+	case r := <-rebalanced:
+		if r.From != 1 || len(r.Moved) != 2 || len(r.Failed) != 0 {
+			t.Errorf("expected markets 1 and 3 moved, got %+v", r)
+		}
+		for key, to := range r.Moved {
+			if to != 0 {
+				t.Errorf("expected %s moved to connection 0, got %d", key, to)
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("lost connection was not rebalanced")
+	}
+
+	if counts := p.SubscriptionCounts(); counts[0] != 4 || counts[1] != 0 {
+		t.Errorf("expected every subscription on connection 0, got %v", counts)
+	}
+	if err := p.SubscribeTrades(5); err != nil {
+		t.Errorf("expected new subscriptions on the live connection, got %v", err)
+	}
+	if p.IsConnected() {
+		t.Error("expected the pool to report the lost connection")
+	}
+}
@@ -0,0 +1,199 @@
+package ws
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// recordingMagic starts every session recording
+const recordingMagic = "LWSREC1\n"
+
+// maxFrameSize bounds the frames read back from a recording
+const maxFrameSize = 64 << 20
+
+// Frame is a raw inbound message of a recorded session
+type Frame struct {
+	Offset time.Duration // Time since the recording started, on the monotonic clock
+	Data   []byte
+}
+
+// Recorder writes raw inbound frames with their timestamps to a compact log: a header,
+// then per frame the varint delay since the previous frame in nanoseconds, the varint
+// length and the frame itself. It is safe for concurrent use, so the connections of a
+// Pool can share one.
+type Recorder struct {
+	mu     sync.Mutex
+	w      *bufio.Writer
+	closer io.Closer
+	start  time.Time
+	last   time.Duration
+	err    error
+}
+
+// NewRecorder starts a recording on w. Frames are buffered; call Flush or Close.
+func NewRecorder(w io.Writer) (*Recorder, error) {
+	r := &Recorder{w: bufio.NewWriter(w), start: time.Now()}
+	if closer, ok := w.(io.Closer); ok {
+		r.closer = closer
+	}
+	if _, err := r.w.WriteString(recordingMagic); err != nil {
+		return nil, fmt.Errorf("failed to write recording header: %w", err)
+	}
+	return r, nil
+}
+
+// CreateRecording starts a recording in a new file at path, truncating an existing one
+func CreateRecording(path string) (*Recorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recording: %w", err)
+	}
+	r, err := NewRecorder(f)
+	if err != nil {
+		f.Close() //nolint:errcheck // Returning the header error
+		return nil, err
+	}
+	return r, nil
+}
+
+// Record appends a frame, timestamped now. After a write error, the recording stops
+// and every call returns that error.
+func (r *Recorder) Record(frame []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+
+	offset := time.Since(r.start)
+	if offset < r.last {
+		offset = r.last
+	}
+	var header [2 * binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(header[:], uint64(offset-r.last))
+	n += binary.PutUvarint(header[n:], uint64(len(frame)))
+	r.last = offset
+
+	if _, err := r.w.Write(header[:n]); err != nil {
+		r.err = fmt.Errorf("failed to record frame: %w", err)
+		return r.err
+	}
+	if _, err := r.w.Write(frame); err != nil {
+		r.err = fmt.Errorf("failed to record frame: %w", err)
+		return r.err
+	}
+	return nil
+}
+
+// Flush writes the buffered frames
+func (r *Recorder) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	return r.w.Flush()
+}
+
+// Close flushes the recording and closes the underlying writer if it is a Closer
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err := r.err
+	if err == nil {
+		err = r.w.Flush()
+	}
+	if r.err == nil {
+		r.err = errors.New("recording closed")
+	}
+	if r.closer != nil {
+		err = errors.Join(err, r.closer.Close())
+		r.closer = nil
+	}
+	return err
+}
+
+// RecordingReader reads the frames of a session recording
+type RecordingReader struct {
+	r      *bufio.Reader
+	closer io.Closer
+	offset time.Duration
+}
+
+// NewRecordingReader reads a recording from r
+func NewRecordingReader(r io.Reader) (*RecordingReader, error) {
+	rr := &RecordingReader{r: bufio.NewReader(r)}
+	if closer, ok := r.(io.Closer); ok {
+		rr.closer = closer
+	}
+
+	magic := make([]byte, len(recordingMagic))
+	if _, err := io.ReadFull(rr.r, magic); err != nil || string(magic) != recordingMagic {
+		return nil, ErrInvalidRecording
+	}
+	return rr, nil
+}
+
+// OpenRecording reads the recording at path
+func OpenRecording(path string) (*RecordingReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recording: %w", err)
+	}
+	rr, err := NewRecordingReader(f)
+	if err != nil {
+		f.Close() //nolint:errcheck // Returning the header error
+		return nil, err
+	}
+	return rr, nil
+}
+
+// Next returns the next frame, or io.EOF at the end of the recording
+func (rr *RecordingReader) Next() (*Frame, error) {
+	delay, err := binary.ReadUvarint(rr.r)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("failed to read frame: %w", err)
+	}
+	size, err := binary.ReadUvarint(rr.r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read frame: %w", noEOF(err))
+	}
+	if size > maxFrameSize {
+		return nil, fmt.Errorf("%w: frame of %d bytes", ErrInvalidRecording, size)
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(rr.r, data); err != nil {
+		return nil, fmt.Errorf("failed to read frame: %w", noEOF(err))
+	}
+	rr.offset += time.Duration(delay)
+	return &Frame{Offset: rr.offset, Data: data}, nil
+}
+
+// Close closes the underlying reader if it is a Closer
+func (rr *RecordingReader) Close() error {
+	if rr.closer == nil {
+		return nil
+	}
+	err := rr.closer.Close()
+	rr.closer = nil
+	return err
+}
+
+// noEOF reports a recording cut off inside a frame as truncated rather than finished
+func noEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,167 @@
+package ws
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecording_RoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	rec, err := NewRecorder(&buf)
+	if err != nil {
+		t.Fatalf("NewRecorder failed: %v", err)
+	}
+	frames := [][]byte{[]byte(`{"type":"connected"}`), {}, []byte(`{"type":"ping"}`)}
+	for i, frame := range frames {
+		if i == 2 {
+			time.Sleep(20 * time.Millisecond)
+		}
+		if err := rec.Record(frame); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := rec.Record(frames[0]); err == nil {
+		t.Error("expected an error recording after Close")
+	}
+
+	rr, err := NewRecordingReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("NewRecordingReader failed: %v", err)
+	}
+	var last time.Duration
+	for i, want := range frames {
+		frame, err := rr.Next()
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		if !bytes.Equal(frame.Data, want) || frame.Offset < last {
+			t.Errorf("frame %d: got %q at %v after %v", i, frame.Data, frame.Offset, last)
+		}
+		last = frame.Offset
+	}
+	if last < 20*time.Millisecond {
+		t.Errorf("expected the pause to be recorded, got offset %v", last)
+	}
+	if _, err := rr.Next(); !errors.Is(err, io.EOF) {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+
+	// A recording cut off inside a frame is reported as truncated
+	rr, _ = NewRecordingReader(bytes.NewReader(buf.Bytes()[:buf.Len()-3]))
+	rr.Next() //nolint:errcheck
+	rr.Next() //nolint:errcheck
+	if _, err := rr.Next(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+	if _, err := NewRecordingReader(bytes.NewReader([]byte("not a recording"))); !errors.Is(err, ErrInvalidRecording) {
+		t.Errorf("expected ErrInvalidRecording, got %v", err)
+	}
+}
+
+func TestReplayer_ReproducesLiveSession(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.rec")
+	rec, err := CreateRecording(path)
+	if err != nil {
+		t.Fatalf("CreateRecording failed: %v", err)
+	}
+
+	srv := newFlakyServer(t)
+	live := NewClient(srv.url(), supervisorOptions().WithRecorder(rec))
+	if err := live.Connect(context.Background()); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	if err := live.SubscribeOrderBook(3); err != nil {
+		t.Fatalf("subscribe order book: %v", err)
+	}
+	if err := live.SubscribeTrades(3); err != nil {
+		t.Fatalf("subscribe trades: %v", err)
+	}
+	want, _ := live.GetOrderBookState(3)
+	live.Close() //nolint:errcheck
+	if err := rec.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	rr, err := OpenRecording(path)
+	if err != nil {
+		t.Fatalf("OpenRecording failed: %v", err)
+	}
+	defer rr.Close()
+
+	r := NewReplayer(nil)
+	if err := r.Replay(context.Background(), rr); err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	got, err := r.Client().GetOrderBookState(3)
+	if err != nil {
+		t.Fatalf("GetOrderBookState failed: %v", err)
+	}
+	if got.View().Sequence != want.View().Sequence || len(got.GetBids()) != 1 || got.GetBids()[0] != want.GetBids()[0] {
+		t.Errorf("expected the live book %+v, got %+v", want.GetBids(), got.GetBids())
+	}
+	select {
+	case update := <-r.Client().OrderBookUpdates():
+		if update.MarketIndex != 3 {
+			t.Errorf("expected the snapshot of market 3, got %d", update.MarketIndex)
+		}
+	default:
+		t.Error("expected the replayed snapshot on the event channel")
+	}
+	if err := r.Client().SubscribeOrderBook(4); !errors.Is(err, ErrNotConnected) {
+		t.Errorf("expected ErrNotConnected, got %v", err)
+	}
+}
+
+func TestReplayer_Pacing(t *testing.T) {
+	var buf bytes.Buffer
+	rec, _ := NewRecorder(&buf)
+	rec.Record(orderBookMsg(MessageTypeSubscribedOrderBook, 100, 0, 10, `[{"price":"100","size":"1"}]`)) //nolint:errcheck
+	rec.Record([]byte(`{"type":"ping"}`))                                                                //nolint:errcheck
+	time.Sleep(100 * time.Millisecond)
+	rec.Record(orderBookMsg(MessageTypeUpdateOrderBook, 101, 10, 11, `[{"price":"100","size":"2"}]`)) //nolint:errcheck
+	rec.Close()                                                                                       //nolint:errcheck
+
+	replay := func(speed float64) (time.Duration, *wsClient) {
+		rr, _ := NewRecordingReader(bytes.NewReader(buf.Bytes()))
+		r := NewReplayer(nil).WithSpeed(speed)
+		start := time.Now()
+		if err := r.Replay(context.Background(), rr); err != nil {
+			t.Fatalf("Replay failed: %v", err)
+		}
+		return time.Since(start), r.client
+	}
+
+	elapsed, c := replay(1)
+	if elapsed < 100*time.Millisecond {
+		t.Errorf("expected real-time replay to take the recorded 100ms, took %v", elapsed)
+	}
+	state, _ := c.GetOrderBookState(0)
+	if bid, _ := state.Bid("100"); bid.Size != "2" {
+		t.Errorf("expected the update applied, got size %s", bid.Size)
+	}
+	select {
+	case err := <-c.Errors():
+		t.Errorf("expected the ping to be answered silently, got %v", err)
+	default:
+	}
+
+	if elapsed, _ := replay(0); elapsed >= 100*time.Millisecond {
+		t.Errorf("expected an unpaced replay, took %v", elapsed)
+	}
+
+	// A cancelled context stops a paced replay
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rr, _ := NewRecordingReader(bytes.NewReader(buf.Bytes()))
+	if err := NewReplayer(nil).WithSpeed(1).Replay(ctx, rr); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
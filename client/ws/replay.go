@@ -0,0 +1,74 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// Replayer feeds recorded frames through a client's message handlers without a network,
+// so order book states, trade streams and account events can be tested deterministically.
+// The client is never connected: subscribing and sending return ErrNotConnected, and
+// replies such as pongs are discarded.
+type Replayer struct {
+	client *wsClient
+	speed  float64
+}
+
+// NewReplayer creates a replayer whose client publishes events as configured by options.
+// Frames are replayed as fast as possible unless WithSpeed is set.
+func NewReplayer(options *Options) *Replayer {
+	if options == nil {
+		options = DefaultOptions()
+	}
+	c := newClient("", options, newClientEvents(options))
+	c.replaying = true
+	c.readyCh = make(chan struct{}) // Closed by a recorded "connected" message
+	return &Replayer{client: c}
+}
+
+// WithSpeed replays frames at their recorded pace scaled by speed,
+// e.g. 1 for real time, 10 for ten times faster, 0 for as fast as possible
+func (r *Replayer) WithSpeed(speed float64) *Replayer {
+	r.speed = speed
+	return r
+}
+
+// Client returns the client fed by the replayer, for its event channels and order book states
+func (r *Replayer) Client() Client {
+	return r.client
+}
+
+// Replay feeds every frame of the recording to the client and returns at its end.
+// Handler errors are reported on Errors(), as for a live connection.
+func (r *Replayer) Replay(ctx context.Context, recording *RecordingReader) error {
+	start := time.Now()
+	for {
+		frame, err := recording.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if r.speed > 0 {
+			at := start.Add(time.Duration(float64(frame.Offset) / r.speed))
+			if wait := time.Until(at); wait > 0 {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(wait):
+				}
+			}
+		}
+
+		if err := r.client.handleMessage(frame.Data); err != nil {
+			r.client.sendError(err)
+		}
+	}
+}
@@ -0,0 +1,342 @@
+package ws
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/0xJord4n/lighter-go/types/api"
+)
+
+// OrderBookFetcher fetches order book snapshots over REST.
+// The HTTP client's Order() API implements it.
+type OrderBookFetcher interface {
+	GetOrderBooks(marketID *int16, filter api.MarketFilter) (*api.OrderBooks, error)
+}
+
+// ContextOrderBookFetcher is an OrderBookFetcher whose requests can be bound to a context.
+// The HTTP client's Order() API implements it, so that closing the client stops a resync.
+type ContextOrderBookFetcher interface {
+	OrderBookFetcher
+	GetOrderBooksWithContext(ctx context.Context, marketID *int16, filter api.MarketFilter) (*api.OrderBooks, error)
+}
+
+// maxRESTResyncAttempts is the number of REST snapshots tried before resubscribing, when
+// the buffered deltas do not follow a snapshot
+const maxRESTResyncAttempts = 3
+
+// Order book resync sources
+const (
+	ResyncSourceREST        = "rest"
+	ResyncSourceResubscribe = "resubscribe"
+)
+
+// OrderBookResync describes a rebuilt order book
+type OrderBookResync struct {
+	MarketIndex int16
+	Reason      error  // ErrSequenceGap or ErrStaleOrderBook
+	Source      string // ResyncSourceREST or ResyncSourceResubscribe
+	Offset      int64  // Offset of the snapshot the book was rebuilt from
+	Replayed    int    // Buffered deltas applied on top of the snapshot
+	Dropped     int    // Buffered deltas already contained in the snapshot, or over the buffer limit
+}
+
+// orderBookMessage is the order book payload of subscribed/order_book and update/order_book messages.
+// Each update carries the nonce of the previous one as begin_nonce, so a dropped frame shows up as a mismatch.
+type orderBookMessage struct {
+	Bids       []OrderBookLevel `json:"bids"`
+	Asks       []OrderBookLevel `json:"asks"`
+	Offset     int64            `json:"offset"`
+	Nonce      int64            `json:"nonce"`
+	BeginNonce int64            `json:"begin_nonce"`
+}
+
+// bookStream tracks the continuity of a market's order book stream
+type bookStream struct {
+	mu         sync.Mutex
+	offset     int64
+	nonce      int64
+	lastUpdate time.Time
+	resyncing  bool
+	reason     error
+	buffer     []orderBookMessage
+	dropped    int
+	lastDrop   int64 // Offset of the newest delta dropped from the buffer
+	attempts   int   // Snapshots the buffered deltas did not follow
+}
+
+// bookStream returns the stream tracker of a market, creating it if needed
+func (c *wsClient) bookStream(marketIndex int16) *bookStream {
+	c.orderBookMu.Lock()
+	defer c.orderBookMu.Unlock()
+
+	s, ok := c.bookStreams[marketIndex]
+	if !ok {
+		s = &bookStream{}
+		c.bookStreams[marketIndex] = s
+	}
+	return s
+}
+
+// follows reports whether an update can be applied after the stream's last message.
+// stale is set for duplicated or out of order updates, which must be ignored.
+func (s *bookStream) follows(msg *orderBookMessage) (ok bool, stale bool) {
+	if msg.Offset != 0 && s.offset != 0 && msg.Offset <= s.offset {
+		return false, true
+	}
+	if msg.BeginNonce != 0 && s.nonce != 0 && msg.BeginNonce != s.nonce {
+		return false, false
+	}
+	return true, false
+}
+
+// advance records msg as the last applied message
+func (s *bookStream) advance(msg *orderBookMessage) {
+	if msg.Offset != 0 {
+		s.offset = msg.Offset
+	}
+	if msg.Nonce != 0 {
+		s.nonce = msg.Nonce
+	}
+	s.lastUpdate = time.Now()
+}
+
+// bufferDelta keeps a delta received while the book is rebuilt
+func (c *wsClient) bufferDelta(s *bookStream, msg orderBookMessage) {
+	if limit := c.options.OrderBookResyncBuffer; limit > 0 && len(s.buffer) >= limit {
+		s.lastDrop = s.buffer[0].Offset
+		s.buffer = s.buffer[1:]
+		s.dropped++
+	}
+	s.buffer = append(s.buffer, msg)
+}
+
+// startResync marks the book as out of sync and rebuilds it in the background.
+// It must be called with the stream lock held.
+func (c *wsClient) startResync(marketIndex int16, s *bookStream, reason error) {
+	if s.resyncing {
+		return
+	}
+	s.resyncing = true
+	s.reason = reason
+	s.buffer = nil
+	s.dropped = 0
+	s.lastDrop = 0
+	s.attempts = 0
+
+	if c.options.OrderBookFetcher != nil {
+		c.wg.Add(1)
+		go c.resyncFromREST(marketIndex, s)
+		return
+	}
+	c.wg.Add(1)
+	go c.resyncBySubscription(marketIndex)
+}
+
+// resyncBySubscription asks the server for a fresh snapshot; the resync completes
+// when the subscribed message arrives
+func (c *wsClient) resyncBySubscription(marketIndex int16) {
+	defer c.wg.Done()
+
+	channel := fmt.Sprintf("order_book/%d", marketIndex)
+	if err := c.unsubscribe(channel); err != nil {
+		c.sendError(err)
+	}
+	if err := c.subscribe(channel, ""); err != nil {
+		c.sendError(fmt.Errorf("order book %d resync: %w", marketIndex, err))
+	}
+}
+
+// retryResync rebuilds the book again, keeping the buffered deltas. It resubscribes once
+// maxRESTResyncAttempts REST snapshots were tried.
+// It must be called with the stream lock held.
+func (c *wsClient) retryResync(marketIndex int16, s *bookStream) {
+	s.attempts++
+	c.wg.Add(1)
+	if c.options.OrderBookFetcher != nil && s.attempts < maxRESTResyncAttempts {
+		go c.resyncFromREST(marketIndex, s)
+		return
+	}
+	go c.resyncBySubscription(marketIndex)
+}
+
+// continues reports whether the buffered deltas newer than a snapshot at offset follow it
+// without a hole, and returns the nonce of the snapshot. If nonce is 0, the snapshot's nonce
+// is unknown and taken from the buffered delta preceding the newer ones.
+// It must be called with the stream lock held.
+func (s *bookStream) continues(offset, nonce int64) (int64, bool) {
+	if s.dropped > 0 && (offset == 0 || s.lastDrop == 0 || s.lastDrop > offset) {
+		// A delta newer than the snapshot was dropped from the buffer
+		return 0, false
+	}
+
+	snapshotNonce, prev, newer := nonce, nonce, false
+	for i := range s.buffer {
+		msg := &s.buffer[i]
+		if offset != 0 && msg.Offset != 0 && msg.Offset <= offset {
+			if nonce == 0 && !newer {
+				snapshotNonce, prev = msg.Nonce, msg.Nonce
+			}
+			continue
+		}
+		if msg.BeginNonce != 0 && msg.BeginNonce != prev {
+			return 0, false
+		}
+		prev, newer = msg.Nonce, true
+	}
+	return snapshotNonce, true
+}
+
+// fetchOrderBook fetches the REST snapshot of a market, bound to ctx if the fetcher supports it
+func (c *wsClient) fetchOrderBook(ctx context.Context, marketIndex int16) (*api.OrderBooks, error) {
+	if f, ok := c.options.OrderBookFetcher.(ContextOrderBookFetcher); ok && ctx != nil {
+		return f.GetOrderBooksWithContext(ctx, &marketIndex, api.MarketFilterAll)
+	}
+	return c.options.OrderBookFetcher.GetOrderBooks(&marketIndex, api.MarketFilterAll)
+}
+
+func (c *wsClient) resyncFromREST(marketIndex int16, s *bookStream) {
+	defer c.wg.Done()
+
+	c.connMu.RLock()
+	ctx := c.ctx
+	c.connMu.RUnlock()
+
+	books, err := c.fetchOrderBook(ctx, marketIndex)
+	if ctx != nil && ctx.Err() != nil {
+		// The connection is gone; the snapshot of the next subscription completes the resync
+		return
+	}
+	if err == nil && (books == nil || len(books.OrderBooks) == 0) {
+		err = ErrOrderBookNotFound
+	}
+	if err != nil {
+		c.sendError(fmt.Errorf("order book %d resync: %w", marketIndex, err))
+		c.wg.Add(1)
+		go c.resyncBySubscription(marketIndex)
+		return
+	}
+
+	ob := books.OrderBooks[0]
+	snapshot := &OrderBookSnapshot{
+		MarketIndex: marketIndex,
+		Sequence:    ob.Sequence,
+		Bids:        fromPriceLevels(ob.Bids),
+		Asks:        fromPriceLevels(ob.Asks),
+		Timestamp:   ob.Timestamp,
+	}
+
+	state := c.orderBookState(marketIndex)
+	s.mu.Lock()
+	if !s.resyncing {
+		// A subscription snapshot completed the resync first
+		s.mu.Unlock()
+		return
+	}
+	nonce, ok := s.continues(snapshot.Sequence, 0)
+	if !ok {
+		// Deltas between the snapshot and the buffered ones are missing
+		c.retryResync(marketIndex, s)
+		s.mu.Unlock()
+		return
+	}
+	if err := state.ApplySnapshot(snapshot); err != nil {
+		s.mu.Unlock()
+		c.sendError(fmt.Errorf("order book %d resync: %w", marketIndex, err))
+		return
+	}
+	s.offset, s.nonce = snapshot.Sequence, nonce
+	update := c.finishResync(marketIndex, s, state, snapshot, ResyncSourceREST)
+	s.mu.Unlock()
+
+	c.emitOrderBookUpdate(update)
+}
+
+// finishResync replays the buffered deltas newer than the snapshot and returns the update to emit.
+// It must be called with the stream lock held, after the snapshot was applied.
+func (c *wsClient) finishResync(marketIndex int16, s *bookStream, state *OrderBookState, snapshot *OrderBookSnapshot, source string) *OrderBookUpdate {
+	resync := &OrderBookResync{
+		MarketIndex: marketIndex,
+		Reason:      s.reason,
+		Source:      source,
+		Offset:      snapshot.Sequence,
+		Dropped:     s.dropped,
+	}
+
+	for i := range s.buffer {
+		msg := &s.buffer[i]
+		if snapshot.Sequence != 0 && msg.Offset != 0 && msg.Offset <= snapshot.Sequence {
+			resync.Dropped++
+			continue
+		}
+		if err := state.MergeUpdates(msg.Bids, msg.Asks); err != nil {
+			resync.Dropped++
+			continue
+		}
+		s.advance(msg)
+		resync.Replayed++
+	}
+
+	s.resyncing = false
+	s.reason = nil
+	s.buffer = nil
+	s.dropped = 0
+	s.lastDrop = 0
+	s.attempts = 0
+	s.lastUpdate = time.Now()
+
+	return &OrderBookUpdate{
+		MarketIndex: marketIndex,
+		IsSnapshot:  true,
+		Snapshot:    snapshot,
+		State:       state.Clone(),
+		Resync:      resync,
+	}
+}
+
+// staleBookLoop resyncs order books that received no update for OrderBookStaleAfter
+func (c *wsClient) staleBookLoop(done <-chan struct{}) {
+	defer c.wg.Done()
+
+	interval := c.options.OrderBookStaleAfter / 2
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			c.checkStaleBooks()
+		}
+	}
+}
+
+func (c *wsClient) checkStaleBooks() {
+	c.orderBookMu.RLock()
+	streams := make(map[int16]*bookStream, len(c.bookStreams))
+	for idx, s := range c.bookStreams {
+		streams[idx] = s
+	}
+	c.orderBookMu.RUnlock()
+
+	for idx, s := range streams {
+		if !c.subscriptions.IsSubscribed(orderBookKey(idx)) {
+			continue
+		}
+		s.mu.Lock()
+		if !s.resyncing && !s.lastUpdate.IsZero() && time.Since(s.lastUpdate) > c.options.OrderBookStaleAfter {
+			c.startResync(idx, s, ErrStaleOrderBook)
+		}
+		s.mu.Unlock()
+	}
+}
+
+func fromPriceLevels(levels []api.PriceLevel) []OrderBookLevel {
+	result := make([]OrderBookLevel, len(levels))
+	for i, l := range levels {
+		result[i] = OrderBookLevel{Price: l.Price, Size: l.Size, OrderCount: l.OrderCount}
+	}
+	return result
+}
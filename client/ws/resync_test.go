@@ -0,0 +1,297 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/0xJord4n/lighter-go/types/api"
+)
+
+// mockOrderBookFetcher serves a fixed REST snapshot once released, after the ones in next
+type mockOrderBookFetcher struct {
+	book    api.OrderBook
+	err     error
+	release chan struct{}
+
+	mu    sync.Mutex
+	next  []api.OrderBook
+	calls int
+}
+
+func (m *mockOrderBookFetcher) GetOrderBooks(marketID *int16, filter api.MarketFilter) (*api.OrderBooks, error) {
+	<-m.release
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls++
+	book := m.book
+	if len(m.next) > 0 {
+		book, m.next = m.next[0], m.next[1:]
+	}
+	return &api.OrderBooks{OrderBooks: []api.OrderBook{book}}, nil
+}
+
+func (m *mockOrderBookFetcher) getCalls() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls
+}
+
+// contextOrderBookFetcher blocks until the context of the request is done
+type contextOrderBookFetcher struct {
+	started chan struct{}
+}
+
+func (f *contextOrderBookFetcher) GetOrderBooks(marketID *int16, filter api.MarketFilter) (*api.OrderBooks, error) {
+	select {} // The resync must use the context-bound request
+}
+
+func (f *contextOrderBookFetcher) GetOrderBooksWithContext(ctx context.Context, marketID *int16, filter api.MarketFilter) (*api.OrderBooks, error) {
+	close(f.started)
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func newResyncTestClient(fetcher OrderBookFetcher) (*wsClient, chan *OrderBookResync) {
+	resyncs := make(chan *OrderBookResync, 1)
+	opts := DefaultOptions().WithOnOrderBookResync(func(r *OrderBookResync) { resyncs <- r })
+	if fetcher != nil {
+		opts.WithOrderBookFetcher(fetcher)
+	}
+	return NewClient("", opts).(*wsClient), resyncs
+}
+
+func orderBookMsg(msgType MessageType, offset, beginNonce, nonce int64, bids string) []byte {
+	return []byte(fmt.Sprintf(`{"type":%q,"channel":"order_book:0","order_book":{"offset":%d,"begin_nonce":%d,"nonce":%d,"bids":%s,"asks":[]}}`,
+		msgType, offset, beginNonce, nonce, bids))
+}
+
+func waitResync(t *testing.T, resyncs chan *OrderBookResync) *OrderBookResync {
+	t.Helper()
+	select {
+	case r := <-resyncs:
+		return r
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for resync")
+		return nil
+	}
+}
+
+func TestOrderBookResync_GapRebuildsFromREST(t *testing.T) {
+	fetcher := &mockOrderBookFetcher{
+		book: api.OrderBook{
+			MarketIndex: 0,
+			Sequence:    120,
+			Bids:        []api.PriceLevel{{Price: "100", Size: "7"}},
+		},
+		release: make(chan struct{}),
+	}
+	c, resyncs := newResyncTestClient(fetcher)
+
+	messages := [][]byte{
+		orderBookMsg(MessageTypeSubscribedOrderBook, 100, 0, 10, `[{"price":"100","size":"1"}]`),
+		orderBookMsg(MessageTypeUpdateOrderBook, 101, 10, 11, `[{"price":"100","size":"2"}]`),
+		// Frame with nonce 12 lost
+		orderBookMsg(MessageTypeUpdateOrderBook, 110, 12, 13, `[{"price":"100","size":"3"}]`),
+		// Buffered during the resync: already in the snapshot, then newer
+		orderBookMsg(MessageTypeUpdateOrderBook, 115, 13, 14, `[{"price":"100","size":"4"}]`),
+		orderBookMsg(MessageTypeUpdateOrderBook, 125, 14, 15, `[{"price":"99","size":"5"}]`),
+	}
+	for _, msg := range messages {
+		if err := c.handleMessage(msg); err != nil {
+			t.Fatalf("handleMessage failed: %v", err)
+		}
+	}
+
+	state, _ := c.GetOrderBookState(0)
+	if bid, _ := state.Bid("100"); bid.Size != "2" {
+		t.Errorf("deltas after the gap should not be applied before the resync, got size %s", bid.Size)
+	}
+
+	close(fetcher.release)
+	r := waitResync(t, resyncs)
+
+	if !errors.Is(r.Reason, ErrSequenceGap) || r.Source != ResyncSourceREST || r.Offset != 120 {
+		t.Errorf("unexpected resync event: %+v", r)
+	}
+	if r.Replayed != 1 || r.Dropped != 2 {
+		t.Errorf("expected 1 replayed and 2 dropped deltas, got %d and %d", r.Replayed, r.Dropped)
+	}
+
+	state, _ = c.GetOrderBookState(0)
+	if bid, _ := state.Bid("100"); bid.Size != "7" {
+		t.Errorf("expected size 7 from the REST snapshot, got %s", bid.Size)
+	}
+	if bid, ok := state.Bid("99"); !ok || bid.Size != "5" {
+		t.Errorf("expected replayed level at 99, got %+v", bid)
+	}
+
+	// The stream continues from the last replayed delta
+	if err := c.handleMessage(orderBookMsg(MessageTypeUpdateOrderBook, 126, 15, 16, `[{"price":"98","size":"1"}]`)); err != nil {
+		t.Fatalf("handleMessage failed: %v", err)
+	}
+	state, _ = c.GetOrderBookState(0)
+	if _, ok := state.Bid("98"); !ok {
+		t.Error("expected update after the resync to be applied")
+	}
+}
+
+func TestOrderBookResync_IgnoresStaleUpdates(t *testing.T) {
+	c, _ := newResyncTestClient(nil)
+
+	messages := [][]byte{
+		orderBookMsg(MessageTypeSubscribedOrderBook, 100, 0, 10, `[{"price":"100","size":"1"}]`),
+		orderBookMsg(MessageTypeUpdateOrderBook, 101, 10, 11, `[{"price":"100","size":"2"}]`),
+		orderBookMsg(MessageTypeUpdateOrderBook, 101, 10, 11, `[{"price":"100","size":"9"}]`), // Duplicate
+	}
+	for _, msg := range messages {
+		if err := c.handleMessage(msg); err != nil {
+			t.Fatalf("handleMessage failed: %v", err)
+		}
+	}
+
+	state, _ := c.GetOrderBookState(0)
+	if bid, _ := state.Bid("100"); bid.Size != "2" {
+		t.Errorf("duplicate update should be ignored, got size %s", bid.Size)
+	}
+}
+
+func TestOrderBookResync_Resubscribe(t *testing.T) {
+	c, resyncs := newResyncTestClient(nil)
+
+	messages := [][]byte{
+		orderBookMsg(MessageTypeSubscribedOrderBook, 100, 0, 10, `[{"price":"100","size":"1"}]`),
+		orderBookMsg(MessageTypeUpdateOrderBook, 105, 11, 12, `[{"price":"100","size":"3"}]`), // Gap
+		orderBookMsg(MessageTypeUpdateOrderBook, 130, 12, 13, `[{"price":"101","size":"4"}]`),
+		// Fresh snapshot sent after resubscribing
+		orderBookMsg(MessageTypeSubscribedOrderBook, 120, 0, 20, `[{"price":"100","size":"8"}]`),
+	}
+	for _, msg := range messages {
+		if err := c.handleMessage(msg); err != nil {
+			t.Fatalf("handleMessage failed: %v", err)
+		}
+	}
+
+	r := waitResync(t, resyncs)
+	if r.Source != ResyncSourceResubscribe || r.Replayed != 1 || r.Dropped != 1 {
+		t.Errorf("unexpected resync event: %+v", r)
+	}
+
+	state, _ := c.GetOrderBookState(0)
+	if bid, _ := state.Bid("100"); bid.Size != "8" {
+		t.Errorf("expected size 8 from the new snapshot, got %s", bid.Size)
+	}
+	if _, ok := state.Bid("101"); !ok {
+		t.Error("expected buffered delta newer than the snapshot to be replayed")
+	}
+}
+
+func TestOrderBookResync_RestartsOnHole(t *testing.T) {
+	fetcher := &mockOrderBookFetcher{
+		// The delta with nonce 14 at offset 115 never arrives: a snapshot at 120 leaves a hole
+		next:    []api.OrderBook{{Sequence: 120, Bids: []api.PriceLevel{{Price: "100", Size: "7"}}}},
+		book:    api.OrderBook{Sequence: 125, Bids: []api.PriceLevel{{Price: "100", Size: "8"}}},
+		release: make(chan struct{}),
+	}
+	c, resyncs := newResyncTestClient(fetcher)
+
+	messages := [][]byte{
+		orderBookMsg(MessageTypeSubscribedOrderBook, 100, 0, 10, `[{"price":"100","size":"1"}]`),
+		orderBookMsg(MessageTypeUpdateOrderBook, 110, 12, 13, `[{"price":"100","size":"3"}]`), // Gap
+		orderBookMsg(MessageTypeUpdateOrderBook, 125, 14, 15, `[{"price":"99","size":"5"}]`),
+	}
+	for _, msg := range messages {
+		if err := c.handleMessage(msg); err != nil {
+			t.Fatalf("handleMessage failed: %v", err)
+		}
+	}
+
+	close(fetcher.release)
+	r := waitResync(t, resyncs)
+	if r.Offset != 125 || r.Replayed != 0 || fetcher.getCalls() != 2 {
+		t.Errorf("expected the resync restarted from the snapshot at 125, got %+v after %d fetches", r, fetcher.getCalls())
+	}
+
+	// The stream continues from the nonce of the delta at the snapshot's offset
+	if err := c.handleMessage(orderBookMsg(MessageTypeUpdateOrderBook, 126, 15, 16, `[{"price":"98","size":"1"}]`)); err != nil {
+		t.Fatalf("handleMessage failed: %v", err)
+	}
+	state, _ := c.GetOrderBookState(0)
+	if _, ok := state.Bid("98"); !ok {
+		t.Error("expected update after the resync to be applied")
+	}
+	if err := c.handleMessage(orderBookMsg(MessageTypeUpdateOrderBook, 130, 17, 18, `[{"price":"97","size":"1"}]`)); err != nil {
+		t.Fatalf("handleMessage failed: %v", err)
+	}
+	if _, ok := state.Bid("97"); ok {
+		t.Error("expected a gap after the resync to be detected")
+	}
+	c.wg.Wait()
+}
+
+func TestOrderBookResync_RestartsOnDroppedDelta(t *testing.T) {
+	fetcher := &mockOrderBookFetcher{
+		next:    []api.OrderBook{{Sequence: 112}},
+		book:    api.OrderBook{Sequence: 125},
+		release: make(chan struct{}),
+	}
+	c, resyncs := newResyncTestClient(fetcher)
+	c.options.OrderBookResyncBuffer = 1
+
+	messages := [][]byte{
+		orderBookMsg(MessageTypeSubscribedOrderBook, 100, 0, 10, `[{"price":"100","size":"1"}]`),
+		orderBookMsg(MessageTypeUpdateOrderBook, 110, 12, 13, `[{"price":"100","size":"3"}]`), // Gap
+		// Over the buffer limit: 110, then 115 are dropped
+		orderBookMsg(MessageTypeUpdateOrderBook, 115, 13, 14, `[{"price":"100","size":"4"}]`),
+		orderBookMsg(MessageTypeUpdateOrderBook, 125, 14, 15, `[{"price":"99","size":"5"}]`),
+	}
+	for _, msg := range messages {
+		if err := c.handleMessage(msg); err != nil {
+			t.Fatalf("handleMessage failed: %v", err)
+		}
+	}
+
+	close(fetcher.release)
+	r := waitResync(t, resyncs)
+	if r.Offset != 125 || fetcher.getCalls() != 2 {
+		t.Errorf("expected the resync restarted from the snapshot at 125, got %+v after %d fetches", r, fetcher.getCalls())
+	}
+	c.wg.Wait()
+}
+
+func TestOrderBookResync_FetchBoundToConnection(t *testing.T) {
+	fetcher := &contextOrderBookFetcher{started: make(chan struct{})}
+	c, _ := newResyncTestClient(fetcher)
+	ctx, cancel := context.WithCancel(context.Background())
+	c.ctx = ctx
+
+	messages := [][]byte{
+		orderBookMsg(MessageTypeSubscribedOrderBook, 100, 0, 10, `[{"price":"100","size":"1"}]`),
+		orderBookMsg(MessageTypeUpdateOrderBook, 110, 12, 13, `[{"price":"100","size":"3"}]`), // Gap
+	}
+	for _, msg := range messages {
+		if err := c.handleMessage(msg); err != nil {
+			t.Fatalf("handleMessage failed: %v", err)
+		}
+	}
+	<-fetcher.started
+
+	// Closing the connection stops the fetch
+	cancel()
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("resync did not stop with the connection")
+	}
+}
@@ -0,0 +1,284 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/0xJord4n/lighter-go/nonce"
+	"github.com/0xJord4n/lighter-go/ratelimit"
+	"github.com/0xJord4n/lighter-go/types/txtypes"
+)
+
+// maxTxBatchSize is the maximum number of transactions in a jsonapi/sendtxbatch frame
+const maxTxBatchSize = 50
+
+// TxPayload is the data of a jsonapi/sendtx frame carrying a signed transaction
+type TxPayload struct {
+	TxType uint8      `json:"tx_type"`
+	TxInfo RawMessage `json:"tx_info"`
+}
+
+// TxBatchPayload is the data of a jsonapi/sendtxbatch frame carrying signed transactions
+type TxBatchPayload struct {
+	TxTypes []uint8      `json:"tx_types"`
+	TxInfos []RawMessage `json:"tx_infos"`
+}
+
+// SignedTxRequest is sent to submit a signed transaction via WebSocket
+type SignedTxRequest struct {
+	Type string    `json:"type"` // "jsonapi/sendtx"
+	Data TxPayload `json:"data"`
+}
+
+// SignedTxBatchRequest is sent to submit signed transactions via WebSocket
+type SignedTxBatchRequest struct {
+	Type string         `json:"type"` // "jsonapi/sendtxbatch"
+	Data TxBatchPayload `json:"data"`
+}
+
+// NewTxPayload serializes a signed transaction into a jsonapi/sendtx payload
+func NewTxPayload(tx txtypes.TxInfo) (TxPayload, error) {
+	if tx.GetTxHash() == "" {
+		return TxPayload{}, ErrTxNotSigned
+	}
+	info, err := tx.GetTxInfo()
+	if err != nil {
+		return TxPayload{}, fmt.Errorf("failed to serialize tx info: %w", err)
+	}
+	return TxPayload{TxType: tx.GetTxType(), TxInfo: RawMessage(info)}, nil
+}
+
+// txOutcome is what a waiter receives: the server's result, or why none will arrive
+type txOutcome struct {
+	result *TxResult
+	err    error
+}
+
+type txWaiter struct {
+	hash string
+	done chan txOutcome
+}
+
+// txWaiters correlates tx results with the requests waiting for them.
+// Results are matched by tx hash; a result without a hash (e.g. a frame the server
+// could not parse) goes to the oldest waiter, since frames are answered in order.
+type txWaiters struct {
+	mu     sync.Mutex
+	byHash map[string][]*txWaiter
+	order  []*txWaiter
+}
+
+func newTxWaiters() *txWaiters {
+	return &txWaiters{byHash: make(map[string][]*txWaiter)}
+}
+
+func (t *txWaiters) add(hash string) *txWaiter {
+	w := &txWaiter{hash: hash, done: make(chan txOutcome, 1)}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.byHash[hash] = append(t.byHash[hash], w)
+	t.order = append(t.order, w)
+	return w
+}
+
+// remove forgets a waiter that stopped waiting; it reports whether the waiter was still pending
+func (t *txWaiters) remove(w *txWaiter) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.removeLocked(w)
+}
+
+func (t *txWaiters) removeLocked(w *txWaiter) bool {
+	found := false
+	for i, o := range t.order {
+		if o == w {
+			t.order = append(t.order[:i], t.order[i+1:]...)
+			found = true
+			break
+		}
+	}
+	waiters := t.byHash[w.hash]
+	for i, o := range waiters {
+		if o == w {
+			waiters = append(waiters[:i], waiters[i+1:]...)
+			break
+		}
+	}
+	if len(waiters) == 0 {
+		delete(t.byHash, w.hash)
+	} else {
+		t.byHash[w.hash] = waiters
+	}
+	return found
+}
+
+// resolve delivers a result to its waiters
+func (t *txWaiters) resolve(result *TxResult) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var waiters []*txWaiter
+	if result.TxHash != "" {
+		waiters = append(waiters, t.byHash[result.TxHash]...)
+	} else if len(t.order) > 0 {
+		waiters = append(waiters, t.order[0])
+	}
+	for _, w := range waiters {
+		t.removeLocked(w)
+		w.done <- txOutcome{result: result}
+	}
+}
+
+// failAll releases every waiter with err
+func (t *txWaiters) failAll(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, w := range t.order {
+		w.done <- txOutcome{err: err}
+	}
+	t.order = nil
+	t.byHash = make(map[string][]*txWaiter)
+}
+
+// SendTxAndWait submits a signed transaction and waits for its result.
+// It returns a *TxRejectedError if the server rejected the transaction. The
+// nonce manager, if set, is acknowledged with the outcome; when ctx expires or
+// the connection drops first, the outcome is unknown and the nonce is not acknowledged.
+func (c *wsClient) SendTxAndWait(ctx context.Context, tx txtypes.TxInfo) (*TxResult, error) {
+	if !c.connected.Load() {
+		return nil, ErrNotConnected
+	}
+
+	payload, err := NewTxPayload(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.acquire(ratelimit.EndpointWsSendTx, 1); err != nil {
+		return nil, err
+	}
+
+	c.trackTxHash(tx)
+	w := c.txWaiters.add(tx.GetTxHash())
+	if err := c.sendJSON(SignedTxRequest{Type: "jsonapi/sendtx", Data: payload}); err != nil {
+		c.txWaiters.remove(w)
+		c.acknowledgeTx(tx, false)
+		return nil, err
+	}
+
+	result, err := c.waitTx(ctx, w)
+	if err != nil {
+		return nil, err
+	}
+	return result, c.settleTx(tx, result)
+}
+
+// SendTxBatchAndWait submits signed transactions in one frame (max 50) and waits for their results.
+// Results are in the order of txs; a transaction whose result did not arrive has a nil result.
+// The error joins a *TxRejectedError for each rejected transaction, and the wait error if any.
+func (c *wsClient) SendTxBatchAndWait(ctx context.Context, txs []txtypes.TxInfo) ([]*TxResult, error) {
+	if !c.connected.Load() {
+		return nil, ErrNotConnected
+	}
+
+	if len(txs) > maxTxBatchSize {
+		return nil, ErrBatchTooLarge
+	}
+
+	payload := TxBatchPayload{
+		TxTypes: make([]uint8, len(txs)),
+		TxInfos: make([]RawMessage, len(txs)),
+	}
+	for i, tx := range txs {
+		p, err := NewTxPayload(tx)
+		if err != nil {
+			return nil, fmt.Errorf("tx %d: %w", i, err)
+		}
+		payload.TxTypes[i] = p.TxType
+		payload.TxInfos[i] = p.TxInfo
+	}
+
+	if err := c.acquire(ratelimit.EndpointWsSendTxBatch, len(txs)); err != nil {
+		return nil, err
+	}
+
+	waiters := make([]*txWaiter, len(txs))
+	for i, tx := range txs {
+		c.trackTxHash(tx)
+		waiters[i] = c.txWaiters.add(tx.GetTxHash())
+	}
+	if err := c.sendJSON(SignedTxBatchRequest{Type: "jsonapi/sendtxbatch", Data: payload}); err != nil {
+		for i, w := range waiters {
+			c.txWaiters.remove(w)
+			c.acknowledgeTx(txs[i], false)
+		}
+		return nil, err
+	}
+
+	results := make([]*TxResult, len(txs))
+	var errs []error
+	for i, w := range waiters {
+		result, err := c.waitTx(ctx, w)
+		if err != nil {
+			// Stop waiting for the rest of the batch
+			for _, rest := range waiters[i+1:] {
+				c.txWaiters.remove(rest)
+			}
+			errs = append(errs, err)
+			break
+		}
+		results[i] = result
+		if err := c.settleTx(txs[i], result); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+// waitTx waits for the outcome of a submitted transaction
+func (c *wsClient) waitTx(ctx context.Context, w *txWaiter) (*TxResult, error) {
+	select {
+	case outcome := <-w.done:
+		return outcome.result, outcome.err
+	case <-ctx.Done():
+		if !c.txWaiters.remove(w) {
+			// The outcome arrived concurrently
+			outcome := <-w.done
+			return outcome.result, outcome.err
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// settleTx acknowledges the nonce of a transaction and returns its rejection, if any
+func (c *wsClient) settleTx(tx txtypes.TxInfo, result *TxResult) error {
+	c.acknowledgeTx(tx, result.Success)
+	if result.Success {
+		return nil
+	}
+	return &TxRejectedError{TxHash: tx.GetTxHash(), Message: result.Error}
+}
+
+// trackTxHash hands the hash of a transaction about to be sent to the nonce manager
+func (c *wsClient) trackTxHash(tx txtypes.TxInfo) {
+	if m := c.options.NonceManager; m != nil {
+		nonce.TrackTxHash(m, tx.GetAccountIndex(), tx.GetApiKeyIndex(), tx.GetNonce(), tx.GetTxHash())
+	}
+}
+
+// acknowledgeTx reports the nonce a transaction was signed with to the nonce manager
+func (c *wsClient) acknowledgeTx(tx txtypes.TxInfo, success bool) {
+	m := c.options.NonceManager
+	if m == nil {
+		return
+	}
+	if success {
+		nonce.AcknowledgeTxSuccess(m, tx.GetAccountIndex(), tx.GetApiKeyIndex(), tx.GetNonce(), tx.GetTxHash())
+	} else {
+		nonce.AcknowledgeTxFailure(m, tx.GetAccountIndex(), tx.GetApiKeyIndex(), tx.GetNonce(), tx.GetTxHash())
+	}
+}
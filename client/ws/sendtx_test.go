@@ -0,0 +1,220 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/0xJord4n/lighter-go/types/txtypes"
+	"github.com/bytedance/sonic"
+	"github.com/coder/websocket"
+)
+
+// mockAckManager records nonce acknowledgments
+type mockAckManager struct {
+	mu        sync.Mutex
+	successes []int64
+	failures  []int64
+}
+
+func (m *mockAckManager) GetNonce(accountIndex int64, apiKeyIndex uint8) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockAckManager) AcknowledgeSuccess(accountIndex int64, apiKeyIndex uint8, nonce int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.successes = append(m.successes, nonce)
+}
+
+func (m *mockAckManager) AcknowledgeFailure(accountIndex int64, apiKeyIndex uint8, nonce int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failures = append(m.failures, nonce)
+}
+
+func (m *mockAckManager) Reset(accountIndex int64, apiKeyIndex uint8) {}
+
+func (m *mockAckManager) ResetAll() {}
+
+func (m *mockAckManager) acks() (successes, failures []int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]int64(nil), m.successes...), append([]int64(nil), m.failures...)
+}
+
+func signedCancel(nonce int64) *txtypes.L2CancelOrderTxInfo {
+	return &txtypes.L2CancelOrderTxInfo{
+		AccountIndex: 7,
+		ApiKeyIndex:  2,
+		Nonce:        nonce,
+		SignedHash:   txHashOf(nonce),
+	}
+}
+
+func txHashOf(nonce int64) string {
+	return fmt.Sprintf("0x%x", nonce)
+}
+
+// txFrame is the part of a sendtx frame the test server looks at
+type txFrame struct {
+	Type string `json:"type"`
+	Data struct {
+		TxType  uint8                   `json:"tx_type"`
+		TxInfo  struct{ Nonce int64 }   `json:"tx_info"`
+		TxTypes []uint8                 `json:"tx_types"`
+		TxInfos []struct{ Nonce int64 } `json:"tx_infos"`
+	} `json:"data"`
+}
+
+// newTxServer starts a server answering each transaction with a result; nonces in reject are rejected.
+// Batch results are sent in reverse order, so they can only be matched by hash.
+func newTxServer(t *testing.T, reject map[int64]bool, silent bool) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.CloseNow()
+
+		ctx := r.Context()
+		conn.Write(ctx, websocket.MessageText, []byte(`{"type":"connected"}`))
+
+		result := func(nonce int64) TxResult {
+			if reject[nonce] {
+				return TxResult{TxHash: txHashOf(nonce), Error: "invalid nonce"}
+			}
+			return TxResult{Success: true, TxHash: txHashOf(nonce)}
+		}
+
+		for {
+			_, msg, err := conn.Read(ctx)
+			if err != nil {
+				return
+			}
+			if silent {
+				continue
+			}
+			var frame txFrame
+			if err := sonic.Unmarshal(msg, &frame); err != nil {
+				t.Errorf("invalid frame: %v", err)
+				return
+			}
+
+			var reply any
+			switch frame.Type {
+			case "jsonapi/sendtx":
+				if frame.Data.TxType != txtypes.TxTypeL2CancelOrder {
+					t.Errorf("unexpected tx type %d", frame.Data.TxType)
+				}
+				reply = map[string]any{"type": "tx_result", "data": result(frame.Data.TxInfo.Nonce)}
+			case "jsonapi/sendtxbatch":
+				var results []TxResult
+				for i := len(frame.Data.TxInfos) - 1; i >= 0; i-- {
+					results = append(results, result(frame.Data.TxInfos[i].Nonce))
+				}
+				reply = map[string]any{"type": "tx_batch_result", "data": TxBatchResult{Results: results}}
+			}
+			data, _ := sonic.Marshal(reply)
+			conn.Write(ctx, websocket.MessageText, data)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func connectTxClient(t *testing.T, srv *httptest.Server, m *mockAckManager) Client {
+	t.Helper()
+	c := NewClient("ws"+strings.TrimPrefix(srv.URL, "http"), DefaultOptions().WithNonceManager(m))
+	// The connect context bounds the connection's lifetime
+	if err := c.Connect(context.Background()); err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestSendTxAndWait(t *testing.T) {
+	m := &mockAckManager{}
+	c := connectTxClient(t, newTxServer(t, map[int64]bool{11: true}, false), m)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := c.SendTxAndWait(ctx, signedCancel(10))
+	if err != nil || !result.Success || result.TxHash != txHashOf(10) {
+		t.Fatalf("expected success, got %+v, %v", result, err)
+	}
+
+	result, err = c.SendTxAndWait(ctx, signedCancel(11))
+	var rejected *TxRejectedError
+	if !errors.As(err, &rejected) || !errors.Is(err, ErrTxRejected) || rejected.Message != "invalid nonce" {
+		t.Fatalf("expected TxRejectedError, got %v", err)
+	}
+	if result == nil || result.Success {
+		t.Errorf("expected unsuccessful result, got %+v", result)
+	}
+
+	successes, failures := m.acks()
+	if len(successes) != 1 || successes[0] != 10 || len(failures) != 1 || failures[0] != 11 {
+		t.Errorf("unexpected acks: successes %v, failures %v", successes, failures)
+	}
+}
+
+func TestSendTxBatchAndWait(t *testing.T) {
+	m := &mockAckManager{}
+	c := connectTxClient(t, newTxServer(t, map[int64]bool{21: true}, false), m)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	txs := []txtypes.TxInfo{signedCancel(20), signedCancel(21), signedCancel(22)}
+	results, err := c.SendTxBatchAndWait(ctx, txs)
+	if !errors.Is(err, ErrTxRejected) {
+		t.Fatalf("expected rejection of the second tx, got %v", err)
+	}
+	for i, r := range results {
+		if r == nil || r.TxHash != txs[i].GetTxHash() {
+			t.Fatalf("result %d not matched to its tx: %+v", i, r)
+		}
+	}
+	if !results[0].Success || results[1].Success || !results[2].Success {
+		t.Errorf("unexpected results: %+v %+v %+v", results[0], results[1], results[2])
+	}
+
+	successes, failures := m.acks()
+	if len(successes) != 2 || len(failures) != 1 || failures[0] != 21 {
+		t.Errorf("unexpected acks: successes %v, failures %v", successes, failures)
+	}
+}
+
+func TestSendTxAndWait_ContextExpires(t *testing.T) {
+	m := &mockAckManager{}
+	c := connectTxClient(t, newTxServer(t, nil, true), m)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := c.SendTxAndWait(ctx, signedCancel(30)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	// The outcome is unknown, so the nonce is not acknowledged
+	if successes, failures := m.acks(); len(successes)+len(failures) != 0 {
+		t.Errorf("expected no acks, got successes %v, failures %v", successes, failures)
+	}
+	if n := len(c.(*wsClient).txWaiters.order); n != 0 {
+		t.Errorf("expected no pending waiters, got %d", n)
+	}
+}
+
+func TestSendTxAndWait_Unsigned(t *testing.T) {
+	c := connectTxClient(t, newTxServer(t, nil, false), &mockAckManager{})
+
+	if _, err := c.SendTxAndWait(context.Background(), &txtypes.L2CancelOrderTxInfo{}); !errors.Is(err, ErrTxNotSigned) {
+		t.Errorf("expected ErrTxNotSigned, got %v", err)
+	}
+}
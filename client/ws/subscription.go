@@ -3,6 +3,7 @@ package ws
 import (
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -45,8 +46,10 @@ type subscription struct {
 	channel      string // full channel path (e.g., "order_book/0", "account_all/123")
 	identifier   string // market index, account index, etc.
 	active       bool
+	restoring    bool   // being resubscribed after a reconnect
 	authToken    string // for private channels
 	subscribedAt time.Time
+	lastMessage  atomic.Int64 // unix nanos of the last message on the channel
 }
 
 type subscriptionManager struct {
@@ -295,7 +298,9 @@ func (sm *subscriptionManager) ConfirmSubscription(key string, err error) {
 	if err == nil {
 		if sub, exists := sm.subscriptions[key]; exists {
 			sub.active = true
+			sub.restoring = false
 			sub.subscribedAt = time.Now()
+			sub.lastMessage.Store(sub.subscribedAt.UnixNano())
 		}
 	} else {
 		delete(sm.subscriptions, key)
@@ -316,6 +321,64 @@ func (sm *subscriptionManager) GetAll() []*subscription {
 	return result
 }
 
+// resubscription is a subscription being restored on a new connection
+type resubscription struct {
+	key     string
+	sub     *subscription
+	confirm chan error
+}
+
+// PrepareResubscribe marks the active subscriptions, and those still being restored after an
+// earlier reconnect, as awaiting confirmation, and returns them with their confirmation channels
+func (sm *subscriptionManager) PrepareResubscribe() []resubscription {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	result := make([]resubscription, 0, len(sm.subscriptions))
+	for key, sub := range sm.subscriptions {
+		if !sub.active && !sub.restoring {
+			continue
+		}
+		sub.active = false
+		sub.restoring = true
+
+		confirmChan := make(chan error, 1)
+		sm.pending[key] = confirmChan
+		result = append(result, resubscription{key: key, sub: sub, confirm: confirmChan})
+	}
+	return result
+}
+
+// Touch records a message received on the subscription with the given key, if any
+func (sm *subscriptionManager) Touch(key string, now time.Time) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if sub, exists := sm.subscriptions[key]; exists {
+		sub.lastMessage.Store(now.UnixNano())
+	}
+}
+
+// Silent returns the active subscription that has been quiet the longest past its
+// timeout, if any. Channel types without a timeout are not watched.
+func (sm *subscriptionManager) Silent(now time.Time, timeouts map[ChannelType]time.Duration) *SilenceError {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	var worst *SilenceError
+	for key, sub := range sm.subscriptions {
+		timeout := timeouts[sub.channelType]
+		if !sub.active || timeout <= 0 {
+			continue
+		}
+		silence := now.Sub(time.Unix(0, sub.lastMessage.Load()))
+		if silence > timeout && (worst == nil || silence > worst.Silence) {
+			worst = &SilenceError{Key: key, Silence: silence}
+		}
+	}
+	return worst
+}
+
 // IsSubscribed checks if a subscription exists and is active
 func (sm *subscriptionManager) IsSubscribed(key string) bool {
 	sm.mu.RLock()
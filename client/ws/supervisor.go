@@ -0,0 +1,240 @@
+package ws
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// ConnectionState is the state of the client's connection
+type ConnectionState int32
+
+const (
+	StateDisconnected  ConnectionState = iota // Not connected; Run reconnects if it is supervising
+	StateConnecting                           // Dialing and waiting for the server's connected message
+	StateConnected                            // Connected and subscribed
+	StateResubscribing                        // Reconnected, waiting for subscriptions to be confirmed
+	StateClosed                               // Closed by Close; the client does not reconnect
+)
+
+// String returns the name of the state
+func (s ConnectionState) String() string {
+	switch s {
+	case StateDisconnected:
+		return "disconnected"
+	case StateConnecting:
+		return "connecting"
+	case StateConnected:
+		return "connected"
+	case StateResubscribing:
+		return "resubscribing"
+	case StateClosed:
+		return "closed"
+	}
+	return fmt.Sprintf("ConnectionState(%d)", int32(s))
+}
+
+// ResubscribeResult reports the subscriptions restored after a reconnect
+type ResubscribeResult struct {
+	Attempt   int              // Reconnect attempt that succeeded
+	Confirmed []string         // Subscription keys confirmed by the server
+	Failed    map[string]error // Subscription keys that were rejected or timed out; they are dropped
+}
+
+// State returns the connection state
+func (c *wsClient) State() ConnectionState {
+	return ConnectionState(c.state.Load())
+}
+
+// setState moves to a new state; nothing leaves StateClosed except a new Connect
+func (c *wsClient) setState(s ConnectionState) {
+	for {
+		old := c.state.Load()
+		if old == int32(StateClosed) && s != StateConnecting {
+			return
+		}
+		if c.state.CompareAndSwap(old, int32(s)) {
+			return
+		}
+	}
+}
+
+// connLost returns the channel closed when the current connection's read loop exits
+func (c *wsClient) connLost() <-chan struct{} {
+	c.connMu.RLock()
+	defer c.connMu.RUnlock()
+	return c.lost
+}
+
+// supervise re-establishes lost connections until ctx is cancelled or the client is closed
+func (c *wsClient) supervise(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return c.Close()
+		case <-c.connLost():
+		}
+
+		if c.State() == StateClosed {
+			return nil
+		}
+		c.teardown() //nolint:errcheck // The connection is already lost
+
+		if err := c.reconnect(ctx); err != nil {
+			return err
+		}
+		if c.State() == StateClosed {
+			return nil
+		}
+	}
+}
+
+// reconnect dials until a connection is established, then restores the subscriptions
+func (c *wsClient) reconnect(ctx context.Context) error {
+	for attempt := 1; ; attempt++ {
+		if c.options.MaxReconnectAttempts > 0 && attempt > c.options.MaxReconnectAttempts {
+			c.setState(StateDisconnected)
+			return ErrMaxReconnectAttemptsExceeded
+		}
+
+		select {
+		case <-ctx.Done():
+			c.Close() //nolint:errcheck // Returning the context error
+			return ctx.Err()
+		case <-time.After(c.backoff(attempt)):
+		}
+
+		if err := c.connect(ctx, true); err != nil {
+			if c.State() == StateClosed {
+				return nil
+			}
+			c.sendError(fmt.Errorf("reconnect attempt %d: %w", attempt, err))
+			continue
+		}
+
+		if c.options.OnReconnect != nil {
+			c.options.OnReconnect(attempt)
+		}
+		c.resubscribeAll(attempt)
+		return nil
+	}
+}
+
+// backoff returns the delay before the given reconnect attempt (1-based)
+func (c *wsClient) backoff(attempt int) time.Duration {
+	d := float64(c.options.ReconnectDelay)
+	for i := 1; i < attempt && d < float64(c.options.MaxReconnectDelay); i++ {
+		d *= 2
+	}
+	if c.options.MaxReconnectDelay > 0 && d > float64(c.options.MaxReconnectDelay) {
+		d = float64(c.options.MaxReconnectDelay)
+	}
+	if j := c.options.ReconnectJitter; j > 0 {
+		d += d * j * (2*rand.Float64() - 1)
+	}
+	return time.Duration(d)
+}
+
+// resubscribeAll restores the subscriptions of the lost connection and waits for each confirmation
+func (c *wsClient) resubscribeAll(attempt int) {
+	c.setState(StateResubscribing)
+
+	result := &ResubscribeResult{Attempt: attempt, Failed: make(map[string]error)}
+	lost := c.connLost()
+
+	// If the connection is lost again, the remaining subscriptions stay
+	// marked as restoring and are retried after the next reconnect
+	subs := c.subscriptions.PrepareResubscribe()
+	for _, r := range subs {
+		token, err := c.renewAuthToken(r.sub)
+		if err == nil {
+			err = c.subscribe(r.sub.channel, token)
+		}
+		if err != nil {
+			if isClosed(lost) {
+				return
+			}
+			c.subscriptions.Remove(r.key) //nolint:errcheck // Reported as failed
+			result.Failed[r.key] = err
+		}
+	}
+
+	timeout := time.NewTimer(c.options.ResubscribeTimeout)
+	defer timeout.Stop()
+	expired := false
+
+	for _, r := range subs {
+		if _, failed := result.Failed[r.key]; failed {
+			continue
+		}
+		if expired {
+			select {
+			case err := <-r.confirm:
+				c.settleResubscription(result, r.key, err)
+			default:
+				c.subscriptions.Remove(r.key) //nolint:errcheck // Reported as failed
+				result.Failed[r.key] = ErrSubscriptionTimeout
+			}
+			continue
+		}
+		select {
+		case err := <-r.confirm:
+			c.settleResubscription(result, r.key, err)
+		case <-timeout.C:
+			expired = true
+			c.subscriptions.Remove(r.key) //nolint:errcheck // Reported as failed
+			result.Failed[r.key] = ErrSubscriptionTimeout
+		case <-lost:
+			return
+		}
+	}
+
+	for key, err := range result.Failed {
+		c.sendError(fmt.Errorf("resubscribe %s: %w", key, err))
+	}
+	c.state.CompareAndSwap(int32(StateResubscribing), int32(StateConnected))
+
+	if c.options.OnResubscribed != nil {
+		c.options.OnResubscribed(result)
+	}
+}
+
+// renewAuthToken returns the token to restore a subscription with: a fresh one from
+// the auth provider for private channels, since the original may have expired
+func (c *wsClient) renewAuthToken(sub *subscription) (string, error) {
+	if !sub.channelType.IsPrivate() || c.options.AuthProvider == nil {
+		return sub.authToken, nil
+	}
+	token, err := c.options.AuthProvider.Token()
+	if err != nil {
+		return "", fmt.Errorf("auth token: %w", err)
+	}
+	return token, nil
+}
+
+func (c *wsClient) settleResubscription(result *ResubscribeResult, key string, err error) {
+	if err != nil {
+		result.Failed[key] = err
+		return
+	}
+	result.Confirmed = append(result.Confirmed, key)
+}
+
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+// invalidateOrderBooks drops the order book states, which no longer follow the server
+func (c *wsClient) invalidateOrderBooks() {
+	c.orderBookMu.Lock()
+	defer c.orderBookMu.Unlock()
+
+	c.orderBooks = make(map[int16]*OrderBookState)
+	c.bookStreams = make(map[int16]*bookStream)
+}
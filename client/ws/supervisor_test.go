@@ -0,0 +1,339 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/coder/websocket"
+
+	"github.com/0xJord4n/lighter-go/ratelimit"
+)
+
+// flakyServer confirms subscriptions and can drop its connections
+type flakyServer struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	conns  []*websocket.Conn
+	dials  int
+	reject map[string]bool // Channels rejected from the second connection on
+	refuse bool            // Refuse new connections
+	auths  []string        // Auth tokens of private subscriptions, in order
+}
+
+func newFlakyServer(t *testing.T) *flakyServer {
+	t.Helper()
+	s := &flakyServer{reject: make(map[string]bool)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *flakyServer) url() string {
+	return "ws" + strings.TrimPrefix(s.URL, "http")
+}
+
+func (s *flakyServer) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	refuse := s.refuse
+	s.mu.Unlock()
+	if refuse {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.CloseNow()
+
+	s.mu.Lock()
+	s.conns = append(s.conns, conn)
+	s.dials++
+	dial := s.dials
+	s.mu.Unlock()
+
+	ctx := r.Context()
+	conn.Write(ctx, websocket.MessageText, []byte(`{"type":"connected"}`))
+
+	for {
+		_, msg, err := conn.Read(ctx)
+		if err != nil {
+			return
+		}
+		var req SubscribeRequest
+		if err := sonic.Unmarshal(msg, &req); err != nil || req.Type != "subscribe" {
+			continue
+		}
+		channel := strings.Replace(req.Channel, "/", ":", 1)
+
+		s.mu.Lock()
+		rejected := dial > 1 && s.reject[channel]
+		if req.Auth != "" {
+			s.auths = append(s.auths, req.Auth)
+		}
+		s.mu.Unlock()
+
+		var reply string
+		switch {
+		case rejected:
+			reply = fmt.Sprintf(`{"type":"error","data":{"code":30003,"message":"rejected","channel":%q}}`, channel)
+		case strings.HasPrefix(channel, "order_book:"):
+			reply = fmt.Sprintf(`{"type":"subscribed/order_book","channel":%q,"order_book":{"offset":%d,"bids":[{"price":"100","size":"%d"}],"asks":[]}}`,
+				channel, dial*100, dial)
+		case strings.HasPrefix(channel, "trade:"):
+			reply = fmt.Sprintf(`{"type":"subscribed/trade","channel":%q}`, channel)
+		case strings.HasPrefix(channel, "account_all:"):
+			reply = fmt.Sprintf(`{"type":"subscribed/account_all","channel":%q}`, channel)
+		}
+		conn.Write(ctx, websocket.MessageText, []byte(reply))
+	}
+}
+
+// drop closes every open connection
+func (s *flakyServer) drop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conn := range s.conns {
+		conn.CloseNow()
+	}
+	s.conns = nil
+}
+
+func (s *flakyServer) authTokens() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.auths...)
+}
+
+func (s *flakyServer) dialCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dials
+}
+
+func supervisorOptions() *Options {
+	return DefaultOptions().
+		WithReconnectDelay(10 * time.Millisecond).
+		WithMaxReconnectDelay(50 * time.Millisecond).
+		WithResubscribeTimeout(time.Second)
+}
+
+func runClient(t *testing.T, c Client) chan error {
+	t.Helper()
+	runErr := make(chan error, 1)
+	go func() { runErr <- c.Run(context.Background()) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !c.IsConnected() {
+		if time.Now().After(deadline) {
+			t.Fatal("client did not connect")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return runErr
+}
+
+func TestSupervisor_ReconnectAndResubscribe(t *testing.T) {
+	srv := newFlakyServer(t)
+	srv.reject["trade:0"] = true
+
+	var bookOnReconnect error
+	var c Client
+	resubscribed := make(chan *ResubscribeResult, 1)
+	opts := supervisorOptions().
+		WithOnReconnect(func(attempt int) {
+			_, bookOnReconnect = c.GetOrderBookState(0)
+		}).
+		WithOnResubscribed(func(r *ResubscribeResult) { resubscribed <- r })
+	c = NewClient(srv.url(), opts)
+	runErr := runClient(t, c)
+
+	if err := c.SubscribeOrderBook(0); err != nil {
+		t.Fatalf("subscribe order book: %v", err)
+	}
+	if err := c.SubscribeTrades(0); err != nil {
+		t.Fatalf("subscribe trades: %v", err)
+	}
+
+	srv.drop()
+
+	var r *ResubscribeResult
+	select {
+	case r = <-resubscribed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for resubscription")
+	}
+
+	if !errors.Is(bookOnReconnect, ErrOrderBookNotFound) {
+		t.Errorf("expected the book to be invalidated on disconnect, got %v", bookOnReconnect)
+	}
+	if len(r.Confirmed) != 1 || r.Confirmed[0] != orderBookKey(0) {
+		t.Errorf("expected order book to be confirmed, got %v", r.Confirmed)
+	}
+	var wsErr *WsError
+	if err := r.Failed[tradeKey(0)]; !errors.As(err, &wsErr) {
+		t.Errorf("expected trade resubscription to be rejected, got %v", err)
+	}
+	if c.State() != StateConnected {
+		t.Errorf("expected connected state, got %s", c.State())
+	}
+
+	// The book is rebuilt from the new connection's snapshot
+	state, err := c.GetOrderBookState(0)
+	if err != nil {
+		t.Fatalf("expected book after resubscribe: %v", err)
+	}
+	if bid, _ := state.Bid("100"); bid.Size != "2" {
+		t.Errorf("expected size from the new snapshot, got %s", bid.Size)
+	}
+
+	ws := c.(*wsClient)
+	if !ws.subscriptions.IsSubscribed(orderBookKey(0)) || ws.subscriptions.IsSubscribed(tradeKey(0)) {
+		t.Error("expected only the confirmed subscription to remain")
+	}
+
+	if err := c.Close(); err != nil {
+		t.Logf("close: %v", err)
+	}
+	select {
+	case err := <-runErr:
+		if err != nil {
+			t.Errorf("expected Run to return nil after Close, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after Close")
+	}
+	if c.State() != StateClosed {
+		t.Errorf("expected closed state, got %s", c.State())
+	}
+	if n := srv.dialCount(); n != 2 {
+		t.Errorf("expected no reconnect after Close, got %d dials", n)
+	}
+}
+
+// countingProvider hands out a new token on each call
+type countingProvider struct {
+	mu sync.Mutex
+	n  int
+}
+
+func (p *countingProvider) Token() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.n++
+	return fmt.Sprintf("token-%d", p.n), nil
+}
+
+func TestSupervisor_RenewsAuthToken(t *testing.T) {
+	srv := newFlakyServer(t)
+	resubscribed := make(chan *ResubscribeResult, 1)
+	opts := supervisorOptions().
+		WithAuthProvider(&countingProvider{}).
+		WithOnResubscribed(func(r *ResubscribeResult) { resubscribed <- r })
+	c := NewClient(srv.url(), opts)
+	runClient(t, c)
+	defer c.Close()
+
+	if err := c.SubscribeAccountAll(42, ""); err != nil {
+		t.Fatalf("subscribe account: %v", err)
+	}
+
+	srv.drop()
+	select {
+	case r := <-resubscribed:
+		if len(r.Failed) != 0 {
+			t.Fatalf("unexpected failures: %v", r.Failed)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for resubscription")
+	}
+
+	auths := srv.authTokens()
+	if len(auths) != 2 || auths[0] != "token-1" || auths[1] != "token-2" {
+		t.Errorf("expected a fresh token on resubscribe, got %v", auths)
+	}
+}
+
+func TestSupervisor_MaxReconnectAttempts(t *testing.T) {
+	srv := newFlakyServer(t)
+	c := NewClient(srv.url(), supervisorOptions().WithMaxReconnectAttempts(2))
+	runErr := runClient(t, c)
+
+	// Refuse new connections and drop the current one
+	srv.mu.Lock()
+	srv.refuse = true
+	srv.mu.Unlock()
+	srv.drop()
+
+	select {
+	case err := <-runErr:
+		if !errors.Is(err, ErrMaxReconnectAttemptsExceeded) {
+			t.Errorf("expected ErrMaxReconnectAttemptsExceeded, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not give up")
+	}
+	if c.State() != StateDisconnected {
+		t.Errorf("expected disconnected state, got %s", c.State())
+	}
+}
+
+func TestSupervisor_Backoff(t *testing.T) {
+	c := NewClient("", DefaultOptions().
+		WithReconnectDelay(100*time.Millisecond).
+		WithMaxReconnectDelay(time.Second)).(*wsClient)
+
+	for attempt, base := range map[int]time.Duration{1: 100 * time.Millisecond, 3: 400 * time.Millisecond, 10: time.Second} {
+		for i := 0; i < 20; i++ {
+			d := c.backoff(attempt)
+			if d < base*8/10 || d > base*12/10 {
+				t.Errorf("attempt %d: delay %v outside %v ±20%%", attempt, d, base)
+			}
+		}
+	}
+}
+
+func TestSupervisor_RateLimitedSendDuringReconnect(t *testing.T) {
+	srv := newFlakyServer(t)
+	limiter := ratelimit.New(ratelimit.DefaultConfig())
+	c := NewClient(srv.url(), supervisorOptions().WithRateLimiter(limiter, 1))
+	if err := c.SendTx(map[string]string{}); !errors.Is(err, ErrNotConnected) {
+		t.Errorf("expected ErrNotConnected before Connect, got %v", err)
+	}
+	runErr := runClient(t, c)
+
+	// Sends read the connection's context while reconnects replace it
+	stop := make(chan struct{})
+	sent := make(chan struct{})
+	go func() {
+		defer close(sent)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			c.SendTx(map[string]string{}) //nolint:errcheck // Fails while disconnected
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	for i := 0; i < 3; i++ {
+		srv.drop()
+		time.Sleep(30 * time.Millisecond)
+	}
+	close(stop)
+	<-sent
+
+	c.Close() //nolint:errcheck
+	<-runErr
+}
@@ -139,7 +139,8 @@ type OrderBookUpdate struct {
 	IsSnapshot  bool
 	Snapshot    *OrderBookSnapshot
 	Delta       *OrderBookDelta
-	State       *OrderBookState // Current merged state
+	State       *OrderBookState  // Current merged state
+	Resync      *OrderBookResync // Set when the update completes a resync
 }
 
 // Trade represents a single trade
@@ -0,0 +1,137 @@
+// Command lighter-signer holds an API private key in its own process and signs the
+// transactions of trading processes that pass its policy. Clients connect with
+// remote.NewKeyManager.
+//
+// Usage:
+//
+//	openssl rand -hex 32 > signer.secret
+//	lighter-signer -listen unix:///run/lighter-signer.sock \
+//	    -key-file api.key -account-index 42 -api-key-index 3 \
+//	    -secret-file signer.secret -policy policy.json -network mainnet
+//
+// The key file holds the hex-encoded API private key; LIGHTER_SIGNER_KEY can be
+// used instead. The secret file is shared with the clients.
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/0xJord4n/lighter-go/signer"
+	"github.com/0xJord4n/lighter-go/signer/remote"
+	"github.com/0xJord4n/lighter-go/types"
+)
+
+func main() {
+	listen := flag.String("listen", "unix:///tmp/lighter-signer.sock", "unix:///path/to/socket or host:port")
+	keyFile := flag.String("key-file", "", "file with the hex-encoded API private key (default: $LIGHTER_SIGNER_KEY)")
+	accountIndex := flag.Int64("account-index", -1, "account index of the API key")
+	apiKeyIndex := flag.Int("api-key-index", -1, "index of the API key")
+	secretFile := flag.String("secret-file", "", "file with the secret shared with clients, at least 32 bytes")
+	policyFile := flag.String("policy", "", "JSON policy file")
+	network := flag.String("network", "mainnet", "mainnet or testnet")
+	chainId := flag.Uint("chain-id", 0, "chain ID, overrides -network")
+	flag.Parse()
+
+	logger := log.New(os.Stderr, "lighter-signer: ", log.LstdFlags)
+	if err := run(logger, *listen, *keyFile, *accountIndex, *apiKeyIndex, *secretFile, *policyFile, *network, uint32(*chainId)); err != nil {
+		logger.Fatal(err)
+	}
+}
+
+func run(logger *log.Logger, listen, keyFile string, accountIndex int64, apiKeyIndex int, secretFile, policyFile, network string, chainId uint32) error {
+	key, err := loadKey(keyFile)
+	if err != nil {
+		return err
+	}
+	if accountIndex < 0 {
+		return errors.New("-account-index is required")
+	}
+	if apiKeyIndex < 0 || apiKeyIndex > 255 {
+		return errors.New("-api-key-index is required, from 0 to 255")
+	}
+	if secretFile == "" {
+		return errors.New("-secret-file is required")
+	}
+	secret, err := os.ReadFile(secretFile)
+	if err != nil {
+		return fmt.Errorf("failed to read secret: %w", err)
+	}
+	if policyFile == "" {
+		return errors.New("-policy is required")
+	}
+	policy, err := remote.LoadPolicy(policyFile)
+	if err != nil {
+		return err
+	}
+	if chainId == 0 {
+		switch network {
+		case "mainnet":
+			chainId = types.Mainnet.ChainID()
+		case "testnet":
+			chainId = types.Testnet.ChainID()
+		default:
+			return fmt.Errorf("unknown network %q", network)
+		}
+	}
+
+	server, err := remote.NewServer(key, chainId, accountIndex, uint8(apiKeyIndex), []byte(strings.TrimSpace(string(secret))), policy)
+	if err != nil {
+		return err
+	}
+	server.SetLogger(logger)
+
+	listener, err := remote.Listen(listen)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{Handler: server, ReadHeaderTimeout: 5 * time.Second}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx) //nolint:errcheck // Exiting
+	}()
+
+	pk := key.PubKeyBytes()
+	logger.Printf("serving public key %s of account %d api key %d for chain %d on %s", hex.EncodeToString(pk[:]), accountIndex, apiKeyIndex, chainId, listen)
+	if err := srv.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// loadKey reads the hex-encoded private key from keyFile or $LIGHTER_SIGNER_KEY
+func loadKey(keyFile string) (signer.KeyManager, error) {
+	keyHex := os.Getenv("LIGHTER_SIGNER_KEY")
+	if keyFile != "" {
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key: %w", err)
+		}
+		keyHex = string(data)
+	}
+	keyHex = strings.TrimPrefix(strings.TrimSpace(keyHex), "0x")
+	if keyHex == "" {
+		return nil, errors.New("no private key: set -key-file or LIGHTER_SIGNER_KEY")
+	}
+
+	b, err := hex.DecodeString(keyHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	return signer.NewKeyManager(b)
+}
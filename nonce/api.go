@@ -21,6 +21,11 @@ func (m *APIManager) GetNonce(accountIndex int64, apiKeyIndex uint8) (int64, err
 	return m.fetcher.GetNextNonce(accountIndex, apiKeyIndex)
 }
 
+// GetNonceWithFetcher fetches the next nonce with fetcher instead of the manager's own.
+func (m *APIManager) GetNonceWithFetcher(fetcher NonceFetcher, accountIndex int64, apiKeyIndex uint8) (int64, error) {
+	return fetcher.GetNextNonce(accountIndex, apiKeyIndex)
+}
+
 // AcknowledgeSuccess is a no-op for APIManager since it always queries the API.
 func (m *APIManager) AcknowledgeSuccess(accountIndex int64, apiKeyIndex uint8, nonce int64) {
 	// No-op: API manager always fetches fresh nonce
@@ -42,4 +47,7 @@ func (m *APIManager) ResetAll() {
 }
 
 // Ensure APIManager implements Manager
-var _ Manager = (*APIManager)(nil)
+var (
+	_ Manager          = (*APIManager)(nil)
+	_ FetcherOverrider = (*APIManager)(nil)
+)
@@ -0,0 +1,268 @@
+package nonce
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// DefaultCompactThreshold is the number of journal entries after which a FileStore compacts
+const DefaultCompactThreshold = 4096
+
+// ErrStoreClosed is returned when using a closed Store
+var ErrStoreClosed = errors.New("nonce store closed")
+
+// FileStore is a Store backed by an append-only journal file.
+// Every change is appended as a JSON line and fsynced before returning.
+// Once the journal grows past the compaction threshold, it is rewritten
+// atomically with the minimal set of entries describing the current state.
+type FileStore struct {
+	mu               sync.Mutex
+	path             string
+	file             *os.File
+	states           map[nonceKey]*keyState
+	entries          int // Journal entries
+	compacted        int // Journal entries written by the last compaction
+	compactThreshold int
+}
+
+// OpenFileStore opens the journal at path, creating it if it doesn't exist.
+// A trailing entry torn by a crash is discarded.
+func OpenFileStore(path string) (*FileStore, error) {
+	s := &FileStore{
+		path:             path,
+		states:           make(map[nonceKey]*keyState),
+		compactThreshold: DefaultCompactThreshold,
+	}
+
+	valid, err := s.replay()
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open nonce journal: %w", err)
+	}
+	// Drop a torn trailing entry, so that new entries start on a fresh line
+	if err := file.Truncate(valid); err != nil {
+		file.Close() //nolint:errcheck // Already failing
+		return nil, fmt.Errorf("failed to truncate nonce journal: %w", err)
+	}
+	if _, err := file.Seek(valid, io.SeekStart); err != nil {
+		file.Close() //nolint:errcheck // Already failing
+		return nil, fmt.Errorf("failed to seek nonce journal: %w", err)
+	}
+	s.file = file
+	return s, nil
+}
+
+// replay rebuilds the state from the journal and returns the length of its valid prefix
+func (s *FileStore) replay() (int64, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read nonce journal: %w", err)
+	}
+
+	var valid int64
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		end := valid + int64(len(line)) + 1
+		if end > int64(len(data)) {
+			// Last line without newline: the write was interrupted
+			break
+		}
+
+		var rec storeRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			if end == int64(len(data)) {
+				break
+			}
+			return 0, fmt.Errorf("corrupt nonce journal at offset %d: %w", valid, err)
+		}
+		applyRecord(s.states, rec)
+		s.entries++
+		valid = end
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read nonce journal: %w", err)
+	}
+	return valid, nil
+}
+
+// SetCompactThreshold sets how many journal entries are appended between compactions, 0 to disable
+func (s *FileStore) SetCompactThreshold(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.compactThreshold = n
+}
+
+// Load returns the persisted state of all account/key pairs
+func (s *FileStore) Load() ([]State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return exportStates(s.states), nil
+}
+
+// Allocate records that nonce was handed out
+func (s *FileStore) Allocate(accountIndex int64, apiKeyIndex uint8, nonce int64) error {
+	return s.append(storeRecord{Op: opAllocate, AccountIndex: accountIndex, ApiKeyIndex: apiKeyIndex, Nonce: nonce})
+}
+
+// Release records that nonce is no longer pending
+func (s *FileStore) Release(accountIndex int64, apiKeyIndex uint8, nonce int64) error {
+	return s.append(storeRecord{Op: opRelease, AccountIndex: accountIndex, ApiKeyIndex: apiKeyIndex, Nonce: nonce})
+}
+
+// SetTxHash records the hash of the transaction signed with a pending nonce
+func (s *FileStore) SetTxHash(accountIndex int64, apiKeyIndex uint8, nonce int64, txHash string) error {
+	return s.append(storeRecord{Op: opTxHash, AccountIndex: accountIndex, ApiKeyIndex: apiKeyIndex, Nonce: nonce, TxHash: txHash})
+}
+
+// SetNext records the next nonce to hand out
+func (s *FileStore) SetNext(accountIndex int64, apiKeyIndex uint8, next int64) error {
+	return s.append(storeRecord{Op: opSetNext, AccountIndex: accountIndex, ApiKeyIndex: apiKeyIndex, Nonce: next})
+}
+
+// Reset forgets all state of an account/key pair
+func (s *FileStore) Reset(accountIndex int64, apiKeyIndex uint8) error {
+	return s.append(storeRecord{Op: opReset, AccountIndex: accountIndex, ApiKeyIndex: apiKeyIndex})
+}
+
+// ResetAll forgets all state
+func (s *FileStore) ResetAll() error {
+	return s.append(storeRecord{Op: opResetAll})
+}
+
+// Close closes the journal file
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// Compact rewrites the journal with the minimal set of entries describing the current state
+func (s *FileStore) Compact() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.compact()
+}
+
+func (s *FileStore) append(rec storeRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return ErrStoreClosed
+	}
+
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	// A failed write may leave a partial entry behind; stop writing so that
+	// the journal keeps a valid prefix that can be replayed on restart
+	if _, err := s.file.Write(line); err != nil {
+		s.closeFile()
+		return fmt.Errorf("failed to write nonce journal: %w", err)
+	}
+	if err := s.file.Sync(); err != nil {
+		s.closeFile()
+		return fmt.Errorf("failed to sync nonce journal: %w", err)
+	}
+
+	applyRecord(s.states, rec)
+	s.entries++
+
+	if s.compactThreshold > 0 && s.entries-s.compacted >= s.compactThreshold {
+		return s.compact()
+	}
+	return nil
+}
+
+// compact writes a snapshot to a temporary file and atomically replaces the journal with it
+func (s *FileStore) compact() error {
+	if s.file == nil {
+		return ErrStoreClosed
+	}
+
+	recs := snapshot(s.states)
+	var buf bytes.Buffer
+	for _, rec := range recs {
+		line, err := json.Marshal(rec)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	tmpPath := s.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create compacted nonce journal: %w", err)
+	}
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close() //nolint:errcheck // Already failing
+		return fmt.Errorf("failed to write compacted nonce journal: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close() //nolint:errcheck // Already failing
+		return fmt.Errorf("failed to sync compacted nonce journal: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close compacted nonce journal: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("failed to replace nonce journal: %w", err)
+	}
+	syncDir(filepath.Dir(s.path))
+
+	file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to reopen nonce journal: %w", err)
+	}
+	s.file.Close() //nolint:errcheck // The old journal was replaced
+	s.file = file
+	s.entries = len(recs)
+	s.compacted = len(recs)
+	return nil
+}
+
+func (s *FileStore) closeFile() {
+	s.file.Close() //nolint:errcheck // Already failing
+	s.file = nil
+}
+
+// syncDir makes a rename in dir durable. Not all platforms support it, so errors are ignored.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	d.Sync()  //nolint:errcheck // Best effort
+	d.Close() //nolint:errcheck // Read-only handle
+}
+
+// Ensure FileStore implements Store
+var (
+	_ Store       = (*FileStore)(nil)
+	_ TxHashStore = (*FileStore)(nil)
+)
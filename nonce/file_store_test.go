@@ -0,0 +1,262 @@
+package nonce
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/0xJord4n/lighter-go/types/api"
+)
+
+// mockTxFetcher is a mock implementation of TxFetcher for testing.
+// GetTx finds the hashes in known, fails for those in failing and reports the others as not found.
+type mockTxFetcher struct {
+	txs     []api.Tx
+	known   map[string]bool
+	failing map[string]bool
+}
+
+func (m *mockTxFetcher) GetAccountTxs(by api.QueryBy, value string, limit int, types []api.TxType) (*api.Txs, error) {
+	return &api.Txs{Txs: m.txs}, nil
+}
+
+func (m *mockTxFetcher) GetTx(by api.QueryBy, value string) (*api.EnrichedTx, error) {
+	switch {
+	case m.known[value]:
+		return &api.EnrichedTx{}, nil
+	case m.failing[value]:
+		return nil, errors.New("internal server error")
+	}
+	return nil, notFoundError{}
+}
+
+// notFoundError mimics the not found answer of http.APIError
+type notFoundError struct{}
+
+func (notFoundError) Error() string    { return "not found" }
+func (notFoundError) IsNotFound() bool { return true }
+
+func openTestStore(t *testing.T, path string) *FileStore {
+	t.Helper()
+	store, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("OpenFileStore failed: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestFileStore_PersistsAcrossRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nonces.journal")
+	fetcher := newMockFetcher()
+	fetcher.setNonce(1, 0, 100)
+
+	store := openTestStore(t, path)
+	manager, err := NewOptimisticManagerWithStore(fetcher, store)
+	if err != nil {
+		t.Fatalf("NewOptimisticManagerWithStore failed: %v", err)
+	}
+	n1, _ := manager.GetNonce(1, 0)
+	n2, _ := manager.GetNonce(1, 0)
+	manager.AcknowledgeSuccess(1, 0, n1)
+	store.Close()
+
+	// Restart
+	restarted, err := NewOptimisticManagerWithStore(fetcher, openTestStore(t, path))
+	if err != nil {
+		t.Fatalf("NewOptimisticManagerWithStore failed: %v", err)
+	}
+	if restarted.PendingCount(1, 0) != 1 {
+		t.Errorf("expected 1 pending nonce, got %d", restarted.PendingCount(1, 0))
+	}
+
+	calls := fetcher.getCalls()
+	n3, err := restarted.GetNonce(1, 0)
+	if err != nil {
+		t.Fatalf("GetNonce failed: %v", err)
+	}
+	if n3 != n2+1 {
+		t.Errorf("expected nonce %d after restart, got %d", n2+1, n3)
+	}
+	if fetcher.getCalls() != calls {
+		t.Error("expected restored nonce to be used without fetching")
+	}
+}
+
+func TestFileStore_DiscardsTornEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nonces.journal")
+
+	store := openTestStore(t, path)
+	if err := store.Allocate(1, 0, 100); err != nil {
+		t.Fatalf("Allocate failed: %v", err)
+	}
+	store.Close()
+
+	// Simulate a crash in the middle of a write
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	f.WriteString(`{"op":"alloc","account_index":1,"no`)
+	f.Close()
+
+	store = openTestStore(t, path)
+	if err := store.Allocate(1, 0, 101); err != nil {
+		t.Fatalf("Allocate failed: %v", err)
+	}
+	store.Close()
+
+	states, err := openTestStore(t, path).Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(states) != 1 || states[0].Next != 102 || len(states[0].Pending) != 2 {
+		t.Errorf("unexpected state after torn entry: %+v", states)
+	}
+}
+
+func TestFileStore_Compaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nonces.journal")
+
+	store := openTestStore(t, path)
+	store.SetCompactThreshold(10)
+	for n := int64(0); n < 25; n++ {
+		if err := store.Allocate(1, 0, n); err != nil {
+			t.Fatalf("Allocate failed: %v", err)
+		}
+		if err := store.Release(1, 0, n); err != nil {
+			t.Fatalf("Release failed: %v", err)
+		}
+	}
+	if err := store.Allocate(1, 0, 25); err != nil {
+		t.Fatalf("Allocate failed: %v", err)
+	}
+	if err := store.SetTxHash(1, 0, 25, "0xabc"); err != nil {
+		t.Fatalf("SetTxHash failed: %v", err)
+	}
+	if err := store.Compact(); err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+	store.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read journal: %v", err)
+	}
+	if lines := countLines(data); lines != 3 {
+		t.Errorf("expected 3 journal entries after compaction, got %d", lines)
+	}
+
+	states, _ := openTestStore(t, path).Load()
+	if len(states) != 1 || states[0].Next != 26 || len(states[0].Pending) != 1 || states[0].Pending[0] != 25 || states[0].TxHashes[25] != "0xabc" {
+		t.Errorf("unexpected state after compaction: %+v", states)
+	}
+}
+
+func TestOptimisticManager_Recover(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nonces.journal")
+	fetcher := newMockFetcher()
+	fetcher.setNonce(1, 0, 100)
+
+	manager, _ := NewOptimisticManagerWithStore(fetcher, openTestStore(t, path))
+	for i := 0; i < 5; i++ {
+		manager.GetNonce(1, 0) // 100..104 pending
+	}
+
+	// Before the crash, 100 and 101 were executed and 102 is queued on Lighter
+	fetcher.setNonce(1, 0, 102)
+	txs := &mockTxFetcher{txs: []api.Tx{
+		{AccountIndex: 1, ApiKeyIndex: 0, Nonce: 101},
+		{AccountIndex: 1, ApiKeyIndex: 0, Nonce: 102},
+		{AccountIndex: 1, ApiKeyIndex: 3, Nonce: 500},
+	}}
+
+	restarted, _ := NewOptimisticManagerWithStore(fetcher, openTestStore(t, path))
+	report, err := restarted.Recover(txs)
+	if err != nil {
+		t.Fatalf("Recover failed: %v", err)
+	}
+
+	if len(report.Keys) != 1 {
+		t.Fatalf("expected 1 recovered key, got %d", len(report.Keys))
+	}
+
+	// 103 and 104 have no tx hash, so they can't be confirmed absent and are skipped
+	rec := report.Keys[0]
+	if rec.JournalNext != 105 || rec.ServerNext != 102 || rec.Next != 105 {
+		t.Errorf("unexpected recovery: %+v", rec)
+	}
+	if len(rec.Landed) != 3 || len(rec.Lost) != 0 || len(rec.Skipped) != 2 || rec.Skipped[0] != 103 {
+		t.Errorf("unexpected landed/lost/skipped nonces: %+v", rec)
+	}
+
+	nonce, _ := restarted.GetNonce(1, 0)
+	if nonce != 105 {
+		t.Errorf("expected nonce 105 after recovery, got %d", nonce)
+	}
+	if restarted.PendingCount(1, 0) != 1 {
+		t.Errorf("expected 1 pending nonce after recovery, got %d", restarted.PendingCount(1, 0))
+	}
+}
+
+func TestOptimisticManager_RecoverConfirmsLostNonces(t *testing.T) {
+	tests := []struct {
+		name    string
+		txs     *mockTxFetcher
+		next    int64
+		lost    []int64
+		skipped []int64
+	}{
+		{name: "all absent", txs: &mockTxFetcher{}, next: 102, lost: []int64{102, 103, 104}},
+		{name: "lookup fails", txs: &mockTxFetcher{failing: map[string]bool{"0x103": true}}, next: 104, lost: []int64{104}, skipped: []int64{102, 103}},
+		{name: "highest landed", txs: &mockTxFetcher{known: map[string]bool{"0x104": true}}, next: 105, skipped: []int64{102, 103, 104}},
+		{name: "without tx fetcher", next: 105, skipped: []int64{102, 103, 104}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "nonces.journal")
+			fetcher := newMockFetcher()
+			fetcher.setNonce(1, 0, 100)
+
+			manager, _ := NewOptimisticManagerWithStore(fetcher, openTestStore(t, path))
+			for i := 0; i < 5; i++ {
+				n, _ := manager.GetNonce(1, 0) // 100..104 pending
+				manager.TrackTxHash(1, 0, n, fmt.Sprintf("0x%d", n))
+			}
+			// A failure resets the journal's next nonce, the pending nonces are kept
+			manager.AcknowledgeFailure(1, 0, 101)
+
+			fetcher.setNonce(1, 0, 101)
+			restarted, _ := NewOptimisticManagerWithStore(fetcher, openTestStore(t, path))
+			var txs TxFetcher
+			if tt.txs != nil {
+				txs = tt.txs
+			}
+			report, err := restarted.Recover(txs)
+			if err != nil {
+				t.Fatalf("Recover failed: %v", err)
+			}
+
+			rec := report.Keys[0]
+			if rec.JournalNext != -1 || rec.Next != tt.next {
+				t.Errorf("expected next nonce %d, got %+v", tt.next, rec)
+			}
+			if fmt.Sprint(rec.Landed) != "[100]" || fmt.Sprint(rec.Lost) != fmt.Sprint(tt.lost) || fmt.Sprint(rec.Skipped) != fmt.Sprint(tt.skipped) {
+				t.Errorf("expected landed [100], lost %v and skipped %v, got %+v", tt.lost, tt.skipped, rec)
+			}
+		})
+	}
+}
+
+func countLines(data []byte) int {
+	n := 0
+	for _, b := range data {
+		if b == '\n' {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,354 @@
+package nonce
+
+import (
+	"sort"
+	"sync"
+)
+
+// Dependent is a transaction signed with a nonce following a rejected one.
+// Lighter executes nonces in order, so it can only execute once re-signed.
+type Dependent struct {
+	AccountIndex int64
+	ApiKeyIndex  uint8
+	Nonce        int64
+	Tag          any    // Set by GapRepairer.Track, e.g. a client order index
+	TxHash       string // Set by GapRepairer.TrackTxHash, empty if unknown
+}
+
+// StaleLimit is the number of re-sequenced dependents per account/key pair whose late
+// acknowledgments GapRepairer recognizes; beyond it, the lowest nonces are forgotten
+const StaleLimit = 1024
+
+// Resequenced reports the repair of a Dependent
+type Resequenced struct {
+	Dependent
+	NewNonce int64 // Nonce allocated for the re-signed transaction, -1 if none was allocated
+	Err      error // Error of the allocation or returned by the ResignFunc
+}
+
+// GapEvent is emitted when a rejected nonce left a gap before pending nonces
+type GapEvent struct {
+	AccountIndex int64
+	ApiKeyIndex  uint8
+	Nonce        int64         // Rejected nonce
+	Resequenced  []Resequenced // Dependents in ascending nonce order
+}
+
+// ResignFunc re-signs a dependent transaction with a fresh nonce, and typically resubmits it.
+// It is called outside of the GapRepairer's lock, so it may create and submit transactions.
+// If it returns an error, the fresh nonce is acknowledged as failed.
+type ResignFunc func(d Dependent, newNonce int64) error
+
+// GapRepairer wraps a Manager to repair nonce gaps.
+//
+// It tracks the nonces handed out per account/key pair until they are acknowledged.
+// When a nonce N is rejected, every pending nonce after N is signed with a nonce
+// Lighter will never execute. GapRepairer then allocates fresh nonces from the wrapped
+// Manager for these dependents, in order, calls the ResignFunc for each of them, and
+// emits a GapEvent describing which transactions were re-sequenced. The fresh nonces are
+// fetched, if needed, with the fetcher the rejected nonce was allocated with.
+//
+// Dependents that were already submitted are rejected by Lighter as well; the failure
+// acknowledgment of such a stale transaction is ignored, so it does not trigger another
+// repair. The re-signed transactions may get the nonces of stale ones, so acknowledgments
+// are matched by the hash recorded with TrackTxHash: clients acknowledge through
+// AcknowledgeTxFailure and AcknowledgeTxSuccess. A plain AcknowledgeFailure can't tell the
+// two apart and is taken for the stale transaction while one holds the nonce.
+type GapRepairer struct {
+	mu      sync.Mutex
+	manager Manager
+	resign  ResignFunc     // optional
+	onGap   func(GapEvent) // optional
+	pending map[nonceKey]map[int64]*trackedTx
+	stale   map[nonceKey]map[*trackedTx]int64 // Re-sequenced dependents, to their nonce
+}
+
+// trackedTx is the transaction signed with a pending nonce
+type trackedTx struct {
+	tag     any
+	txHash  string       // Empty until TrackTxHash
+	fetcher NonceFetcher // Fetcher the nonce was allocated with, nil for the Manager's own
+}
+
+// matches reports whether an acknowledgment with txHash may be for t
+func (t *trackedTx) matches(txHash string) bool {
+	return t.txHash == "" || txHash == "" || t.txHash == txHash
+}
+
+// NewGapRepairer creates a GapRepairer around manager.
+// resign may be nil to only report gaps; dependents then get no new nonce.
+// onGap may be nil if no events are needed.
+func NewGapRepairer(manager Manager, resign ResignFunc, onGap func(GapEvent)) *GapRepairer {
+	return &GapRepairer{
+		manager: manager,
+		resign:  resign,
+		onGap:   onGap,
+		pending: make(map[nonceKey]map[int64]*trackedTx),
+		stale:   make(map[nonceKey]map[*trackedTx]int64),
+	}
+}
+
+// Track attaches a tag to a pending nonce, reported back in Dependent.Tag
+func (r *GapRepairer) Track(accountIndex int64, apiKeyIndex uint8, nonce int64, tag any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := nonceKey{accountIndex, apiKeyIndex}
+	if t, ok := r.pending[key][nonce]; ok {
+		t.tag = tag
+		return
+	}
+	r.track(key, nonce, &trackedTx{tag: tag})
+}
+
+// track adds a pending nonce. It must be called with the lock held.
+func (r *GapRepairer) track(key nonceKey, nonce int64, t *trackedTx) {
+	if r.pending[key] == nil {
+		r.pending[key] = make(map[int64]*trackedTx)
+	}
+	r.pending[key][nonce] = t
+}
+
+// Pending returns the pending nonces of an account/key pair in ascending order
+func (r *GapRepairer) Pending(accountIndex int64, apiKeyIndex uint8) []int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return sortedNonces(r.pending[nonceKey{accountIndex, apiKeyIndex}], nil)
+}
+
+// GetNonce allocates a nonce from the wrapped Manager and tracks it as pending
+func (r *GapRepairer) GetNonce(accountIndex int64, apiKeyIndex uint8) (int64, error) {
+	return r.GetNonceWithFetcher(nil, accountIndex, apiKeyIndex)
+}
+
+// GetNonceWithFetcher is like GetNonce, but has the wrapped Manager fetch the nonce with fetcher
+func (r *GapRepairer) GetNonceWithFetcher(fetcher NonceFetcher, accountIndex int64, apiKeyIndex uint8) (int64, error) {
+	nonce, err := GetNonceWith(r.manager, fetcher, accountIndex, apiKeyIndex)
+	if err != nil {
+		return 0, err
+	}
+	r.mu.Lock()
+	r.track(nonceKey{accountIndex, apiKeyIndex}, nonce, &trackedTx{fetcher: fetcher})
+	r.mu.Unlock()
+	return nonce, nil
+}
+
+// TrackTxHash records the hash of the transaction signed with a pending nonce, to match
+// its acknowledgment, and forwards it to the wrapped Manager
+func (r *GapRepairer) TrackTxHash(accountIndex int64, apiKeyIndex uint8, nonce int64, txHash string) {
+	r.mu.Lock()
+	if t, ok := r.pending[nonceKey{accountIndex, apiKeyIndex}][nonce]; ok {
+		t.txHash = txHash
+	}
+	r.mu.Unlock()
+
+	TrackTxHash(r.manager, accountIndex, apiKeyIndex, nonce, txHash)
+}
+
+// AcknowledgeSuccess stops tracking the nonce and forwards the acknowledgment
+func (r *GapRepairer) AcknowledgeSuccess(accountIndex int64, apiKeyIndex uint8, nonce int64) {
+	r.AcknowledgeTxSuccess(accountIndex, apiKeyIndex, nonce, "")
+}
+
+// AcknowledgeTxSuccess stops tracking the transaction with txHash and forwards the acknowledgment
+func (r *GapRepairer) AcknowledgeTxSuccess(accountIndex int64, apiKeyIndex uint8, nonce int64, txHash string) {
+	r.mu.Lock()
+	key := nonceKey{accountIndex, apiKeyIndex}
+	if !r.takeStale(key, nonce, txHash) {
+		if t, ok := r.pending[key][nonce]; ok && t.matches(txHash) {
+			delete(r.pending[key], nonce)
+		}
+	}
+	r.mu.Unlock()
+
+	AcknowledgeTxSuccess(r.manager, accountIndex, apiKeyIndex, nonce, txHash)
+}
+
+// AcknowledgeFailure forwards the acknowledgment and repairs the gap left by the nonce
+func (r *GapRepairer) AcknowledgeFailure(accountIndex int64, apiKeyIndex uint8, nonce int64) {
+	r.AcknowledgeTxFailure(accountIndex, apiKeyIndex, nonce, "")
+}
+
+// AcknowledgeTxFailure forwards the acknowledgment and repairs the gap left by the
+// transaction with txHash. The rejections of re-sequenced dependents are ignored.
+func (r *GapRepairer) AcknowledgeTxFailure(accountIndex int64, apiKeyIndex uint8, nonce int64, txHash string) {
+	r.mu.Lock()
+	key := nonceKey{accountIndex, apiKeyIndex}
+
+	if r.takeStale(key, nonce, txHash) {
+		// Rejection of a dependent that was already re-sequenced
+		r.mu.Unlock()
+		return
+	}
+
+	t, tracked := r.pending[key][nonce]
+	if tracked && !t.matches(txHash) {
+		// Rejection of a transaction that is no longer the one signed with the nonce
+		r.mu.Unlock()
+		return
+	}
+	delete(r.pending[key], nonce)
+	r.manager.AcknowledgeFailure(accountIndex, apiKeyIndex, nonce)
+	if !tracked {
+		r.mu.Unlock()
+		return
+	}
+
+	event := r.resequence(key, nonce)
+	r.mu.Unlock()
+
+	if event == nil {
+		return
+	}
+	if r.resign != nil {
+		for i := range event.Resequenced {
+			r.resignDependent(key, &event.Resequenced[i], t.fetcher)
+		}
+	}
+	if r.onGap != nil {
+		r.onGap(*event)
+	}
+}
+
+// resignDependent allocates a fresh nonce for a dependent with fetcher and re-signs it.
+// Nonces are allocated one at a time outside of the lock, so a slow fetch does not block
+// the GapRepairer, and a nonce whose re-signing failed is acknowledged before the next one
+// is allocated.
+func (r *GapRepairer) resignDependent(key nonceKey, rs *Resequenced, fetcher NonceFetcher) {
+	newNonce, err := GetNonceWith(r.manager, fetcher, key.accountIndex, key.apiKeyIndex)
+	if err != nil {
+		rs.Err = err
+		return
+	}
+	rs.NewNonce = newNonce
+
+	t := &trackedTx{tag: rs.Tag, fetcher: fetcher}
+	r.mu.Lock()
+	r.track(key, newNonce, t)
+	r.mu.Unlock()
+
+	rs.Err = r.resign(rs.Dependent, newNonce)
+	if rs.Err == nil {
+		return
+	}
+
+	// The ResignFunc may have acknowledged the nonce already
+	r.mu.Lock()
+	tracked := r.pending[key][newNonce] == t
+	if tracked {
+		delete(r.pending[key], newNonce)
+	}
+	r.mu.Unlock()
+	if tracked {
+		r.manager.AcknowledgeFailure(key.accountIndex, key.apiKeyIndex, newNonce)
+	}
+}
+
+// takeStale stops tracking the re-sequenced dependent an acknowledgment is for, and
+// reports whether there was one. Without a hash on either side, any dependent signed with
+// the nonce matches.
+// It must be called with the lock held.
+func (r *GapRepairer) takeStale(key nonceKey, nonce int64, txHash string) bool {
+	for t, n := range r.stale[key] {
+		if n == nonce && t.matches(txHash) {
+			delete(r.stale[key], t)
+			return true
+		}
+	}
+	return false
+}
+
+// resequence releases the dependents of a rejected nonce and marks them stale.
+// It must be called with the lock held.
+func (r *GapRepairer) resequence(key nonceKey, rejected int64) *GapEvent {
+	dependents := sortedNonces(r.pending[key], func(n int64) bool { return n > rejected })
+	if len(dependents) == 0 {
+		return nil
+	}
+
+	event := &GapEvent{
+		AccountIndex: key.accountIndex,
+		ApiKeyIndex:  key.apiKeyIndex,
+		Nonce:        rejected,
+	}
+
+	for _, n := range dependents {
+		t := r.pending[key][n]
+		event.Resequenced = append(event.Resequenced, Resequenced{
+			Dependent: Dependent{
+				AccountIndex: key.accountIndex,
+				ApiKeyIndex:  key.apiKeyIndex,
+				Nonce:        n,
+				Tag:          t.tag,
+				TxHash:       t.txHash,
+			},
+			NewNonce: -1,
+		})
+		delete(r.pending[key], n)
+		r.addStale(key, t, n)
+		r.manager.AcknowledgeFailure(key.accountIndex, key.apiKeyIndex, n)
+	}
+	return event
+}
+
+// addStale records a re-sequenced dependent, forgetting the lowest nonce beyond StaleLimit.
+// Stale transactions whose submission timed out may never be acknowledged.
+// It must be called with the lock held.
+func (r *GapRepairer) addStale(key nonceKey, t *trackedTx, nonce int64) {
+	stale := r.stale[key]
+	if stale == nil {
+		stale = make(map[*trackedTx]int64)
+		r.stale[key] = stale
+	}
+	if len(stale) >= StaleLimit {
+		var oldest *trackedTx
+		for s, n := range stale {
+			if oldest == nil || n < stale[oldest] {
+				oldest = s
+			}
+		}
+		delete(stale, oldest)
+	}
+	stale[t] = nonce
+}
+
+// Reset stops tracking an account/key pair and forwards the reset
+func (r *GapRepairer) Reset(accountIndex int64, apiKeyIndex uint8) {
+	r.mu.Lock()
+	key := nonceKey{accountIndex, apiKeyIndex}
+	delete(r.pending, key)
+	delete(r.stale, key)
+	r.mu.Unlock()
+
+	r.manager.Reset(accountIndex, apiKeyIndex)
+}
+
+// ResetAll stops tracking all account/key pairs and forwards the reset
+func (r *GapRepairer) ResetAll() {
+	r.mu.Lock()
+	r.pending = make(map[nonceKey]map[int64]*trackedTx)
+	r.stale = make(map[nonceKey]map[*trackedTx]int64)
+	r.mu.Unlock()
+
+	r.manager.ResetAll()
+}
+
+// sortedNonces returns the nonces of m matching keep (all if nil) in ascending order
+func sortedNonces(m map[int64]*trackedTx, keep func(int64) bool) []int64 {
+	var nonces []int64
+	for n := range m {
+		if keep == nil || keep(n) {
+			nonces = append(nonces, n)
+		}
+	}
+	sort.Slice(nonces, func(i, j int) bool { return nonces[i] < nonces[j] })
+	return nonces
+}
+
+// Ensure GapRepairer implements Manager
+var (
+	_ Manager          = (*GapRepairer)(nil)
+	_ FetcherOverrider = (*GapRepairer)(nil)
+	_ TxHashTracker    = (*GapRepairer)(nil)
+	_ TxAcknowledger   = (*GapRepairer)(nil)
+)
@@ -0,0 +1,273 @@
+package nonce
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestGapRepairer_ResequencesDependents(t *testing.T) {
+	fetcher := newMockFetcher()
+	fetcher.setNonce(1, 0, 10)
+
+	type resigned struct {
+		tag      any
+		oldNonce int64
+		newNonce int64
+	}
+	var calls []resigned
+	var events []GapEvent
+	r := NewGapRepairer(NewOptimisticManager(fetcher),
+		func(d Dependent, newNonce int64) error {
+			calls = append(calls, resigned{d.Tag, d.Nonce, newNonce})
+			return nil
+		},
+		func(e GapEvent) { events = append(events, e) },
+	)
+
+	for i := 0; i < 4; i++ {
+		nonce, err := r.GetNonce(1, 0)
+		if err != nil {
+			t.Fatalf("GetNonce failed: %v", err)
+		}
+		r.Track(1, 0, nonce, 100+i)
+	}
+
+	// 10 lands, 11 is rejected: 12 and 13 can no longer execute
+	r.AcknowledgeSuccess(1, 0, 10)
+	fetcher.setNonce(1, 0, 11)
+	r.AcknowledgeFailure(1, 0, 11)
+
+	expected := []resigned{{102, 12, 11}, {103, 13, 12}}
+	if len(calls) != len(expected) {
+		t.Fatalf("expected %d re-signed transactions, got %v", len(expected), calls)
+	}
+	for i, c := range calls {
+		if c != expected[i] {
+			t.Errorf("re-sign %d: expected %v, got %v", i, expected[i], c)
+		}
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("expected 1 gap event, got %d", len(events))
+	}
+	e := events[0]
+	if e.Nonce != 11 || len(e.Resequenced) != 2 || e.Resequenced[0].Tag != 102 || e.Resequenced[1].NewNonce != 12 {
+		t.Errorf("unexpected gap event: %+v", e)
+	}
+
+	pending := r.Pending(1, 0)
+	if len(pending) != 2 || pending[0] != 11 || pending[1] != 12 {
+		t.Errorf("expected re-signed nonces to be pending, got %v", pending)
+	}
+
+	// The next nonce continues after the re-sequenced ones
+	if nonce, _ := r.GetNonce(1, 0); nonce != 13 {
+		t.Errorf("expected nonce 13, got %d", nonce)
+	}
+}
+
+func TestGapRepairer_IgnoresStaleRejection(t *testing.T) {
+	fetcher := newMockFetcher()
+	fetcher.setNonce(1, 0, 10)
+
+	var events int
+	r := NewGapRepairer(NewOptimisticManager(fetcher),
+		func(d Dependent, newNonce int64) error { return nil },
+		func(e GapEvent) { events++ },
+	)
+
+	r.GetNonce(1, 0) // 10
+	r.GetNonce(1, 0) // 11, already submitted
+
+	fetcher.setNonce(1, 0, 10)
+	r.AcknowledgeFailure(1, 0, 10) // 11 is re-signed as 10
+	fetchesAfterGap := fetcher.getCalls()
+
+	// The rejection of the submitted dependent arrives late
+	r.AcknowledgeFailure(1, 0, 11)
+
+	if events != 1 {
+		t.Errorf("expected 1 gap event, got %d", events)
+	}
+	if fetcher.getCalls() != fetchesAfterGap {
+		t.Error("stale rejection should not reset the wrapped manager")
+	}
+	if pending := r.Pending(1, 0); len(pending) != 1 || pending[0] != 10 {
+		t.Errorf("expected re-signed nonce 10 to stay pending, got %v", pending)
+	}
+}
+
+func TestGapRepairer_ReportOnly(t *testing.T) {
+	fetcher := newMockFetcher()
+	fetcher.setNonce(1, 0, 5)
+
+	var event GapEvent
+	r := NewGapRepairer(NewOptimisticManager(fetcher), nil, func(e GapEvent) { event = e })
+
+	r.GetNonce(1, 0) // 5
+	r.GetNonce(1, 0) // 6
+	r.AcknowledgeFailure(1, 0, 5)
+
+	if len(event.Resequenced) != 1 || event.Resequenced[0].Nonce != 6 || event.Resequenced[0].NewNonce != -1 {
+		t.Errorf("expected dependent 6 without new nonce, got %+v", event)
+	}
+	if pending := r.Pending(1, 0); len(pending) != 0 {
+		t.Errorf("expected no pending nonces, got %v", pending)
+	}
+}
+
+func TestGapRepairer_ResignError(t *testing.T) {
+	fetcher := newMockFetcher()
+	errResign := errors.New("order no longer wanted")
+
+	var event GapEvent
+	manager := NewOptimisticManager(fetcher)
+	r := NewGapRepairer(manager,
+		func(d Dependent, newNonce int64) error { return errResign },
+		func(e GapEvent) { event = e },
+	)
+
+	r.GetNonce(1, 0) // 0
+	r.GetNonce(1, 0) // 1
+	fetcher.setNonce(1, 0, 0)
+	r.AcknowledgeFailure(1, 0, 0)
+
+	if len(event.Resequenced) != 1 || !errors.Is(event.Resequenced[0].Err, errResign) {
+		t.Errorf("expected re-sign error in event, got %+v", event)
+	}
+
+	// The nonce allocated for the re-signing is released
+	if pending := r.Pending(1, 0); len(pending) != 0 {
+		t.Errorf("expected no pending nonces, got %v", pending)
+	}
+	if n := manager.PendingCount(1, 0); n != 0 {
+		t.Errorf("expected no nonce pending in the manager, got %d", n)
+	}
+	fetcher.setNonce(1, 0, 0)
+	if nonce, _ := r.GetNonce(1, 0); nonce != 0 {
+		t.Errorf("expected nonce 0 reused, got %d", nonce)
+	}
+}
+
+// lockingFetcher checks that the GapRepairer is not locked while fetching
+type lockingFetcher struct {
+	*mockFetcher
+	r *GapRepairer
+}
+
+func (f *lockingFetcher) GetNextNonce(accountIndex int64, apiKeyIndex uint8) (int64, error) {
+	f.r.Pending(accountIndex, apiKeyIndex)
+	return f.mockFetcher.GetNextNonce(accountIndex, apiKeyIndex)
+}
+
+func TestGapRepairer_ResequencesWithCallerFetcher(t *testing.T) {
+	own := newMockFetcher()
+	own.setNonce(1, 0, 50)
+	override := &lockingFetcher{mockFetcher: newMockFetcher()}
+	override.setNonce(1, 0, 10)
+
+	var event GapEvent
+	r := NewGapRepairer(NewOptimisticManager(own),
+		func(d Dependent, newNonce int64) error { return nil },
+		func(e GapEvent) { event = e },
+	)
+	override.r = r
+
+	for i := 0; i < 3; i++ {
+		GetNonceWith(r, override, 1, 0) // 10, 11, 12
+	}
+	override.setNonce(1, 0, 10)
+	r.AcknowledgeFailure(1, 0, 10)
+
+	if len(event.Resequenced) != 2 || event.Resequenced[0].NewNonce != 10 || event.Resequenced[1].NewNonce != 11 {
+		t.Errorf("expected dependents re-sequenced as 10 and 11, got %+v", event)
+	}
+	if own.getCalls() != 0 {
+		t.Errorf("expected the manager's own fetcher unused, got %d calls", own.getCalls())
+	}
+}
+
+func TestGapRepairer_BoundsStaleDependents(t *testing.T) {
+	fetcher := newMockFetcher()
+	r := NewGapRepairer(NewOptimisticManager(fetcher), func(d Dependent, newNonce int64) error { return nil }, nil)
+
+	for i := 0; i <= StaleLimit+1; i++ {
+		r.GetNonce(1, 0)
+	}
+	fetcher.setNonce(1, 0, 0)
+	r.AcknowledgeFailure(1, 0, 0)
+
+	// The dependents 1 to StaleLimit+1 are stale, the lowest one is forgotten
+	stale := r.stale[nonceKey{1, 0}]
+	if len(stale) != StaleLimit {
+		t.Fatalf("expected %d stale dependents, got %d", StaleLimit, len(stale))
+	}
+	for _, n := range stale {
+		if n == 1 {
+			t.Error("expected the lowest stale dependent forgotten")
+		}
+	}
+}
+
+func TestGapRepairer_NoGap(t *testing.T) {
+	fetcher := newMockFetcher()
+
+	var events int
+	r := NewGapRepairer(NewOptimisticManager(fetcher), nil, func(e GapEvent) { events++ })
+
+	r.GetNonce(1, 0)
+	r.AcknowledgeFailure(1, 0, 0)
+	r.AcknowledgeFailure(1, 0, 42) // Never handed out
+
+	if events != 0 {
+		t.Errorf("expected no gap event, got %d", events)
+	}
+}
+
+func TestGapRepairer_CollidingRejection(t *testing.T) {
+	fetcher := newMockFetcher()
+	fetcher.setNonce(1, 0, 10)
+
+	var r *GapRepairer
+	var events []GapEvent
+	resigned := 0
+	r = NewGapRepairer(NewOptimisticManager(fetcher),
+		func(d Dependent, newNonce int64) error {
+			resigned++
+			r.TrackTxHash(1, 0, newNonce, fmt.Sprintf("resigned-%d", resigned))
+			return nil
+		},
+		func(e GapEvent) { events = append(events, e) },
+	)
+
+	for i := 0; i < 4; i++ {
+		nonce, _ := r.GetNonce(1, 0)
+		r.TrackTxHash(1, 0, nonce, fmt.Sprintf("tx-%d", nonce))
+	}
+
+	// 10 is rejected: 11, 12 and 13 are re-signed as 10, 11 and 12
+	fetcher.setNonce(1, 0, 10)
+	r.AcknowledgeTxFailure(1, 0, 10, "tx-10")
+	if len(events) != 1 || events[0].Resequenced[0].TxHash != "tx-11" {
+		t.Fatalf("expected the dependents re-sequenced, got %+v", events)
+	}
+
+	// The re-signed 11 is rejected although a stale transaction had nonce 11
+	fetcher.setNonce(1, 0, 11)
+	r.AcknowledgeTxFailure(1, 0, 11, "resigned-2")
+	if len(events) != 2 || events[1].Nonce != 11 || len(events[1].Resequenced) != 1 || events[1].Resequenced[0].TxHash != "resigned-3" {
+		t.Fatalf("expected the re-signed rejection repaired, got %+v", events)
+	}
+
+	// The late rejections of the stale transactions change nothing
+	for n := int64(11); n <= 13; n++ {
+		r.AcknowledgeTxFailure(1, 0, n, fmt.Sprintf("tx-%d", n))
+	}
+	if len(events) != 2 {
+		t.Errorf("expected stale rejections ignored, got %d gap events", len(events))
+	}
+	if pending := r.Pending(1, 0); len(pending) != 2 || pending[0] != 10 || pending[1] != 11 {
+		t.Errorf("expected re-signed nonces 10 and 11 pending, got %v", pending)
+	}
+}
@@ -4,6 +4,10 @@
 //   - OptimisticNonceManager: Assumes transactions succeed and increments locally.
 //     Faster but requires failure acknowledgment for recovery.
 //   - APINonceManager: Queries the API for each nonce. Slower but always accurate.
+//
+// The optimistic manager can persist its state in a Store, such as the
+// journal-backed FileStore, to survive process restarts. A GapRepairer can wrap
+// any Manager to re-sign the transactions left behind a rejected nonce.
 package nonce
 
 // NonceFetcher is the interface for fetching nonce from API
@@ -32,6 +36,62 @@ type Manager interface {
 	ResetAll()
 }
 
+// FetcherOverrider is implemented by Managers that can fetch a nonce with another
+// NonceFetcher than their own for a single call, e.g. with an HTTP client bound to
+// the context of the request the nonce is for
+type FetcherOverrider interface {
+	GetNonceWithFetcher(fetcher NonceFetcher, accountIndex int64, apiKeyIndex uint8) (int64, error)
+}
+
+// GetNonceWith allocates a nonce from m, fetched with fetcher if m implements
+// FetcherOverrider. Otherwise, or if fetcher is nil, it calls m.GetNonce.
+func GetNonceWith(m Manager, fetcher NonceFetcher, accountIndex int64, apiKeyIndex uint8) (int64, error) {
+	if o, ok := m.(FetcherOverrider); ok && fetcher != nil {
+		return o.GetNonceWithFetcher(fetcher, accountIndex, apiKeyIndex)
+	}
+	return m.GetNonce(accountIndex, apiKeyIndex)
+}
+
+// TxHashTracker is implemented by Managers that record the hash of the transaction
+// signed with a nonce, see OptimisticManager.Recover
+type TxHashTracker interface {
+	TrackTxHash(accountIndex int64, apiKeyIndex uint8, nonce int64, txHash string)
+}
+
+// TrackTxHash records the hash of the transaction signed with nonce if m implements TxHashTracker
+func TrackTxHash(m Manager, accountIndex int64, apiKeyIndex uint8, nonce int64, txHash string) {
+	if t, ok := m.(TxHashTracker); ok {
+		t.TrackTxHash(accountIndex, apiKeyIndex, nonce, txHash)
+	}
+}
+
+// TxAcknowledger is implemented by Managers that tell apart the transactions signed with
+// the same nonce, e.g. a rejected transaction and the one re-signed in its place
+type TxAcknowledger interface {
+	AcknowledgeTxSuccess(accountIndex int64, apiKeyIndex uint8, nonce int64, txHash string)
+	AcknowledgeTxFailure(accountIndex int64, apiKeyIndex uint8, nonce int64, txHash string)
+}
+
+// AcknowledgeTxSuccess acknowledges the success of the transaction signed with nonce,
+// along with its hash if m implements TxAcknowledger
+func AcknowledgeTxSuccess(m Manager, accountIndex int64, apiKeyIndex uint8, nonce int64, txHash string) {
+	if a, ok := m.(TxAcknowledger); ok {
+		a.AcknowledgeTxSuccess(accountIndex, apiKeyIndex, nonce, txHash)
+		return
+	}
+	m.AcknowledgeSuccess(accountIndex, apiKeyIndex, nonce)
+}
+
+// AcknowledgeTxFailure acknowledges the failure of the transaction signed with nonce,
+// along with its hash if m implements TxAcknowledger
+func AcknowledgeTxFailure(m Manager, accountIndex int64, apiKeyIndex uint8, nonce int64, txHash string) {
+	if a, ok := m.(TxAcknowledger); ok {
+		a.AcknowledgeTxFailure(accountIndex, apiKeyIndex, nonce, txHash)
+		return
+	}
+	m.AcknowledgeFailure(accountIndex, apiKeyIndex, nonce)
+}
+
 // nonceKey is used as map key for account/apikey pairs
 type nonceKey struct {
 	accountIndex int64
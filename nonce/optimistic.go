@@ -1,6 +1,7 @@
 package nonce
 
 import (
+	"fmt"
 	"sync"
 )
 
@@ -13,92 +14,188 @@ import (
 // This is the fastest option but requires proper failure handling.
 // If a transaction fails and AcknowledgeFailure is not called, subsequent
 // transactions may fail due to nonce mismatch.
+//
+// With a Store (see NewOptimisticManagerWithStore), every change is written
+// through before it takes effect, and Recover reconciles the persisted state
+// with Lighter after a restart. The acknowledgment and reset methods can't return
+// the errors of the store; they are reported to the handler set with SetErrorHandler.
+//
+// Each account/key pair is locked on its own while its nonce is fetched or persisted,
+// so that a slow fetch for one key does not block the others.
 type OptimisticManager struct {
 	mu      sync.Mutex
+	keyMu   map[nonceKey]*sync.Mutex // Serializes the changes of each account/key pair
 	nonces  map[nonceKey]int64
-	pending map[nonceKey]map[int64]struct{} // Track pending nonces
+	pending map[nonceKey]map[int64]string // Pending nonces and the hash of their tx, if known
 	fetcher NonceFetcher
+	store   Store       // optional
+	onError func(error) // optional
 }
 
 // NewOptimisticManager creates a new OptimisticManager
 func NewOptimisticManager(fetcher NonceFetcher) *OptimisticManager {
 	return &OptimisticManager{
+		keyMu:   make(map[nonceKey]*sync.Mutex),
 		nonces:  make(map[nonceKey]int64),
-		pending: make(map[nonceKey]map[int64]struct{}),
+		pending: make(map[nonceKey]map[int64]string),
 		fetcher: fetcher,
 	}
 }
 
+// NewOptimisticManagerWithStore creates an OptimisticManager that persists its state
+// in store and restores the state found there. Call Recover before handing out
+// nonces to reconcile the restored state with Lighter.
+func NewOptimisticManagerWithStore(fetcher NonceFetcher, store Store) (*OptimisticManager, error) {
+	states, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	m := NewOptimisticManager(fetcher)
+	m.store = store
+	for _, st := range states {
+		key := nonceKey{st.AccountIndex, st.ApiKeyIndex}
+		if st.Next >= 0 {
+			m.nonces[key] = st.Next
+		}
+		for _, n := range st.Pending {
+			m.trackPending(key, n)
+			m.pending[key][n] = st.TxHashes[n]
+		}
+	}
+	return m, nil
+}
+
+// SetErrorHandler sets the function called with the store errors of the methods that
+// can't return them. The persisted state may then lag behind; it is reconciled by Recover.
+// fn may be called with an account/key pair locked, so it must not call the manager.
+func (m *OptimisticManager) SetErrorHandler(fn func(error)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onError = fn
+}
+
 // GetNonce returns the next nonce to use for a transaction.
 // If no local nonce exists, it fetches from the API.
 // Otherwise, it increments and returns the local nonce.
 func (m *OptimisticManager) GetNonce(accountIndex int64, apiKeyIndex uint8) (int64, error) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	return m.GetNonceWithFetcher(m.fetcher, accountIndex, apiKeyIndex)
+}
 
+// GetNonceWithFetcher is like GetNonce, but fetches a missing nonce with fetcher
+func (m *OptimisticManager) GetNonceWithFetcher(fetcher NonceFetcher, accountIndex int64, apiKeyIndex uint8) (int64, error) {
 	key := nonceKey{accountIndex, apiKeyIndex}
+	keyMu := m.lockKey(key)
+	defer keyMu.Unlock()
 
-	// Check if we have a local nonce
-	if nonce, ok := m.nonces[key]; ok {
-		// Return current and increment for next
-		m.nonces[key] = nonce + 1
-		m.trackPending(key, nonce)
-		return nonce, nil
+	// Check if we have a local nonce, otherwise fetch from API
+	m.mu.Lock()
+	nonce, ok := m.nonces[key]
+	m.mu.Unlock()
+	if !ok {
+		var err error
+		nonce, err = fetcher.GetNextNonce(accountIndex, apiKeyIndex)
+		if err != nil {
+			return 0, err
+		}
 	}
 
-	// Fetch from API
-	nonce, err := m.fetcher.GetNextNonce(accountIndex, apiKeyIndex)
-	if err != nil {
-		return 0, err
+	// Persist before handing out, so a restart never reuses it
+	if m.store != nil {
+		if err := m.store.Allocate(accountIndex, apiKeyIndex, nonce); err != nil {
+			return 0, err
+		}
 	}
 
 	// Store next nonce and track this one as pending
+	m.mu.Lock()
 	m.nonces[key] = nonce + 1
 	m.trackPending(key, nonce)
+	m.mu.Unlock()
 	return nonce, nil
 }
 
 // AcknowledgeSuccess removes the nonce from pending tracking.
 func (m *OptimisticManager) AcknowledgeSuccess(accountIndex int64, apiKeyIndex uint8, nonce int64) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
 	key := nonceKey{accountIndex, apiKeyIndex}
+	keyMu := m.lockKey(key)
+	defer keyMu.Unlock()
+
+	m.mu.Lock()
 	m.removePending(key, nonce)
+	m.mu.Unlock()
+	if m.store != nil {
+		m.reportStoreError(m.store.Release(accountIndex, apiKeyIndex, nonce))
+	}
 }
 
 // AcknowledgeFailure removes the nonce from pending and resets local state
 // to force an API fetch on the next GetNonce call.
 func (m *OptimisticManager) AcknowledgeFailure(accountIndex int64, apiKeyIndex uint8, nonce int64) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
 	key := nonceKey{accountIndex, apiKeyIndex}
+	keyMu := m.lockKey(key)
+	defer keyMu.Unlock()
 
+	m.mu.Lock()
 	// Remove from pending
 	m.removePending(key, nonce)
 
 	// Reset local nonce to force API fetch
 	delete(m.nonces, key)
+	m.mu.Unlock()
+
+	if m.store != nil {
+		m.reportStoreError(m.store.Release(accountIndex, apiKeyIndex, nonce))
+		m.reportStoreError(m.store.SetNext(accountIndex, apiKeyIndex, -1))
+	}
 }
 
 // Reset clears the cached nonce for a specific account/key pair.
 func (m *OptimisticManager) Reset(accountIndex int64, apiKeyIndex uint8) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
 	key := nonceKey{accountIndex, apiKeyIndex}
+	keyMu := m.lockKey(key)
+	defer keyMu.Unlock()
+
+	m.mu.Lock()
 	delete(m.nonces, key)
 	delete(m.pending, key)
+	m.mu.Unlock()
+	if m.store != nil {
+		m.reportStoreError(m.store.Reset(accountIndex, apiKeyIndex))
+	}
 }
 
 // ResetAll clears all cached nonce state.
 func (m *OptimisticManager) ResetAll() {
 	m.mu.Lock()
-	defer m.mu.Unlock()
-
 	m.nonces = make(map[nonceKey]int64)
-	m.pending = make(map[nonceKey]map[int64]struct{})
+	m.pending = make(map[nonceKey]map[int64]string)
+	m.mu.Unlock()
+
+	if m.store != nil {
+		m.reportStoreError(m.store.ResetAll())
+	}
+}
+
+// TrackTxHash records the hash of the transaction signed with a pending nonce, so that
+// Recover can check whether it reached Lighter. Clients call it before submission.
+func (m *OptimisticManager) TrackTxHash(accountIndex int64, apiKeyIndex uint8, nonce int64, txHash string) {
+	key := nonceKey{accountIndex, apiKeyIndex}
+	keyMu := m.lockKey(key)
+	defer keyMu.Unlock()
+
+	m.mu.Lock()
+	_, ok := m.pending[key][nonce]
+	if ok && txHash != "" {
+		m.pending[key][nonce] = txHash
+	}
+	m.mu.Unlock()
+	if !ok || txHash == "" {
+		return
+	}
+	if s, ok := m.store.(TxHashStore); ok {
+		m.reportStoreError(s.SetTxHash(accountIndex, apiKeyIndex, nonce, txHash))
+	}
 }
 
 // PendingCount returns the number of pending transactions for an account/key pair.
@@ -113,11 +210,26 @@ func (m *OptimisticManager) PendingCount(accountIndex int64, apiKeyIndex uint8)
 	return 0
 }
 
+// lockKey locks and returns the mutex of an account/key pair. It must be called without
+// m.mu held, which is taken after the key's mutex.
+func (m *OptimisticManager) lockKey(key nonceKey) *sync.Mutex {
+	m.mu.Lock()
+	keyMu, ok := m.keyMu[key]
+	if !ok {
+		keyMu = new(sync.Mutex)
+		m.keyMu[key] = keyMu
+	}
+	m.mu.Unlock()
+
+	keyMu.Lock()
+	return keyMu
+}
+
 func (m *OptimisticManager) trackPending(key nonceKey, nonce int64) {
 	if m.pending[key] == nil {
-		m.pending[key] = make(map[int64]struct{})
+		m.pending[key] = make(map[int64]string)
 	}
-	m.pending[key][nonce] = struct{}{}
+	m.pending[key][nonce] = ""
 }
 
 func (m *OptimisticManager) removePending(key nonceKey, nonce int64) {
@@ -126,5 +238,22 @@ func (m *OptimisticManager) removePending(key nonceKey, nonce int64) {
 	}
 }
 
+// reportStoreError hands a store error to the error handler, if any
+func (m *OptimisticManager) reportStoreError(err error) {
+	if err == nil {
+		return
+	}
+	m.mu.Lock()
+	onError := m.onError
+	m.mu.Unlock()
+	if onError != nil {
+		onError(fmt.Errorf("nonce store: %w", err))
+	}
+}
+
 // Ensure OptimisticManager implements Manager
-var _ Manager = (*OptimisticManager)(nil)
+var (
+	_ Manager          = (*OptimisticManager)(nil)
+	_ FetcherOverrider = (*OptimisticManager)(nil)
+	_ TxHashTracker    = (*OptimisticManager)(nil)
+)
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"sync"
 	"testing"
+	"time"
 )
 
 // mockFetcher is a mock implementation of NonceFetcher for testing
@@ -152,6 +153,85 @@ func TestOptimisticManager_GetNonce_FetchError(t *testing.T) {
 	}
 }
 
+func TestOptimisticManager_GetNonceWithFetcher(t *testing.T) {
+	own := newMockFetcher()
+	own.setNonce(1, 0, 10)
+	override := newMockFetcher()
+	override.setNonce(1, 0, 20)
+	manager := NewOptimisticManager(own)
+
+	nonce, err := GetNonceWith(manager, override, 1, 0)
+	if err != nil {
+		t.Fatalf("GetNonceWith failed: %v", err)
+	}
+	if nonce != 20 || own.getCalls() != 0 || override.getCalls() != 1 {
+		t.Errorf("expected nonce 20 from the override, got %d", nonce)
+	}
+
+	// The local nonce is used once known, and a nil fetcher uses the manager's own
+	nonce, _ = GetNonceWith(manager, override, 1, 0)
+	other, _ := GetNonceWith(manager, nil, 2, 0)
+	if nonce != 21 || other != 0 || override.getCalls() != 1 || own.getCalls() != 1 {
+		t.Errorf("expected nonces 21 and 0, got %d and %d", nonce, other)
+	}
+}
+
+// blockingFetcher blocks the fetches of one account until release is closed
+type blockingFetcher struct {
+	*mockFetcher
+	account int64
+	started chan struct{}
+	release chan struct{}
+}
+
+func (f *blockingFetcher) GetNextNonce(accountIndex int64, apiKeyIndex uint8) (int64, error) {
+	if accountIndex == f.account {
+		close(f.started)
+		<-f.release
+	}
+	return f.mockFetcher.GetNextNonce(accountIndex, apiKeyIndex)
+}
+
+func TestOptimisticManager_SlowFetchDoesNotBlockOtherKeys(t *testing.T) {
+	fetcher := &blockingFetcher{
+		mockFetcher: newMockFetcher(),
+		account:     1,
+		started:     make(chan struct{}),
+		release:     make(chan struct{}),
+	}
+	fetcher.setNonce(1, 0, 10)
+	fetcher.setNonce(2, 0, 20)
+	manager := NewOptimisticManager(fetcher)
+
+	blocked := make(chan int64)
+	go func() {
+		nonce, _ := manager.GetNonce(1, 0)
+		blocked <- nonce
+	}()
+	<-fetcher.started
+
+	// Another key and the bookkeeping of the blocked one go on while the fetch hangs
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if nonce, err := manager.GetNonce(2, 0); err != nil || nonce != 20 {
+			t.Errorf("expected nonce 20, got %d (%v)", nonce, err)
+		}
+		manager.AcknowledgeSuccess(2, 0, 20)
+		manager.PendingCount(1, 0)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("GetNonce for another key blocked on a slow fetch")
+	}
+
+	close(fetcher.release)
+	if nonce := <-blocked; nonce != 10 {
+		t.Errorf("expected nonce 10, got %d", nonce)
+	}
+}
+
 func TestOptimisticManager_AcknowledgeSuccess(t *testing.T) {
 	fetcher := newMockFetcher()
 	fetcher.setNonce(1, 0, 100)
@@ -315,3 +395,57 @@ func TestOptimisticManager_PendingCount_Empty(t *testing.T) {
 		t.Errorf("expected 0 pending for unknown account, got %d", count)
 	}
 }
+
+// failingStore is a Store whose writes fail once broken is set
+type failingStore struct {
+	broken bool
+}
+
+func (s *failingStore) err() error {
+	if s.broken {
+		return errors.New("disk full")
+	}
+	return nil
+}
+
+func (s *failingStore) Load() ([]State, error) { return nil, nil }
+func (s *failingStore) Allocate(accountIndex int64, apiKeyIndex uint8, nonce int64) error {
+	return s.err()
+}
+func (s *failingStore) Release(accountIndex int64, apiKeyIndex uint8, nonce int64) error {
+	return s.err()
+}
+func (s *failingStore) SetNext(accountIndex int64, apiKeyIndex uint8, next int64) error {
+	return s.err()
+}
+func (s *failingStore) Reset(accountIndex int64, apiKeyIndex uint8) error { return s.err() }
+func (s *failingStore) ResetAll() error                                   { return s.err() }
+func (s *failingStore) Close() error                                      { return nil }
+
+func TestOptimisticManager_ReportsStoreErrors(t *testing.T) {
+	fetcher := newMockFetcher()
+	store := &failingStore{}
+	manager, _ := NewOptimisticManagerWithStore(fetcher, store)
+	var reported []error
+	manager.SetErrorHandler(func(err error) { reported = append(reported, err) })
+
+	n1, _ := manager.GetNonce(1, 0)
+	n2, _ := manager.GetNonce(1, 0)
+	store.broken = true
+	if _, err := manager.GetNonce(1, 0); err == nil {
+		t.Error("expected GetNonce to return the store error")
+	}
+
+	manager.AcknowledgeSuccess(1, 0, n1)
+	manager.AcknowledgeFailure(1, 0, n2) // Release and SetNext
+	manager.Reset(1, 0)
+	manager.ResetAll()
+	if len(reported) != 5 {
+		t.Fatalf("expected 5 reported store errors, got %d: %v", len(reported), reported)
+	}
+	for _, err := range reported {
+		if err.Error() != "nonce store: disk full" {
+			t.Errorf("unexpected error %v", err)
+		}
+	}
+}
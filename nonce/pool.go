@@ -0,0 +1,274 @@
+package nonce
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/0xJord4n/lighter-go/signer"
+)
+
+var (
+	// ErrEmptyPool is returned when creating a Pool without keys
+	ErrEmptyPool = errors.New("nonce pool requires at least one api key")
+	// ErrKeyNotInPool is returned when using an api key the Pool does not own
+	ErrKeyNotInPool = errors.New("api key not in nonce pool")
+)
+
+// PoolKey is an API key owned by a Pool
+type PoolKey struct {
+	ApiKeyIndex uint8
+	KeyManager  signer.KeyManager
+}
+
+// Lease is a key and nonce handed out by a Pool. It is released when its
+// nonce is acknowledged through the Pool's Manager methods.
+type Lease struct {
+	ApiKeyIndex uint8
+	KeyManager  signer.KeyManager
+	Nonce       int64
+}
+
+// pooledKey tracks the nonces of a key that were handed out but not acknowledged
+type pooledKey struct {
+	PoolKey
+	reserved int // Nonce requests in progress
+	inFlight map[int64]struct{}
+}
+
+func (k *pooledKey) load() int {
+	return k.reserved + len(k.inFlight)
+}
+
+// Pool spreads the transactions of one account over several API keys.
+// Each key has an independent nonce sequence, so transactions signed with
+// different keys can be created and submitted concurrently, and a key whose
+// transactions are stuck stops being picked as its load grows.
+//
+// Pool implements Manager: it allocates nonces through the wrapped Manager and
+// tracks the load of each key until the nonce is acknowledged.
+type Pool struct {
+	mu           sync.Mutex
+	accountIndex int64
+	manager      Manager
+	keys         []*pooledKey
+	byIndex      map[uint8]*pooledKey
+	next         int // Round robin start, so that equally loaded keys take turns
+}
+
+// NewPool creates a Pool for the given account and keys.
+// Nonces are allocated by manager, e.g. an OptimisticManager.
+func NewPool(accountIndex int64, manager Manager, keys ...PoolKey) (*Pool, error) {
+	if len(keys) == 0 {
+		return nil, ErrEmptyPool
+	}
+
+	p := &Pool{
+		accountIndex: accountIndex,
+		manager:      manager,
+		byIndex:      make(map[uint8]*pooledKey, len(keys)),
+	}
+	for _, key := range keys {
+		if _, ok := p.byIndex[key.ApiKeyIndex]; ok {
+			return nil, fmt.Errorf("duplicate api key index %d in nonce pool", key.ApiKeyIndex)
+		}
+		pk := &pooledKey{PoolKey: key, inFlight: make(map[int64]struct{})}
+		p.keys = append(p.keys, pk)
+		p.byIndex[key.ApiKeyIndex] = pk
+	}
+	return p, nil
+}
+
+// AccountIndex returns the account the Pool signs for
+func (p *Pool) AccountIndex() int64 {
+	return p.accountIndex
+}
+
+// Keys returns the keys owned by the Pool
+func (p *Pool) Keys() []PoolKey {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	keys := make([]PoolKey, len(p.keys))
+	for i, k := range p.keys {
+		keys[i] = k.PoolKey
+	}
+	return keys
+}
+
+// KeyManager returns the key manager of an API key owned by the Pool
+func (p *Pool) KeyManager(apiKeyIndex uint8) (signer.KeyManager, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	k, ok := p.byIndex[apiKeyIndex]
+	if !ok {
+		return nil, false
+	}
+	return k.KeyManager, true
+}
+
+// SetKeyManager replaces the key manager of an API key owned by the Pool, e.g. after
+// the key was rotated. Leases handed out before keep the previous key manager.
+func (p *Pool) SetKeyManager(apiKeyIndex uint8, keyManager signer.KeyManager) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	k, ok := p.byIndex[apiKeyIndex]
+	if !ok {
+		return ErrKeyNotInPool
+	}
+	k.KeyManager = keyManager
+	return nil
+}
+
+// InFlight returns the number of nonces of an API key handed out but not acknowledged
+func (p *Pool) InFlight(apiKeyIndex uint8) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if k, ok := p.byIndex[apiKeyIndex]; ok {
+		return k.load()
+	}
+	return 0
+}
+
+// Acquire picks the least loaded key and allocates its next nonce
+func (p *Pool) Acquire() (*Lease, error) {
+	return p.AcquireWithFetcher(nil)
+}
+
+// AcquireWithFetcher is like Acquire, but has the wrapped Manager fetch the nonce with
+// fetcher, see GetNonceWith
+func (p *Pool) AcquireWithFetcher(fetcher NonceFetcher) (*Lease, error) {
+	p.mu.Lock()
+	var best *pooledKey
+	for i := range p.keys {
+		k := p.keys[(p.next+i)%len(p.keys)]
+		if best == nil || k.load() < best.load() {
+			best = k
+		}
+	}
+	p.next = (p.next + 1) % len(p.keys)
+	best.reserved++
+	p.mu.Unlock()
+
+	// Allocate outside the pool lock, so that a slow fetch for one key does not block the others
+	nonce, err := GetNonceWith(p.manager, fetcher, p.accountIndex, best.ApiKeyIndex)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	best.reserved--
+	if err != nil {
+		return nil, err
+	}
+	best.inFlight[nonce] = struct{}{}
+
+	return &Lease{
+		ApiKeyIndex: best.ApiKeyIndex,
+		KeyManager:  best.KeyManager,
+		Nonce:       nonce,
+	}, nil
+}
+
+// GetNonce allocates the next nonce of a specific key owned by the Pool
+func (p *Pool) GetNonce(accountIndex int64, apiKeyIndex uint8) (int64, error) {
+	return p.GetNonceWithFetcher(nil, accountIndex, apiKeyIndex)
+}
+
+// GetNonceWithFetcher is like GetNonce, but has the wrapped Manager fetch the nonce with fetcher
+func (p *Pool) GetNonceWithFetcher(fetcher NonceFetcher, accountIndex int64, apiKeyIndex uint8) (int64, error) {
+	if accountIndex != p.accountIndex {
+		return GetNonceWith(p.manager, fetcher, accountIndex, apiKeyIndex)
+	}
+
+	p.mu.Lock()
+	k, ok := p.byIndex[apiKeyIndex]
+	if ok {
+		k.reserved++
+	}
+	p.mu.Unlock()
+	if !ok {
+		return 0, ErrKeyNotInPool
+	}
+
+	nonce, err := GetNonceWith(p.manager, fetcher, accountIndex, apiKeyIndex)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	k.reserved--
+	if err != nil {
+		return 0, err
+	}
+	k.inFlight[nonce] = struct{}{}
+	return nonce, nil
+}
+
+// TrackTxHash forwards the hash of the transaction signed with nonce to the wrapped Manager
+func (p *Pool) TrackTxHash(accountIndex int64, apiKeyIndex uint8, nonce int64, txHash string) {
+	TrackTxHash(p.manager, accountIndex, apiKeyIndex, nonce, txHash)
+}
+
+// AcknowledgeSuccess releases the nonce and forwards the acknowledgment to the wrapped Manager
+func (p *Pool) AcknowledgeSuccess(accountIndex int64, apiKeyIndex uint8, nonce int64) {
+	p.release(accountIndex, apiKeyIndex, nonce)
+	p.manager.AcknowledgeSuccess(accountIndex, apiKeyIndex, nonce)
+}
+
+// AcknowledgeFailure releases the nonce and forwards the acknowledgment to the wrapped Manager
+func (p *Pool) AcknowledgeFailure(accountIndex int64, apiKeyIndex uint8, nonce int64) {
+	p.release(accountIndex, apiKeyIndex, nonce)
+	p.manager.AcknowledgeFailure(accountIndex, apiKeyIndex, nonce)
+}
+
+// AcknowledgeTxSuccess is like AcknowledgeSuccess, forwarding the transaction's hash
+func (p *Pool) AcknowledgeTxSuccess(accountIndex int64, apiKeyIndex uint8, nonce int64, txHash string) {
+	p.release(accountIndex, apiKeyIndex, nonce)
+	AcknowledgeTxSuccess(p.manager, accountIndex, apiKeyIndex, nonce, txHash)
+}
+
+// AcknowledgeTxFailure is like AcknowledgeFailure, forwarding the transaction's hash
+func (p *Pool) AcknowledgeTxFailure(accountIndex int64, apiKeyIndex uint8, nonce int64, txHash string) {
+	p.release(accountIndex, apiKeyIndex, nonce)
+	AcknowledgeTxFailure(p.manager, accountIndex, apiKeyIndex, nonce, txHash)
+}
+
+// Reset clears the load of a key and resets it in the wrapped Manager
+func (p *Pool) Reset(accountIndex int64, apiKeyIndex uint8) {
+	p.mu.Lock()
+	if k, ok := p.byIndex[apiKeyIndex]; ok && accountIndex == p.accountIndex {
+		k.inFlight = make(map[int64]struct{})
+	}
+	p.mu.Unlock()
+	p.manager.Reset(accountIndex, apiKeyIndex)
+}
+
+// ResetAll clears the load of all keys and resets the wrapped Manager
+func (p *Pool) ResetAll() {
+	p.mu.Lock()
+	for _, k := range p.keys {
+		k.inFlight = make(map[int64]struct{})
+	}
+	p.mu.Unlock()
+	p.manager.ResetAll()
+}
+
+func (p *Pool) release(accountIndex int64, apiKeyIndex uint8, nonce int64) {
+	if accountIndex != p.accountIndex {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if k, ok := p.byIndex[apiKeyIndex]; ok {
+		delete(k.inFlight, nonce)
+	}
+}
+
+// Ensure Pool implements Manager
+var (
+	_ Manager          = (*Pool)(nil)
+	_ FetcherOverrider = (*Pool)(nil)
+	_ TxHashTracker    = (*Pool)(nil)
+	_ TxAcknowledger   = (*Pool)(nil)
+)
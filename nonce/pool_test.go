@@ -0,0 +1,159 @@
+package nonce
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/0xJord4n/lighter-go/signer"
+)
+
+func newTestPool(t *testing.T, fetcher *mockFetcher, indices ...uint8) *Pool {
+	t.Helper()
+	keys := make([]PoolKey, len(indices))
+	for i, idx := range indices {
+		keys[i] = PoolKey{ApiKeyIndex: idx}
+	}
+	pool, err := NewPool(1, NewOptimisticManager(fetcher), keys...)
+	if err != nil {
+		t.Fatalf("NewPool failed: %v", err)
+	}
+	return pool
+}
+
+func TestPool_NewPool_Validation(t *testing.T) {
+	if _, err := NewPool(1, NewOptimisticManager(newMockFetcher())); !errors.Is(err, ErrEmptyPool) {
+		t.Errorf("expected ErrEmptyPool, got %v", err)
+	}
+	if _, err := NewPool(1, NewOptimisticManager(newMockFetcher()), PoolKey{ApiKeyIndex: 2}, PoolKey{ApiKeyIndex: 2}); err == nil {
+		t.Error("expected error for duplicate api key index")
+	}
+}
+
+func TestPool_Acquire_SpreadsAcrossKeys(t *testing.T) {
+	fetcher := newMockFetcher()
+	fetcher.setNonce(1, 2, 100)
+	fetcher.setNonce(1, 3, 200)
+	pool := newTestPool(t, fetcher, 2, 3)
+
+	seen := make(map[uint8][]int64)
+	for i := 0; i < 4; i++ {
+		lease, err := pool.Acquire()
+		if err != nil {
+			t.Fatalf("Acquire failed: %v", err)
+		}
+		seen[lease.ApiKeyIndex] = append(seen[lease.ApiKeyIndex], lease.Nonce)
+	}
+
+	if len(seen[2]) != 2 || len(seen[3]) != 2 {
+		t.Fatalf("expected leases to be spread evenly, got %v", seen)
+	}
+	if seen[2][0] != 100 || seen[2][1] != 101 || seen[3][0] != 200 || seen[3][1] != 201 {
+		t.Errorf("expected independent nonce sequences, got %v", seen)
+	}
+}
+
+func TestPool_Acquire_AvoidsLoadedKey(t *testing.T) {
+	fetcher := newMockFetcher()
+	pool := newTestPool(t, fetcher, 0, 1)
+
+	// Key 0 has two transactions stuck in flight
+	pool.GetNonce(1, 0)
+	pool.GetNonce(1, 0)
+
+	for i := 0; i < 2; i++ {
+		lease, err := pool.Acquire()
+		if err != nil {
+			t.Fatalf("Acquire failed: %v", err)
+		}
+		if lease.ApiKeyIndex != 1 {
+			t.Errorf("expected least loaded key 1, got %d", lease.ApiKeyIndex)
+		}
+	}
+
+	if pool.InFlight(0) != 2 || pool.InFlight(1) != 2 {
+		t.Errorf("unexpected load: key 0 %d, key 1 %d", pool.InFlight(0), pool.InFlight(1))
+	}
+}
+
+func TestPool_AcknowledgeReleasesLoad(t *testing.T) {
+	fetcher := newMockFetcher()
+	pool := newTestPool(t, fetcher, 0)
+
+	lease, _ := pool.Acquire()
+	if pool.InFlight(0) != 1 {
+		t.Fatalf("expected 1 nonce in flight, got %d", pool.InFlight(0))
+	}
+
+	pool.AcknowledgeFailure(1, lease.ApiKeyIndex, lease.Nonce)
+	if pool.InFlight(0) != 0 {
+		t.Errorf("expected no nonce in flight, got %d", pool.InFlight(0))
+	}
+}
+
+func TestPool_GetNonce_UnknownKey(t *testing.T) {
+	pool := newTestPool(t, newMockFetcher(), 0)
+
+	if _, err := pool.GetNonce(1, 7); !errors.Is(err, ErrKeyNotInPool) {
+		t.Errorf("expected ErrKeyNotInPool, got %v", err)
+	}
+}
+
+func TestPool_SetKeyManager(t *testing.T) {
+	pool := newTestPool(t, newMockFetcher(), 0, 1)
+	km, err := signer.NewKeyManager(make([]byte, 40))
+	if err != nil {
+		t.Fatalf("NewKeyManager failed: %v", err)
+	}
+
+	if err := pool.SetKeyManager(1, km); err != nil {
+		t.Fatalf("SetKeyManager failed: %v", err)
+	}
+	if got, _ := pool.KeyManager(1); got != km {
+		t.Error("expected the replaced key manager")
+	}
+	if got, _ := pool.KeyManager(0); got != nil {
+		t.Error("expected the other key untouched")
+	}
+	if err := pool.SetKeyManager(7, km); !errors.Is(err, ErrKeyNotInPool) {
+		t.Errorf("expected ErrKeyNotInPool, got %v", err)
+	}
+}
+
+func TestPool_Acquire_Concurrent(t *testing.T) {
+	fetcher := newMockFetcher()
+	pool := newTestPool(t, fetcher, 0, 1, 2)
+
+	type keyNonce struct {
+		apiKeyIndex uint8
+		nonce       int64
+	}
+	var mu sync.Mutex
+	seen := make(map[keyNonce]bool)
+	var wg sync.WaitGroup
+	for i := 0; i < 60; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lease, err := pool.Acquire()
+			if err != nil {
+				t.Errorf("Acquire failed: %v", err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			key := keyNonce{lease.ApiKeyIndex, lease.Nonce}
+			if seen[key] {
+				t.Errorf("nonce %d of key %d handed out twice", lease.Nonce, lease.ApiKeyIndex)
+			}
+			seen[key] = true
+		}()
+	}
+	wg.Wait()
+
+	for idx := uint8(0); idx < 3; idx++ {
+		if n := pool.InFlight(idx); n != 20 {
+			t.Errorf("expected 20 nonces in flight for key %d, got %d", idx, n)
+		}
+	}
+}
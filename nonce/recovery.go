@@ -0,0 +1,173 @@
+package nonce
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+
+	"github.com/0xJord4n/lighter-go/types/api"
+)
+
+// RecoveryTxLimit is the number of recent account transactions inspected by Recover
+const RecoveryTxLimit = 100
+
+// TxFetcher is the interface for fetching the transactions of an account,
+// implemented by client.TransactionAPI
+type TxFetcher interface {
+	GetAccountTxs(by api.QueryBy, value string, limit int, types []api.TxType) (*api.Txs, error)
+	GetTx(by api.QueryBy, value string) (*api.EnrichedTx, error)
+}
+
+// KeyRecovery describes how the state of an account/key pair was reconciled
+type KeyRecovery struct {
+	AccountIndex int64
+	ApiKeyIndex  uint8
+	JournalNext  int64   // Next nonce found in the store, -1 if unknown
+	ServerNext   int64   // Next nonce reported by GetNextNonce
+	Next         int64   // Next nonce handed out from now on
+	Landed       []int64 // Pending nonces that reached Lighter before the restart
+	Lost         []int64 // Pending nonces whose tx is confirmed absent from Lighter, reused from now on
+	Skipped      []int64 // Pending nonces whose tx may still execute, never reused
+}
+
+// RecoveryReport lists the account/key pairs reconciled by Recover
+type RecoveryReport struct {
+	Keys []KeyRecovery
+}
+
+// Recover reconciles the state restored from the store with Lighter.
+//
+// For every known account/key pair, nonces below the server's next nonce and the
+// latest transaction of that key among the account's recent transactions are landed.
+// No nonce the store saw handed out is reused unless its transaction is confirmed
+// absent: the next nonce starts above the journal's next nonce and every pending
+// nonce, and only moves back over the highest pending nonces whose tx hash, recorded
+// with TrackTxHash, GetTx reports as not found. The other pending nonces are skipped.
+//
+// txs may be nil to only rely on GetNextNonce; no pending nonce is reused then.
+func (m *OptimisticManager) Recover(txs TxFetcher) (*RecoveryReport, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys := make(map[nonceKey]struct{})
+	for key := range m.nonces {
+		keys[key] = struct{}{}
+	}
+	for key := range m.pending {
+		keys[key] = struct{}{}
+	}
+
+	// Latest nonce seen per key, fetched once per account
+	seen := make(map[nonceKey]int64)
+	fetched := make(map[int64]bool)
+
+	report := &RecoveryReport{}
+	for key := range keys {
+		serverNext, err := m.fetcher.GetNextNonce(key.accountIndex, key.apiKeyIndex)
+		if err != nil {
+			return nil, err
+		}
+
+		if txs != nil && !fetched[key.accountIndex] {
+			if err := fetchSeenNonces(txs, key.accountIndex, seen); err != nil {
+				return nil, err
+			}
+			fetched[key.accountIndex] = true
+		}
+
+		rec := KeyRecovery{
+			AccountIndex: key.accountIndex,
+			ApiKeyIndex:  key.apiKeyIndex,
+			JournalNext:  -1,
+			ServerNext:   serverNext,
+		}
+		if n, ok := m.nonces[key]; ok {
+			rec.JournalNext = n
+		}
+
+		// Nonces below landed are known to have reached Lighter
+		landed := serverNext
+		if latest, ok := seen[key]; ok && latest+1 > landed {
+			landed = latest + 1
+		}
+		next := max(landed, rec.JournalNext)
+		for n := range m.pending[key] {
+			next = max(next, n+1)
+		}
+
+		// Move back over the highest pending nonces confirmed absent
+		for next > landed {
+			txHash, ok := m.pending[key][next-1]
+			if !ok || txHash == "" || txs == nil {
+				break
+			}
+			if !txAbsent(txs, txHash) {
+				break
+			}
+			next--
+		}
+		rec.Next = next
+
+		for n := range m.pending[key] {
+			switch {
+			case n < landed:
+				rec.Landed = append(rec.Landed, n)
+			case n >= next:
+				rec.Lost = append(rec.Lost, n)
+			default:
+				rec.Skipped = append(rec.Skipped, n)
+			}
+		}
+		sort.Slice(rec.Landed, func(i, j int) bool { return rec.Landed[i] < rec.Landed[j] })
+		sort.Slice(rec.Lost, func(i, j int) bool { return rec.Lost[i] < rec.Lost[j] })
+		sort.Slice(rec.Skipped, func(i, j int) bool { return rec.Skipped[i] < rec.Skipped[j] })
+
+		if m.store != nil {
+			if err := m.store.Reset(key.accountIndex, key.apiKeyIndex); err != nil {
+				return nil, err
+			}
+			if err := m.store.SetNext(key.accountIndex, key.apiKeyIndex, next); err != nil {
+				return nil, err
+			}
+		}
+		m.nonces[key] = next
+		delete(m.pending, key)
+
+		report.Keys = append(report.Keys, rec)
+	}
+
+	sort.Slice(report.Keys, func(i, j int) bool {
+		a, b := report.Keys[i], report.Keys[j]
+		if a.AccountIndex != b.AccountIndex {
+			return a.AccountIndex < b.AccountIndex
+		}
+		return a.ApiKeyIndex < b.ApiKeyIndex
+	})
+	return report, nil
+}
+
+// txAbsent reports whether GetTx confirms that Lighter does not know txHash.
+// Only a not found answer confirms it; other errors leave the tx status unknown.
+func txAbsent(txs TxFetcher, txHash string) bool {
+	_, err := txs.GetTx(api.QueryByHash, txHash)
+	var notFound interface{ IsNotFound() bool }
+	return errors.As(err, &notFound) && notFound.IsNotFound()
+}
+
+// fetchSeenNonces records the latest nonce per api key among the recent transactions of an account
+func fetchSeenNonces(txs TxFetcher, accountIndex int64, seen map[nonceKey]int64) error {
+	resp, err := txs.GetAccountTxs(api.QueryByIndex, strconv.FormatInt(accountIndex, 10), RecoveryTxLimit, nil)
+	if err != nil {
+		return err
+	}
+	for _, tx := range resp.Txs {
+		if tx.AccountIndex != accountIndex {
+			continue
+		}
+		key := nonceKey{accountIndex, tx.ApiKeyIndex}
+		if latest, ok := seen[key]; !ok || tx.Nonce > latest {
+			seen[key] = tx.Nonce
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package nonce
+
+// State is the persisted nonce state of an account/key pair
+type State struct {
+	AccountIndex int64
+	ApiKeyIndex  uint8
+	Next         int64            // Next nonce to hand out, -1 if it must be fetched from the API
+	Pending      []int64          // Nonces handed out but not acknowledged yet
+	TxHashes     map[int64]string // Hashes of the transactions signed with pending nonces, where known
+}
+
+// Store persists the state of an OptimisticManager, so that a restarted
+// process neither reuses nonces nor races its own in-flight transactions.
+//
+// Every method returns only once the change is durable.
+type Store interface {
+	// Load returns the persisted state of all account/key pairs.
+	Load() ([]State, error)
+
+	// Allocate records that nonce was handed out: it is pending and the next nonce is nonce+1.
+	Allocate(accountIndex int64, apiKeyIndex uint8, nonce int64) error
+
+	// Release records that nonce is no longer pending.
+	Release(accountIndex int64, apiKeyIndex uint8, nonce int64) error
+
+	// SetNext records the next nonce to hand out, -1 to fetch it from the API.
+	SetNext(accountIndex int64, apiKeyIndex uint8, next int64) error
+
+	// Reset forgets all state of an account/key pair.
+	Reset(accountIndex int64, apiKeyIndex uint8) error
+
+	// ResetAll forgets all state.
+	ResetAll() error
+
+	// Close releases the resources held by the store.
+	Close() error
+}
+
+// TxHashStore is implemented by Stores that also persist the hash of the transaction
+// signed with a pending nonce, which lets Recover confirm that the transaction never
+// reached Lighter before reusing its nonce
+type TxHashStore interface {
+	// SetTxHash records the hash of the transaction signed with a pending nonce.
+	SetTxHash(accountIndex int64, apiKeyIndex uint8, nonce int64, txHash string) error
+}
+
+// storeOp identifies a state change in a Store journal
+type storeOp string
+
+const (
+	opAllocate storeOp = "alloc"
+	opRelease  storeOp = "release"
+	opSetNext  storeOp = "next"
+	opReset    storeOp = "reset"
+	opResetAll storeOp = "reset_all"
+	opTxHash   storeOp = "tx_hash"
+)
+
+// storeRecord is a single journal entry
+type storeRecord struct {
+	Op           storeOp `json:"op"`
+	AccountIndex int64   `json:"account_index,omitempty"`
+	ApiKeyIndex  uint8   `json:"api_key_index,omitempty"`
+	Nonce        int64   `json:"nonce,omitempty"`
+	TxHash       string  `json:"tx_hash,omitempty"`
+}
+
+// keyState is the in-memory form of State
+type keyState struct {
+	next    int64
+	pending map[int64]string // Pending nonce to tx hash, empty if unknown
+}
+
+// applyRecord replays a journal entry onto states
+func applyRecord(states map[nonceKey]*keyState, rec storeRecord) {
+	key := nonceKey{rec.AccountIndex, rec.ApiKeyIndex}
+
+	switch rec.Op {
+	case opResetAll:
+		for k := range states {
+			delete(states, k)
+		}
+		return
+	case opReset:
+		delete(states, key)
+		return
+	}
+
+	st, ok := states[key]
+	if !ok {
+		st = &keyState{next: -1, pending: make(map[int64]string)}
+		states[key] = st
+	}
+
+	switch rec.Op {
+	case opAllocate:
+		st.pending[rec.Nonce] = ""
+		st.next = rec.Nonce + 1
+	case opRelease:
+		delete(st.pending, rec.Nonce)
+	case opTxHash:
+		if _, ok := st.pending[rec.Nonce]; ok {
+			st.pending[rec.Nonce] = rec.TxHash
+		}
+	case opSetNext:
+		st.next = rec.Nonce
+	}
+}
+
+// snapshot returns the journal entries that rebuild states
+func snapshot(states map[nonceKey]*keyState) []storeRecord {
+	var recs []storeRecord
+	for key, st := range states {
+		for n, txHash := range st.pending {
+			recs = append(recs, storeRecord{Op: opAllocate, AccountIndex: key.accountIndex, ApiKeyIndex: key.apiKeyIndex, Nonce: n})
+			if txHash != "" {
+				recs = append(recs, storeRecord{Op: opTxHash, AccountIndex: key.accountIndex, ApiKeyIndex: key.apiKeyIndex, Nonce: n, TxHash: txHash})
+			}
+		}
+		recs = append(recs, storeRecord{Op: opSetNext, AccountIndex: key.accountIndex, ApiKeyIndex: key.apiKeyIndex, Nonce: st.next})
+	}
+	return recs
+}
+
+// exportStates converts states to their exported form
+func exportStates(states map[nonceKey]*keyState) []State {
+	result := make([]State, 0, len(states))
+	for key, st := range states {
+		s := State{
+			AccountIndex: key.accountIndex,
+			ApiKeyIndex:  key.apiKeyIndex,
+			Next:         st.next,
+		}
+		for n, txHash := range st.pending {
+			s.Pending = append(s.Pending, n)
+			if txHash != "" {
+				if s.TxHashes == nil {
+					s.TxHashes = make(map[int64]string)
+				}
+				s.TxHashes[n] = txHash
+			}
+		}
+		result = append(result, s)
+	}
+	return result
+}
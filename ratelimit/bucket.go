@@ -0,0 +1,48 @@
+package ratelimit
+
+import (
+	"time"
+)
+
+// bucket is a token bucket refilled continuously at rate tokens per second.
+// It is not safe for concurrent use; Limiter serializes access.
+type bucket struct {
+	capacity float64
+	rate     float64 // tokens per second
+	tokens   float64
+	last     time.Time
+}
+
+func newBucket(limit Limit, now time.Time) *bucket {
+	capacity := float64(limit.Weight)
+	return &bucket{
+		capacity: capacity,
+		rate:     capacity / limit.Per.Seconds(),
+		tokens:   capacity,
+		last:     now,
+	}
+}
+
+// refill adds the tokens accumulated since the last call
+func (b *bucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * b.rate
+		if b.tokens > b.capacity {
+			b.tokens = b.capacity
+		}
+	}
+	b.last = now
+}
+
+// wait returns how long until n tokens are available while keeping reserve tokens untouched
+func (b *bucket) wait(n, reserve float64) time.Duration {
+	missing := n + reserve - b.tokens
+	if missing <= 0 {
+		return 0
+	}
+	return time.Duration(missing / b.rate * float64(time.Second))
+}
+
+func (b *bucket) take(n float64) {
+	b.tokens -= n
+}
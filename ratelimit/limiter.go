@@ -0,0 +1,263 @@
+// Package ratelimit provides a client-side, weight-based rate limiter matched to
+// Lighter's request limits.
+//
+// A Limiter holds one token bucket for the IP and one per account. Every request
+// is charged its endpoint weight against the IP bucket; transaction submissions are
+// additionally charged against the bucket of the submitting account, which the HTTP
+// and WebSocket clients share when given the same Limiter.
+//
+// Usage:
+//
+//	limiter := ratelimit.New(ratelimit.DefaultConfig())
+//	httpClient := http.NewFullClientWithOptions(url, http.WithRateLimiter(limiter))
+//	wsClient := ws.NewClient(wsURL, ws.DefaultOptions().WithRateLimiter(limiter, accountIndex))
+//
+//	// Shed optional work when the budget runs low
+//	ctx = ratelimit.WithPriority(ctx, ratelimit.PriorityLow)
+//	stats, err := httpClient.WithContext(ctx).Order().GetExchangeStats()
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrRateLimited is returned in FailFast mode when the budget is exhausted
+	ErrRateLimited = errors.New("client-side rate limit exceeded")
+	// ErrWeightExceedsCapacity is returned when a single request weighs more than a bucket can hold
+	ErrWeightExceedsCapacity = errors.New("request weight exceeds rate limit capacity")
+)
+
+// NoAccount is passed as account index for requests that only count against the IP budget
+const NoAccount int64 = -1
+
+// Mode selects what happens when the budget is exhausted
+type Mode int
+
+const (
+	// Block waits until enough budget is available or the context is done
+	Block Mode = iota
+	// FailFast returns ErrRateLimited immediately
+	FailFast
+)
+
+// Priority of a request. Low priority requests may not consume the reserved share of the budget.
+type Priority int
+
+const (
+	PriorityNormal Priority = iota
+	PriorityLow
+)
+
+// Limit is a request weight allowed per time window
+type Limit struct {
+	Weight int
+	Per    time.Duration
+}
+
+// Config configures a Limiter
+type Config struct {
+	IP      Limit // Budget shared by every request
+	Account Limit // Budget of each account, charged by transaction submissions
+
+	Weights       map[string]int // Endpoint weights, see DefaultWeights
+	DefaultWeight int            // Weight of endpoints missing from Weights
+
+	Mode Mode
+
+	// LowPriorityReserve is the fraction of each bucket, in [0, 1), that low priority
+	// requests may not consume, keeping headroom for order flow.
+	LowPriorityReserve float64
+}
+
+// DefaultConfig returns a blocking configuration for premium accounts:
+// 24000 weight per minute per IP and per account, with 20% reserved for normal priority requests
+func DefaultConfig() Config {
+	return Config{
+		IP:                 Limit{Weight: 24000, Per: time.Minute},
+		Account:            Limit{Weight: 24000, Per: time.Minute},
+		Weights:            DefaultWeights(),
+		DefaultWeight:      DefaultWeight,
+		Mode:               Block,
+		LowPriorityReserve: 0.2,
+	}
+}
+
+// Budget is the remaining weight in the buckets a request would be charged against
+type Budget struct {
+	IP      float64
+	Account float64 // Equal to IP when no account was given
+}
+
+// Limiter is a weight-based token bucket rate limiter. It is safe for concurrent use.
+type Limiter struct {
+	mu       sync.Mutex
+	cfg      Config
+	ip       *bucket
+	accounts map[int64]*bucket
+	now      func() time.Time
+}
+
+// New creates a new Limiter
+func New(cfg Config) *Limiter {
+	if cfg.Weights == nil {
+		cfg.Weights = DefaultWeights()
+	}
+	if cfg.DefaultWeight == 0 {
+		cfg.DefaultWeight = DefaultWeight
+	}
+	l := &Limiter{
+		cfg:      cfg,
+		accounts: make(map[int64]*bucket),
+		now:      time.Now,
+	}
+	l.ip = newBucket(cfg.IP, l.now())
+	return l
+}
+
+// Weight returns the weight charged for an endpoint
+func (l *Limiter) Weight(endpoint string) int {
+	if w, ok := l.cfg.Weights[strings.TrimPrefix(endpoint, "/")]; ok {
+		return w
+	}
+	return l.cfg.DefaultWeight
+}
+
+// Acquire charges the endpoint weight against the IP bucket and, if accountIndex is not
+// NoAccount, against that account's bucket. In Block mode it waits until the budget is
+// available or ctx is done; in FailFast mode it returns ErrRateLimited instead.
+// The request priority is read from ctx, see WithPriority.
+func (l *Limiter) Acquire(ctx context.Context, endpoint string, accountIndex int64) error {
+	return l.AcquireN(ctx, endpoint, accountIndex, 1)
+}
+
+// AcquireN is like Acquire, but charges n times the endpoint weight. It is used for
+// batch endpoints, which are weighted per transaction.
+func (l *Limiter) AcquireN(ctx context.Context, endpoint string, accountIndex int64, n int) error {
+	weight := float64(l.Weight(endpoint) * max(n, 1))
+	low := PriorityFromContext(ctx) == PriorityLow
+
+	for {
+		wait, err := l.tryAcquire(weight, accountIndex, low)
+		if err != nil || wait == 0 {
+			return err
+		}
+		if l.cfg.Mode == FailFast {
+			return ErrRateLimited
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// tryAcquire takes weight from all relevant buckets if they can afford it,
+// otherwise it returns how long to wait before trying again
+func (l *Limiter) tryAcquire(weight float64, accountIndex int64, low bool) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	buckets := []*bucket{l.ip}
+	if accountIndex != NoAccount {
+		buckets = append(buckets, l.accountBucket(accountIndex, now))
+	}
+
+	var wait time.Duration
+	for _, b := range buckets {
+		if weight > b.capacity {
+			return 0, ErrWeightExceedsCapacity
+		}
+		b.refill(now)
+
+		reserve := 0.0
+		if low {
+			reserve = b.capacity * l.cfg.LowPriorityReserve
+		}
+		if w := b.wait(weight, reserve); w > wait {
+			wait = w
+		}
+	}
+	if wait > 0 {
+		return wait, nil
+	}
+
+	for _, b := range buckets {
+		b.take(weight)
+	}
+	return 0, nil
+}
+
+// Remaining returns the weight left in the IP bucket and, if accountIndex is
+// not NoAccount, in that account's bucket
+func (l *Limiter) Remaining(accountIndex int64) Budget {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.ip.refill(now)
+	budget := Budget{IP: l.ip.tokens, Account: l.ip.tokens}
+	if accountIndex != NoAccount {
+		b := l.accountBucket(accountIndex, now)
+		b.refill(now)
+		budget.Account = b.tokens
+	}
+	return budget
+}
+
+// CanAfford reports whether a request to endpoint would currently be admitted without waiting
+func (l *Limiter) CanAfford(endpoint string, accountIndex int64, priority Priority) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	weight := float64(l.Weight(endpoint))
+	now := l.now()
+	buckets := []*bucket{l.ip}
+	if accountIndex != NoAccount {
+		buckets = append(buckets, l.accountBucket(accountIndex, now))
+	}
+	for _, b := range buckets {
+		b.refill(now)
+		reserve := 0.0
+		if priority == PriorityLow {
+			reserve = b.capacity * l.cfg.LowPriorityReserve
+		}
+		if b.wait(weight, reserve) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (l *Limiter) accountBucket(accountIndex int64, now time.Time) *bucket {
+	b, ok := l.accounts[accountIndex]
+	if !ok {
+		b = newBucket(l.cfg.Account, now)
+		l.accounts[accountIndex] = b
+	}
+	return b
+}
+
+type priorityKey struct{}
+
+// WithPriority returns a context carrying the request priority
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// PriorityFromContext returns the priority carried by ctx, PriorityNormal if none
+func PriorityFromContext(ctx context.Context) Priority {
+	if p, ok := ctx.Value(priorityKey{}).(Priority); ok {
+		return p
+	}
+	return PriorityNormal
+}
@@ -0,0 +1,195 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// newTestLimiter creates a limiter driven by a manual clock
+func newTestLimiter(cfg Config) (*Limiter, *time.Time) {
+	now := time.Unix(0, 0)
+	l := New(cfg)
+	l.now = func() time.Time { return now }
+	l.ip.last = now
+	return l, &now
+}
+
+func testConfig(mode Mode) Config {
+	return Config{
+		IP:      Limit{Weight: 100, Per: time.Second},
+		Account: Limit{Weight: 20, Per: time.Second},
+		Weights: map[string]int{
+			EndpointSendTx: 10,
+			"api/v1/read":  50,
+		},
+		DefaultWeight: 30,
+		Mode:          mode,
+	}
+}
+
+func TestLimiter_Weight(t *testing.T) {
+	l := New(testConfig(FailFast))
+
+	if w := l.Weight("/api/v1/sendTx"); w != 10 {
+		t.Errorf("expected weight 10, got %d", w)
+	}
+	if w := l.Weight("api/v1/unknown"); w != 30 {
+		t.Errorf("expected default weight 30, got %d", w)
+	}
+}
+
+func TestLimiter_FailFast(t *testing.T) {
+	l, _ := newTestLimiter(testConfig(FailFast))
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if err := l.Acquire(ctx, "api/v1/read", NoAccount); err != nil {
+			t.Fatalf("Acquire %d failed: %v", i, err)
+		}
+	}
+
+	err := l.Acquire(ctx, "api/v1/read", NoAccount)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("expected ErrRateLimited, got %v", err)
+	}
+}
+
+func TestLimiter_Refill(t *testing.T) {
+	l, now := newTestLimiter(testConfig(FailFast))
+	ctx := context.Background()
+
+	_ = l.Acquire(ctx, "api/v1/read", NoAccount)
+	_ = l.Acquire(ctx, "api/v1/read", NoAccount)
+
+	*now = now.Add(500 * time.Millisecond)
+	if err := l.Acquire(ctx, "api/v1/read", NoAccount); err != nil {
+		t.Errorf("expected budget after refill, got %v", err)
+	}
+	if err := l.Acquire(ctx, "api/v1/read", NoAccount); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("expected ErrRateLimited, got %v", err)
+	}
+}
+
+func TestLimiter_AccountBuckets(t *testing.T) {
+	l, _ := newTestLimiter(testConfig(FailFast))
+	ctx := context.Background()
+
+	// Account 1 can afford two submissions
+	for i := 0; i < 2; i++ {
+		if err := l.Acquire(ctx, EndpointSendTx, 1); err != nil {
+			t.Fatalf("Acquire %d failed: %v", i, err)
+		}
+	}
+	if err := l.Acquire(ctx, EndpointSendTx, 1); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("expected ErrRateLimited for account 1, got %v", err)
+	}
+
+	// Account 2 has its own budget
+	if err := l.Acquire(ctx, EndpointSendTx, 2); err != nil {
+		t.Errorf("expected account 2 to be admitted, got %v", err)
+	}
+
+	budget := l.Remaining(1)
+	if budget.Account != 0 {
+		t.Errorf("expected account budget 0, got %v", budget.Account)
+	}
+	if budget.IP != 70 {
+		t.Errorf("expected IP budget 70, got %v", budget.IP)
+	}
+}
+
+func TestLimiter_RejectedRequestDoesNotCharge(t *testing.T) {
+	l, _ := newTestLimiter(testConfig(FailFast))
+	ctx := context.Background()
+
+	_ = l.Acquire(ctx, EndpointSendTx, 1)
+	_ = l.Acquire(ctx, EndpointSendTx, 1)
+	_ = l.Acquire(ctx, EndpointSendTx, 1) // rejected by the account bucket
+
+	if ip := l.Remaining(NoAccount).IP; ip != 80 {
+		t.Errorf("expected IP budget 80, got %v", ip)
+	}
+}
+
+func TestLimiter_LowPriorityReserve(t *testing.T) {
+	cfg := testConfig(FailFast)
+	cfg.LowPriorityReserve = 0.5
+	l, _ := newTestLimiter(cfg)
+
+	low := WithPriority(context.Background(), PriorityLow)
+	if err := l.Acquire(low, "api/v1/read", NoAccount); err != nil {
+		t.Fatalf("expected first low priority request to be admitted, got %v", err)
+	}
+	if l.CanAfford("api/v1/read", NoAccount, PriorityLow) {
+		t.Error("expected low priority request to be shed")
+	}
+	if err := l.Acquire(low, "api/v1/read", NoAccount); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("expected ErrRateLimited, got %v", err)
+	}
+
+	// Normal priority may use the reserve
+	if err := l.Acquire(context.Background(), "api/v1/read", NoAccount); err != nil {
+		t.Errorf("expected normal priority request to be admitted, got %v", err)
+	}
+}
+
+func TestLimiter_AcquireN(t *testing.T) {
+	l, _ := newTestLimiter(testConfig(FailFast))
+	ctx := context.Background()
+
+	if err := l.AcquireN(ctx, EndpointSendTx, 1, 2); err != nil {
+		t.Fatalf("AcquireN failed: %v", err)
+	}
+	if budget := l.Remaining(1); budget.IP != 80 || budget.Account != 0 {
+		t.Errorf("expected a batch of 2 charged twice, got %+v", budget)
+	}
+	if err := l.AcquireN(ctx, EndpointSendTx, 2, 3); !errors.Is(err, ErrWeightExceedsCapacity) {
+		t.Errorf("expected a batch of 3 to exceed the account capacity, got %v", err)
+	}
+}
+
+func TestLimiter_WeightExceedsCapacity(t *testing.T) {
+	l := New(testConfig(Block))
+
+	err := l.Acquire(context.Background(), "api/v1/read", 1)
+	if !errors.Is(err, ErrWeightExceedsCapacity) {
+		t.Errorf("expected ErrWeightExceedsCapacity, got %v", err)
+	}
+}
+
+func TestLimiter_BlockWaits(t *testing.T) {
+	cfg := testConfig(Block)
+	cfg.IP = Limit{Weight: 50, Per: 50 * time.Millisecond}
+	l := New(cfg)
+	ctx := context.Background()
+
+	if err := l.Acquire(ctx, "api/v1/read", NoAccount); err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	start := time.Now()
+	if err := l.Acquire(ctx, "api/v1/read", NoAccount); err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("expected Acquire to block, returned after %v", elapsed)
+	}
+}
+
+func TestLimiter_BlockHonorsContext(t *testing.T) {
+	cfg := testConfig(Block)
+	cfg.IP = Limit{Weight: 50, Per: time.Hour}
+	l := New(cfg)
+
+	_ = l.Acquire(context.Background(), "api/v1/read", NoAccount)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := l.Acquire(ctx, "api/v1/read", NoAccount)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+package ratelimit
+
+// Endpoint names used for weight lookups. HTTP endpoints are identified by their path
+// without leading slash; WebSocket transaction frames by their message type.
+const (
+	EndpointSendTx      = "api/v1/sendTx"
+	EndpointSendTxBatch = "api/v1/sendTxBatch"
+	EndpointNextNonce   = "api/v1/nextNonce"
+
+	EndpointWsSendTx      = "jsonapi/sendtx"
+	EndpointWsSendTxBatch = "jsonapi/sendtxbatch"
+)
+
+// DefaultWeight is charged for endpoints missing from the weight table
+const DefaultWeight = 300
+
+// DefaultWeights returns the per-endpoint weights published by Lighter.
+// Transaction submission is cheap so that order flow is not starved by reads.
+//
+// Batch endpoints are weighted per transaction: a batch of n transactions is charged
+// n times its weight, the same as sending them one by one, since each of them counts
+// against the account's transaction limit.
+func DefaultWeights() map[string]int {
+	return map[string]int{
+		EndpointSendTx:      6,
+		EndpointSendTxBatch: 6,
+		EndpointNextNonce:   6,
+
+		EndpointWsSendTx:      6,
+		EndpointWsSendTxBatch: 6,
+
+		"":                             100,
+		"info":                         100,
+		"api/v1/publicPoolsMetadata":   50,
+		"api/v1/txFromL1TxHash":        50,
+		"api/v1/candlesticks":          50,
+		"api/v1/accountInactiveOrders": 100,
+		"api/v1/apikeys":               150,
+		"api/v1/transferFeeInfo":       500,
+	}
+}
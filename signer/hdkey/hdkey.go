@@ -0,0 +1,185 @@
+// Package hdkey derives Lighter API keys deterministically from one master secret, so
+// every key of every account can be regenerated if the keys themselves are lost.
+//
+// The master is created from a random seed, or from an Ethereum signature of
+// DerivationMessage, so the L1 wallet owning the accounts is the only secret to back up.
+// Keys are derived along the path m/account/apiKey with HMAC-SHA512, in the spirit of
+// BIP-32 hardened derivation: a key reveals nothing about its siblings or its parent.
+//
+// Each node is a 32-byte key and a 32-byte chain code, the halves of an HMAC-SHA512 output:
+//
+//	m       = HMAC-SHA512("Lighter API key seed", seed)
+//	child   = HMAC-SHA512(chainCode, key || label || uint64be(index)), label 0x01 for accounts, 0x02 for api keys
+//	private = uint512be(HMAC-SHA512(chainCode, key || 0x03 || uint64be(0))) mod n, 40 bytes little-endian
+//
+// where n is the order of the ECgFp5 scalar field. For Ethereum signatures, the seed is the
+// 65-byte signature with V as 27/28.
+//
+// Example:
+//
+//	master, err := hdkey.NewMasterFromL1Signer(l1Signer)
+//	privateKey, publicKey, err := master.Derive(accountIndex, apiKeyIndex)
+//	km, err := master.KeyManager(accountIndex, apiKeyIndex)
+package hdkey
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	curve "github.com/elliottech/poseidon_crypto/curve/ecgfp5"
+	schnorr "github.com/elliottech/poseidon_crypto/signature/schnorr"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/0xJord4n/lighter-go/signer"
+)
+
+const (
+	// DerivationMessage is the message signed by an L1 wallet to create a master with
+	// NewMasterFromL1Signer. Changing it changes every derived key.
+	DerivationMessage = "Lighter API key derivation\n\n" +
+		"Signing this message derives the API keys of your Lighter accounts.\n" +
+		"Only sign it in applications you trust with those keys."
+
+	// MinSeedLength is the minimum length of a master seed
+	MinSeedLength = 16
+	// SeedLength is the length of seeds created by GenerateSeed
+	SeedLength = 32
+
+	// masterKey is the HMAC key of the master node, as "Bitcoin seed" in BIP-32
+	masterKey = "Lighter API key seed"
+
+	labelAccount = 0x01
+	labelAPIKey  = 0x02
+	labelScalar  = 0x03
+)
+
+var (
+	// ErrSeedTooShort is returned for seeds shorter than MinSeedLength
+	ErrSeedTooShort = errors.New("seed is too short")
+	// ErrInvalidAccountIndex is returned for negative account indices
+	ErrInvalidAccountIndex = errors.New("invalid account index")
+)
+
+// node is a point of the derivation path
+type node struct {
+	key       [32]byte
+	chainCode [32]byte
+}
+
+// Master derives the API keys of any account and api key index
+type Master struct {
+	root node
+}
+
+// GenerateSeed returns a random seed for NewMaster
+func GenerateSeed() ([]byte, error) {
+	seed := make([]byte, SeedLength)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+
+// NewMaster creates a master from a secret seed of at least MinSeedLength bytes
+func NewMaster(seed []byte) (*Master, error) {
+	if len(seed) < MinSeedLength {
+		return nil, ErrSeedTooShort
+	}
+	mac := hmac.New(sha512.New, []byte(masterKey))
+	mac.Write(seed)
+	return &Master{root: newNode(mac.Sum(nil))}, nil
+}
+
+// NewMasterFromSignature creates a master from the hex-encoded Ethereum signature of
+// DerivationMessage, made with EIP-191 personal sign. V may be 0/1 or 27/28.
+func NewMasterFromSignature(signature string) (*Master, error) {
+	signature, err := signer.NormalizeL1Signature(signature)
+	if err != nil {
+		return nil, err
+	}
+	b, err := hexutil.Decode(signature)
+	if err != nil {
+		return nil, err
+	}
+	return NewMaster(b)
+}
+
+// NewMasterFromL1Signer creates a master from l1Signer's signature of DerivationMessage.
+// The signature must be deterministic (RFC 6979), as it is for go-ethereum and hardware
+// wallets; the signature is verified against the signer's address.
+func NewMasterFromL1Signer(l1Signer signer.L1Signer) (*Master, error) {
+	signature, err := l1Signer.Sign(DerivationMessage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign derivation message: %w", err)
+	}
+	if err := signer.VerifyL1Signature(DerivationMessage, signature, l1Signer.Address()); err != nil {
+		return nil, err
+	}
+	return NewMasterFromSignature(signature)
+}
+
+// Derive returns the hex-encoded private and public key of an api key, in the format of
+// client.GenerateAPIKey
+func (m *Master) Derive(accountIndex int64, apiKeyIndex uint8) (string, string, error) {
+	key, err := m.scalar(accountIndex, apiKeyIndex)
+	if err != nil {
+		return "", "", err
+	}
+	privateKeyStr := hexutil.Encode(key.ToLittleEndianBytes())
+	publicKeyStr := hexutil.Encode(schnorr.SchnorrPkFromSk(key).ToLittleEndianBytes())
+	return privateKeyStr, publicKeyStr, nil
+}
+
+// KeyManager returns the key manager of an api key
+func (m *Master) KeyManager(accountIndex int64, apiKeyIndex uint8) (signer.KeyManager, error) {
+	key, err := m.scalar(accountIndex, apiKeyIndex)
+	if err != nil {
+		return nil, err
+	}
+	return signer.NewKeyManager(key.ToLittleEndianBytes())
+}
+
+// scalar derives the private key at m/accountIndex/apiKeyIndex
+func (m *Master) scalar(accountIndex int64, apiKeyIndex uint8) (curve.ECgFp5Scalar, error) {
+	if accountIndex < 0 {
+		return curve.ECgFp5Scalar{}, ErrInvalidAccountIndex
+	}
+	n := m.root.child(labelAccount, uint64(accountIndex)).child(labelAPIKey, uint64(apiKeyIndex))
+
+	// 512 bits reduced modulo the ~2^319 group order, so the bias is negligible
+	out := n.hmac(labelScalar, 0)
+	key := curve.FromNonCanonicalBigInt(new(big.Int).SetBytes(out))
+	if key.IsZero() {
+		return curve.ECgFp5Scalar{}, fmt.Errorf("derived a zero key for account %d api key %d", accountIndex, apiKeyIndex)
+	}
+	return key, nil
+}
+
+func newNode(out []byte) node {
+	var n node
+	copy(n.key[:], out[:32])
+	copy(n.chainCode[:], out[32:])
+	return n
+}
+
+// child derives the node at index below n
+func (n node) child(label byte, index uint64) node {
+	return newNode(n.hmac(label, index))
+}
+
+// hmac returns HMAC-SHA512(chainCode, key || label || index)
+func (n node) hmac(label byte, index uint64) []byte {
+	var data [32 + 1 + 8]byte
+	copy(data[:32], n.key[:])
+	data[32] = label
+	binary.BigEndian.PutUint64(data[33:], index)
+
+	mac := hmac.New(sha512.New, n.chainCode[:])
+	mac.Write(data[:])
+	return mac.Sum(nil)
+}
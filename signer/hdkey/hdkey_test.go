@@ -0,0 +1,126 @@
+package hdkey
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/0xJord4n/lighter-go/signer"
+)
+
+const testEthKey = "0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+
+func testSeed() []byte {
+	seed := make([]byte, 32)
+	for i := range seed {
+		seed[i] = byte(i)
+	}
+	return seed
+}
+
+func TestMaster_Vectors(t *testing.T) {
+	master, err := NewMaster(testSeed())
+	if err != nil {
+		t.Fatalf("NewMaster failed: %v", err)
+	}
+
+	// The private key at m/7/2 was reproduced independently of this package with Python's
+	// hmac and hashlib, following the derivation in the package doc:
+	//
+	//	import hmac, hashlib
+	//	n = 1067993516717146951041484916571792702745057740581727230159139685185762082554198619328292418486241
+	//	def node(out): return out[:32], out[32:]
+	//	def mac(key, chain, label, index):
+	//	    return hmac.new(chain, key + bytes([label]) + index.to_bytes(8, "big"), hashlib.sha512).digest()
+	//	key, chain = node(hmac.new(b"Lighter API key seed", bytes(range(32)), hashlib.sha512).digest())
+	//	key, chain = node(mac(key, chain, 0x01, 7))
+	//	key, chain = node(mac(key, chain, 0x02, 2))
+	//	scalar = int.from_bytes(mac(key, chain, 0x03, 0), "big") % n
+	//	print("0x" + scalar.to_bytes(40, "little").hex())
+	//	# 0x8ec30c55ba7d2660e065e22729516fcc805ae3b09129aceec5065436fb8cbd603609465f56e5d728
+	//
+	// The public keys and the other vectors are regression fixtures recorded from this
+	// implementation, so that a change of the derivation is noticed.
+	vectors := []struct {
+		accountIndex int64
+		apiKeyIndex  uint8
+		privateKey   string
+		publicKey    string
+	}{
+		{0, 0, "0xf2d4064ea58d0aefa2c86df2dc2364895ca2fa1f474c15115b5162ca0e27bc69be180902ca6b3b1e", "0x215feaa0b7aa0d019ff2b84bfdb472518bb289bcf1e14cb1ad95cad84429526cc577265acb850b58"},
+		{0, 1, "0xffebd8b6f7579d46d5b11a76b928adb0905c468087f1d39bd3cbe0af3dbc5a5b3df221de1d3fc250", "0xf950c0a74428edb0b04b31fc941d73935be10219ba09e6e196ea7d2489d9c12453d02c54b3c56b27"},
+		{7, 2, "0x8ec30c55ba7d2660e065e22729516fcc805ae3b09129aceec5065436fb8cbd603609465f56e5d728", "0xf194f7dc461ccc242c53ddbe9002a60d94567dd16bca117a04fe9af0923e328b50ff6d6eb8e045eb"},
+		{281474976710655, 254, "0x1304f8236e3740ad03c1606edcbbaa736e8ad0e40632a352fb829ddf5d9f47e5f4dfc2b2ba53b635", "0x683450be47a3825a304ade46f89c89c18e706070c5c992699495ee03198dc910fd9718bb947fa49d"},
+	}
+	for _, v := range vectors {
+		privateKey, publicKey, err := master.Derive(v.accountIndex, v.apiKeyIndex)
+		if err != nil {
+			t.Fatalf("Derive(%d, %d) failed: %v", v.accountIndex, v.apiKeyIndex, err)
+		}
+		if privateKey != v.privateKey || publicKey != v.publicKey {
+			t.Errorf("Derive(%d, %d): expected %s / %s, got %s / %s", v.accountIndex, v.apiKeyIndex, v.privateKey, v.publicKey, privateKey, publicKey)
+		}
+
+		km, err := master.KeyManager(v.accountIndex, v.apiKeyIndex)
+		if err != nil {
+			t.Fatalf("KeyManager failed: %v", err)
+		}
+		if pk := km.PubKeyBytes(); hexutil.Encode(pk[:]) != v.publicKey {
+			t.Errorf("expected key manager with public key %s, got %x", v.publicKey, pk)
+		}
+	}
+}
+
+func TestMaster_FromL1Signer(t *testing.T) {
+	const (
+		signature  = "0x76e205929d085b6bcce991c1f4c1adaec9c0d6e62d606f1da820eb7c20d5677317396968a1718f255073e46cf69ca96e6d236fd0c2c8d4be654e750ed2db01621c"
+		privateKey = "0x4b13fdc2d2ec6403fe04ffaa104de997a92f8b68dbc59b91cdd73d932f2050d957df47249a37416b"
+		publicKey  = "0x3ea36e5446f7b2c5ef3f773a3c5083c49eadf11a24c7e455551204b6b3a17780772c2ef3cd602cf5"
+	)
+
+	l1Signer, _ := signer.NewL1Signer(testEthKey)
+	master, err := NewMasterFromL1Signer(l1Signer)
+	if err != nil {
+		t.Fatalf("NewMasterFromL1Signer failed: %v", err)
+	}
+	if p, q, _ := master.Derive(7, 2); p != privateKey || q != publicKey {
+		t.Errorf("expected %s / %s, got %s / %s", privateKey, publicKey, p, q)
+	}
+
+	// A wallet returning V as 0/1 derives the same keys
+	b := hexutil.MustDecode(signature)
+	b[64] -= 27
+	master, err = NewMasterFromSignature(hexutil.Encode(b))
+	if err != nil {
+		t.Fatalf("NewMasterFromSignature failed: %v", err)
+	}
+	if p, _, _ := master.Derive(7, 2); p != privateKey {
+		t.Errorf("expected %s, got %s", privateKey, p)
+	}
+}
+
+func TestMaster_Errors(t *testing.T) {
+	if _, err := NewMaster(make([]byte, MinSeedLength-1)); !errors.Is(err, ErrSeedTooShort) {
+		t.Errorf("expected ErrSeedTooShort, got %v", err)
+	}
+	if _, err := NewMasterFromSignature("0x1234"); !errors.Is(err, signer.ErrInvalidL1Signature) {
+		t.Errorf("expected ErrInvalidL1Signature, got %v", err)
+	}
+
+	master, _ := NewMaster(testSeed())
+	if _, _, err := master.Derive(-1, 0); !errors.Is(err, ErrInvalidAccountIndex) {
+		t.Errorf("expected ErrInvalidAccountIndex, got %v", err)
+	}
+
+	seed, err := GenerateSeed()
+	if err != nil || len(seed) != SeedLength {
+		t.Fatalf("GenerateSeed failed: %v", err)
+	}
+	other, _ := NewMaster(seed)
+	a, _, _ := master.Derive(7, 2)
+	b, _, _ := other.Derive(7, 2)
+	if a == b {
+		t.Error("expected different seeds to derive different keys")
+	}
+}
@@ -7,6 +7,8 @@ import (
 	curve "github.com/elliottech/poseidon_crypto/curve/ecgfp5"
 	gFp5 "github.com/elliottech/poseidon_crypto/field/goldilocks_quintic_extension"
 	schnorr "github.com/elliottech/poseidon_crypto/signature/schnorr"
+
+	"github.com/0xJord4n/lighter-go/types/txtypes"
 )
 
 type Signer interface {
@@ -20,6 +22,14 @@ type KeyManager interface {
 	PrvKeyBytes() []byte
 }
 
+// TxSigner is implemented by signers that need the transaction behind a hash, such as a
+// remote signing service checking it against a policy. Transactions and auth tokens are
+// signed with SignTx and SignAuthToken instead of Sign when the signer implements it.
+type TxSigner interface {
+	SignTx(tx txtypes.TxInfo, hashedMessage []byte) ([]byte, error)
+	SignAuthToken(message string, hashedMessage []byte) ([]byte, error)
+}
+
 type keyManager struct {
 	key curve.ECgFp5Scalar
 }
@@ -0,0 +1,482 @@
+// Package keystore stores Lighter API keys and Ethereum (L1) keys encrypted at rest,
+// in the spirit of go-ethereum's keystore: each key is sealed with AES-256-GCM under a
+// key derived from a passphrase with scrypt. One file holds any number of named keys,
+// e.g. the API keys of several accounts and the L1 key that owns them.
+//
+// Example:
+//
+//	ks, err := keystore.Open("lighter.keystore")
+//	err = ks.ImportAPIKey("main/0", accountIndex, 0, privateKeyHex, passphrase)
+//	km, err := ks.KeyManager("main/0", passphrase)
+//	l1, err := ks.L1Signer("owner", passphrase)
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/0xJord4n/lighter-go/signer"
+)
+
+const (
+	// StandardScryptN and StandardScryptP are the scrypt parameters of new keys, as in go-ethereum
+	StandardScryptN = 1 << 18
+	StandardScryptP = 1
+
+	// LightScryptN and LightScryptP use less memory and CPU, e.g. for tests or constrained devices
+	LightScryptN = 1 << 12
+	LightScryptP = 6
+
+	// MaxScryptN is the largest scrypt N accepted, which takes 1 GiB of memory with r = 8
+	MaxScryptN = 1 << 20
+	// MaxScryptP is the largest scrypt P accepted. The work of N·r·P is bounded as well,
+	// by that of MaxScryptN with r = 8 and P = 1.
+	MaxScryptP = 16
+
+	scryptR     = 8
+	scryptDKLen = 32
+
+	fileVersion = 1
+)
+
+// Kind is the type of a stored key
+type Kind string
+
+const (
+	KindAPIKey Kind = "lighter_api" // Lighter API (Schnorr) private key
+	KindL1     Kind = "ethereum"    // Ethereum private key
+)
+
+var (
+	ErrNotFound         = errors.New("key not found in keystore")
+	ErrKeyExists        = errors.New("a key with this name already exists")
+	ErrWrongPassphrase  = errors.New("wrong passphrase or corrupted key")
+	ErrWrongKind        = errors.New("key is of another kind")
+	ErrEmptyPassphrase  = errors.New("passphrase must not be empty")
+	ErrUnsupportedCrypt = errors.New("unsupported keystore cipher or kdf")
+)
+
+// Entry describes a stored key; the private key itself stays encrypted
+type Entry struct {
+	Name         string `json:"-"`
+	Kind         Kind   `json:"kind"`
+	AccountIndex int64  `json:"account_index,omitempty"` // API keys only
+	ApiKeyIndex  uint8  `json:"api_key_index,omitempty"` // API keys only
+	PublicKey    string `json:"public_key,omitempty"`    // Hex-encoded, API keys only
+	Address      string `json:"address,omitempty"`       // L1 keys only
+}
+
+// storedKey is an entry with its encrypted private key
+type storedKey struct {
+	Entry
+	Crypto cryptoJSON `json:"crypto"`
+}
+
+type cryptoJSON struct {
+	KDF        string       `json:"kdf"`
+	KDFParams  scryptParams `json:"kdfparams"`
+	Cipher     string       `json:"cipher"`
+	Nonce      string       `json:"nonce"`
+	CipherText string       `json:"ciphertext"`
+}
+
+type scryptParams struct {
+	N     int    `json:"n"`
+	R     int    `json:"r"`
+	P     int    `json:"p"`
+	DKLen int    `json:"dklen"`
+	Salt  string `json:"salt"`
+}
+
+type keystoreFile struct {
+	Version int                   `json:"version"`
+	Keys    map[string]*storedKey `json:"keys"`
+}
+
+// Keystore is a file of encrypted keys. Changes are written to disk immediately.
+type Keystore struct {
+	path    string
+	scryptN int
+	scryptP int
+
+	mu   sync.RWMutex
+	keys map[string]*storedKey
+}
+
+// Open loads the keystore at path; the file is created on the first import
+func Open(path string) (*Keystore, error) {
+	ks := &Keystore{
+		path:    path,
+		scryptN: StandardScryptN,
+		scryptP: StandardScryptP,
+		keys:    make(map[string]*storedKey),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ks, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore: %w", err)
+	}
+	var f keystoreFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse keystore: %w", err)
+	}
+	if f.Version != fileVersion {
+		return nil, fmt.Errorf("unsupported keystore version %d", f.Version)
+	}
+	for name, key := range f.Keys {
+		key.Name = name
+		ks.keys[name] = key
+	}
+	return ks, nil
+}
+
+// SetScryptParams sets the scrypt cost of keys imported from now on.
+// Imports fail if the parameters are out of the bounds decryption accepts.
+func (ks *Keystore) SetScryptParams(n, p int) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.scryptN = n
+	ks.scryptP = p
+}
+
+// Path returns the file of the keystore
+func (ks *Keystore) Path() string {
+	return ks.path
+}
+
+// List returns the stored keys, sorted by name
+func (ks *Keystore) List() []Entry {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	entries := make([]Entry, 0, len(ks.keys))
+	for _, key := range ks.keys {
+		entries = append(entries, key.Entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries
+}
+
+// Get returns the entry of a stored key
+func (ks *Keystore) Get(name string) (Entry, bool) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	key, ok := ks.keys[name]
+	if !ok {
+		return Entry{}, false
+	}
+	return key.Entry, true
+}
+
+// FindAPIKey returns the entry of the API key of an account and api key index
+func (ks *Keystore) FindAPIKey(accountIndex int64, apiKeyIndex uint8) (Entry, bool) {
+	for _, entry := range ks.List() {
+		if entry.Kind == KindAPIKey && entry.AccountIndex == accountIndex && entry.ApiKeyIndex == apiKeyIndex {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+// ImportAPIKey encrypts and stores a hex-encoded Lighter API private key
+func (ks *Keystore) ImportAPIKey(name string, accountIndex int64, apiKeyIndex uint8, privateKeyHex, passphrase string) error {
+	b, err := decodeHex(privateKeyHex)
+	if err != nil {
+		return err
+	}
+	defer clear(b)
+
+	km, err := signer.NewKeyManager(b)
+	if err != nil {
+		return err
+	}
+	pk := km.PubKeyBytes()
+	return ks.store(name, Entry{
+		Kind:         KindAPIKey,
+		AccountIndex: accountIndex,
+		ApiKeyIndex:  apiKeyIndex,
+		PublicKey:    hex.EncodeToString(pk[:]),
+	}, b, passphrase)
+}
+
+// ImportL1Key encrypts and stores a hex-encoded Ethereum private key
+func (ks *Keystore) ImportL1Key(name, privateKeyHex, passphrase string) error {
+	l1, err := signer.NewL1Signer(privateKeyHex)
+	if err != nil {
+		return err
+	}
+	b, err := decodeHex(privateKeyHex)
+	if err != nil {
+		return err
+	}
+	defer clear(b)
+
+	return ks.store(name, Entry{Kind: KindL1, Address: l1.Address()}, b, passphrase)
+}
+
+// KeyManager decrypts a stored API key
+func (ks *Keystore) KeyManager(name, passphrase string) (signer.KeyManager, error) {
+	b, err := ks.decrypt(name, KindAPIKey, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(b)
+	return signer.NewKeyManager(b)
+}
+
+// L1Signer decrypts a stored Ethereum key
+func (ks *Keystore) L1Signer(name, passphrase string) (signer.L1Signer, error) {
+	b, err := ks.decrypt(name, KindL1, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(b)
+	l1, err := signer.NewL1Signer(hex.EncodeToString(b))
+	if err != nil {
+		return nil, err
+	}
+	return l1, nil
+}
+
+// PrivateKeyHex decrypts a stored key to the 0x-prefixed hex form taken by NewSignerClient
+func (ks *Keystore) PrivateKeyHex(name, passphrase string) (string, error) {
+	b, err := ks.decrypt(name, "", passphrase)
+	if err != nil {
+		return "", err
+	}
+	defer clear(b)
+	return "0x" + hex.EncodeToString(b), nil
+}
+
+// Delete removes a stored key
+func (ks *Keystore) Delete(name string) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	key, ok := ks.keys[name]
+	if !ok {
+		return ErrNotFound
+	}
+	delete(ks.keys, name)
+	if err := ks.save(); err != nil {
+		ks.keys[name] = key
+		return err
+	}
+	return nil
+}
+
+func (ks *Keystore) store(name string, entry Entry, privateKey []byte, passphrase string) error {
+	if passphrase == "" {
+		return ErrEmptyPassphrase
+	}
+	entry.Name = name
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	if _, exists := ks.keys[name]; exists {
+		return fmt.Errorf("%w: %s", ErrKeyExists, name)
+	}
+	c, err := encrypt(privateKey, passphrase, additionalData(entry), ks.scryptN, ks.scryptP)
+	if err != nil {
+		return err
+	}
+	ks.keys[name] = &storedKey{Entry: entry, Crypto: *c}
+	if err := ks.save(); err != nil {
+		delete(ks.keys, name)
+		return err
+	}
+	return nil
+}
+
+// decrypt returns the private key of name, checking its kind unless kind is empty
+func (ks *Keystore) decrypt(name string, kind Kind, passphrase string) ([]byte, error) {
+	ks.mu.RLock()
+	key, ok := ks.keys[name]
+	ks.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if kind != "" && key.Kind != kind {
+		return nil, fmt.Errorf("%w: %s is a %s key", ErrWrongKind, name, key.Kind)
+	}
+	return decrypt(&key.Crypto, passphrase, additionalData(key.Entry))
+}
+
+// save writes the keystore atomically, readable by its owner only
+func (ks *Keystore) save() error {
+	data, err := json.MarshalIndent(&keystoreFile{Version: fileVersion, Keys: ks.keys}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(ks.path), filepath.Base(ks.path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to write keystore: %w", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // Gone after the rename
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close() //nolint:errcheck // Returning the chmod error
+		return fmt.Errorf("failed to write keystore: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close() //nolint:errcheck // Returning the write error
+		return fmt.Errorf("failed to write keystore: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close() //nolint:errcheck // Returning the sync error
+		return fmt.Errorf("failed to sync keystore: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write keystore: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), ks.path); err != nil {
+		return fmt.Errorf("failed to write keystore: %w", err)
+	}
+	syncDir(filepath.Dir(ks.path))
+	return nil
+}
+
+// syncDir makes a rename in dir durable. Not all platforms support it, so errors are ignored.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	d.Sync()  //nolint:errcheck // Best effort
+	d.Close() //nolint:errcheck // Read-only handle
+}
+
+// additionalData binds the ciphertext to the name and metadata of its entry,
+// so entries cannot be swapped or relabeled in the file
+func additionalData(e Entry) []byte {
+	return []byte(strings.Join([]string{
+		e.Name, string(e.Kind),
+		strconv.FormatInt(e.AccountIndex, 10), strconv.Itoa(int(e.ApiKeyIndex)),
+		e.PublicKey, e.Address,
+	}, "\x00"))
+}
+
+func encrypt(plaintext []byte, passphrase string, aad []byte, n, p int) (*cryptoJSON, error) {
+	if err := checkScryptParams(n, scryptR, p); err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	derived, err := scrypt.Key([]byte(passphrase), salt, n, scryptR, p, scryptDKLen)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(derived)
+
+	gcm, err := newGCM(derived)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return &cryptoJSON{
+		KDF:        "scrypt",
+		KDFParams:  scryptParams{N: n, R: scryptR, P: p, DKLen: scryptDKLen, Salt: hex.EncodeToString(salt)},
+		Cipher:     "aes-256-gcm",
+		Nonce:      hex.EncodeToString(nonce),
+		CipherText: hex.EncodeToString(gcm.Seal(nil, nonce, plaintext, aad)),
+	}, nil
+}
+
+func decrypt(c *cryptoJSON, passphrase string, aad []byte) ([]byte, error) {
+	if c.KDF != "scrypt" || c.Cipher != "aes-256-gcm" || c.KDFParams.DKLen != scryptDKLen {
+		return nil, ErrUnsupportedCrypt
+	}
+	salt, err := hex.DecodeString(c.KDFParams.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid salt: %w", err)
+	}
+	nonce, err := hex.DecodeString(c.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("invalid nonce: %w", err)
+	}
+	ciphertext, err := hex.DecodeString(c.CipherText)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ciphertext: %w", err)
+	}
+
+	p := c.KDFParams
+	if err := checkScryptParams(p.N, p.R, p.P); err != nil {
+		return nil, err
+	}
+	derived, err := scrypt.Key([]byte(passphrase), salt, p.N, p.R, p.P, p.DKLen)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(derived)
+
+	gcm, err := newGCM(derived)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce length %d", len(nonce))
+	}
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, aad)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plaintext, nil
+}
+
+// checkScryptParams bounds the cost of a key derivation, so a tampered file can't make
+// decryption exhaust memory or CPU
+func checkScryptParams(n, r, p int) error {
+	if n <= 1 || n > MaxScryptN || n&(n-1) != 0 {
+		return fmt.Errorf("%w: scrypt n %d must be a power of two up to %d", ErrUnsupportedCrypt, n, MaxScryptN)
+	}
+	if r <= 0 || p <= 0 || p > MaxScryptP || uint64(r)*uint64(p) >= 1<<30 {
+		return fmt.Errorf("%w: scrypt r %d and p %d out of range", ErrUnsupportedCrypt, r, p)
+	}
+	if uint64(n)*uint64(r) > MaxScryptN*scryptR {
+		return fmt.Errorf("%w: scrypt n %d and r %d need more than 1 GiB", ErrUnsupportedCrypt, n, r)
+	}
+	if uint64(n)*uint64(r)*uint64(p) > MaxScryptN*scryptR {
+		return fmt.Errorf("%w: scrypt n %d, r %d and p %d take too long to derive", ErrUnsupportedCrypt, n, r, p)
+	}
+	return nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func decodeHex(s string) ([]byte, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(s), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex private key: %w", err)
+	}
+	return b, nil
+}
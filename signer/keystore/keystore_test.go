@@ -0,0 +1,182 @@
+package keystore
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/0xJord4n/lighter-go/client"
+	"github.com/0xJord4n/lighter-go/signer"
+)
+
+const (
+	testPassphrase = "correct horse battery staple"
+	testEthKey     = "0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+)
+
+func newTestKeystore(t *testing.T) (*Keystore, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "lighter.keystore")
+	ks, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	ks.SetScryptParams(LightScryptN, LightScryptP)
+	return ks, path
+}
+
+func TestKeystore_RoundTrip(t *testing.T) {
+	ks, path := newTestKeystore(t)
+	privateKey, publicKey, _ := client.GenerateAPIKey()
+
+	if err := ks.ImportAPIKey("main/2", 7, 2, privateKey, testPassphrase); err != nil {
+		t.Fatalf("ImportAPIKey failed: %v", err)
+	}
+	if err := ks.ImportL1Key("owner", testEthKey, testPassphrase); err != nil {
+		t.Fatalf("ImportL1Key failed: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected a 0600 keystore file, got %v, %v", info, err)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), strings.TrimPrefix(privateKey, "0x")) || strings.Contains(string(data), testEthKey[2:]) {
+		t.Fatal("private keys must not be stored in plain text")
+	}
+
+	// Reopened from disk, keys load by name
+	ks, err = Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	km, err := ks.KeyManager("main/2", testPassphrase)
+	if err != nil {
+		t.Fatalf("KeyManager failed: %v", err)
+	}
+	pk := km.PubKeyBytes()
+	entry, _ := ks.Get("main/2")
+	if want := strings.TrimPrefix(publicKey, "0x"); hex.EncodeToString(pk[:]) != want || entry.PublicKey != want {
+		t.Errorf("expected public key %s, got %x and %s", publicKey, pk, entry.PublicKey)
+	}
+	if exported, _ := ks.PrivateKeyHex("main/2", testPassphrase); exported != privateKey {
+		t.Errorf("expected the imported private key back, got %s", exported)
+	}
+
+	l1, err := ks.L1Signer("owner", testPassphrase)
+	if err != nil {
+		t.Fatalf("L1Signer failed: %v", err)
+	}
+	want, _ := signer.NewL1Signer(testEthKey)
+	if entry, _ := ks.Get("owner"); l1.Address() != want.Address() || entry.Address != want.Address() {
+		t.Errorf("expected address %s, got %s", want.Address(), l1.Address())
+	}
+
+	if entry, ok := ks.FindAPIKey(7, 2); !ok || entry.Name != "main/2" {
+		t.Errorf("expected main/2 for account 7 key 2, got %+v", entry)
+	}
+	if names := ks.List(); len(names) != 2 || names[0].Name != "main/2" || names[1].Name != "owner" {
+		t.Errorf("expected both keys listed by name, got %+v", names)
+	}
+}
+
+func TestKeystore_Errors(t *testing.T) {
+	ks, _ := newTestKeystore(t)
+	privateKey, _, _ := client.GenerateAPIKey()
+	if err := ks.ImportAPIKey("main/0", 7, 0, privateKey, testPassphrase); err != nil {
+		t.Fatalf("ImportAPIKey failed: %v", err)
+	}
+
+	if _, err := ks.KeyManager("main/0", "wrong"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("expected ErrWrongPassphrase, got %v", err)
+	}
+	if _, err := ks.L1Signer("main/0", testPassphrase); !errors.Is(err, ErrWrongKind) {
+		t.Errorf("expected ErrWrongKind, got %v", err)
+	}
+	if _, err := ks.KeyManager("missing", testPassphrase); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if err := ks.ImportAPIKey("main/0", 7, 0, privateKey, testPassphrase); !errors.Is(err, ErrKeyExists) {
+		t.Errorf("expected ErrKeyExists, got %v", err)
+	}
+	if err := ks.ImportL1Key("owner", testEthKey, ""); !errors.Is(err, ErrEmptyPassphrase) {
+		t.Errorf("expected ErrEmptyPassphrase, got %v", err)
+	}
+
+	if err := ks.Delete("main/0"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, ok := ks.Get("main/0"); ok {
+		t.Error("expected the key deleted")
+	}
+}
+
+func TestKeystore_MetadataIsAuthenticated(t *testing.T) {
+	ks, path := newTestKeystore(t)
+	privateKey, _, _ := client.GenerateAPIKey()
+	if err := ks.ImportAPIKey("main/0", 7, 0, privateKey, testPassphrase); err != nil {
+		t.Fatalf("ImportAPIKey failed: %v", err)
+	}
+
+	// Relabeling the key for another account breaks its decryption
+	data, _ := os.ReadFile(path)
+	tampered := strings.Replace(string(data), `"account_index": 7`, `"account_index": 8`, 1)
+	if tampered == string(data) {
+		t.Fatal("account index not found in keystore file")
+	}
+	os.WriteFile(path, []byte(tampered), 0o600) //nolint:errcheck
+
+	ks, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if _, err := ks.KeyManager("main/0", testPassphrase); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("expected the tampered entry rejected, got %v", err)
+	}
+}
+
+func TestKeystore_BoundsScryptParams(t *testing.T) {
+	ks, path := newTestKeystore(t)
+	privateKey, _, _ := client.GenerateAPIKey()
+	if err := ks.ImportAPIKey("main/0", 7, 0, privateKey, testPassphrase); err != nil {
+		t.Fatalf("ImportAPIKey failed: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+
+	// A tampered file must not make decryption derive with an unbounded cost
+	for name, params := range map[string][3]int{
+		"n too large":   {MaxScryptN * 2, 8, 1},
+		"n not a power": {4097, 8, 1},
+		"r times p":     {4096, 1 << 16, 1 << 14},
+		"memory":        {MaxScryptN, 16, 1},
+		"negative p":    {4096, 8, -1},
+		"p too large":   {MaxScryptN, 8, 1 << 26},
+		"work":          {MaxScryptN, 8, 2},
+	} {
+		var f keystoreFile
+		if err := json.Unmarshal(data, &f); err != nil {
+			t.Fatalf("failed to parse keystore: %v", err)
+		}
+		kdf := &f.Keys["main/0"].Crypto.KDFParams
+		kdf.N, kdf.R, kdf.P = params[0], params[1], params[2]
+		tampered, _ := json.Marshal(&f)
+		os.WriteFile(path, tampered, 0o600) //nolint:errcheck
+
+		ks, err := Open(path)
+		if err != nil {
+			t.Fatalf("%s: Open failed: %v", name, err)
+		}
+		if _, err := ks.KeyManager("main/0", testPassphrase); !errors.Is(err, ErrUnsupportedCrypt) {
+			t.Errorf("%s: expected ErrUnsupportedCrypt, got %v", name, err)
+		}
+	}
+
+	ks.SetScryptParams(MaxScryptN*2, 1)
+	if err := ks.ImportAPIKey("main/1", 7, 1, privateKey, testPassphrase); !errors.Is(err, ErrUnsupportedCrypt) {
+		t.Errorf("expected imports with out of bounds params refused, got %v", err)
+	}
+}
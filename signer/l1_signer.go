@@ -2,21 +2,62 @@ package signer
 
 import (
 	"crypto/ecdsa"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+var (
+	// ErrInvalidL1Signature is returned for signatures that are not 65-byte hex strings
+	// or from which no address can be recovered
+	ErrInvalidL1Signature = errors.New("invalid L1 signature")
+	// ErrL1SignerMismatch is returned when a signature was not made by the expected address
+	ErrL1SignerMismatch = errors.New("L1 signature was made by another address")
+	// ErrTypedDataUnsupported is returned when signing typed data with an L1Signer that can't
+	ErrTypedDataUnsupported = errors.New("L1 signer does not sign typed data")
 )
 
 // L1Signer handles Ethereum L1 signatures for transactions that require them
-// (ChangePubKey and Transfer)
-type L1Signer struct {
+// (ChangePubKey and Transfer). Sign receives the message returned by the transaction's
+// GetL1SignatureBody and returns the hex-encoded EIP-191 personal sign signature.
+type L1Signer interface {
+	Sign(message string) (string, error)
+	Address() string
+}
+
+// TypedDataSigner is implemented by L1Signers that sign EIP-712 typed data, as
+// eth_signTypedData_v4 does, and return the hex-encoded signature
+type TypedDataSigner interface {
+	SignTypedData(typedData apitypes.TypedData) (string, error)
+}
+
+// SignTypedData signs typedData with l1Signer if it implements TypedDataSigner
+func SignTypedData(l1Signer L1Signer, typedData apitypes.TypedData) (string, error) {
+	s, ok := l1Signer.(TypedDataSigner)
+	if !ok {
+		return "", ErrTypedDataUnsupported
+	}
+	return s.SignTypedData(typedData)
+}
+
+// PrivateKeyL1Signer signs with an Ethereum private key held in memory
+type PrivateKeyL1Signer struct {
 	privateKey *ecdsa.PrivateKey
 }
 
-// NewL1Signer creates a new L1Signer from a hex-encoded Ethereum private key
-func NewL1Signer(privateKeyHex string) (*L1Signer, error) {
+var (
+	_ L1Signer        = (*PrivateKeyL1Signer)(nil)
+	_ TypedDataSigner = (*PrivateKeyL1Signer)(nil)
+)
+
+// NewL1Signer creates a new PrivateKeyL1Signer from a hex-encoded Ethereum private key
+func NewL1Signer(privateKeyHex string) (*PrivateKeyL1Signer, error) {
 	// Remove 0x prefix if present
 	if len(privateKeyHex) >= 2 && privateKeyHex[:2] == "0x" {
 		privateKeyHex = privateKeyHex[2:]
@@ -27,15 +68,25 @@ func NewL1Signer(privateKeyHex string) (*L1Signer, error) {
 		return nil, fmt.Errorf("invalid ethereum private key: %w", err)
 	}
 
-	return &L1Signer{privateKey: privateKey}, nil
+	return &PrivateKeyL1Signer{privateKey: privateKey}, nil
 }
 
 // Sign signs a message using EIP-191 personal sign and returns the hex-encoded signature
-func (s *L1Signer) Sign(message string) (string, error) {
+func (s *PrivateKeyL1Signer) Sign(message string) (string, error) {
 	// Hash the message using EIP-191 personal sign
-	hash := accounts.TextHash([]byte(message))
+	return s.signHash(accounts.TextHash([]byte(message)))
+}
+
+// SignTypedData signs the EIP-712 hash of typedData and returns the hex-encoded signature
+func (s *PrivateKeyL1Signer) SignTypedData(typedData apitypes.TypedData) (string, error) {
+	hash, err := TypedDataHash(typedData)
+	if err != nil {
+		return "", err
+	}
+	return s.signHash(hash)
+}
 
-	// Sign the hash
+func (s *PrivateKeyL1Signer) signHash(hash []byte) (string, error) {
 	signature, err := crypto.Sign(hash, s.privateKey)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign message: %w", err)
@@ -50,6 +101,174 @@ func (s *L1Signer) Sign(message string) (string, error) {
 }
 
 // Address returns the Ethereum address derived from the private key
-func (s *L1Signer) Address() string {
+func (s *PrivateKeyL1Signer) Address() string {
 	return crypto.PubkeyToAddress(s.privateKey.PublicKey).Hex()
 }
+
+// ExternalSignFunc signs message out of band, e.g. on a hardware wallet or in a browser
+// wallet with personal_sign, and returns the hex-encoded signature
+type ExternalSignFunc func(message string) (string, error)
+
+// ExternalTypedDataSignFunc signs typed data out of band, e.g. in a browser wallet with
+// eth_signTypedData_v4, and returns the hex-encoded signature
+type ExternalTypedDataSignFunc func(typedData apitypes.TypedData) (string, error)
+
+// ExternalL1Signer hands the message to sign to an ExternalSignFunc and checks that the
+// signature it gets back was made by the configured address
+type ExternalL1Signer struct {
+	address   common.Address
+	sign      ExternalSignFunc
+	signTyped ExternalTypedDataSignFunc // optional
+}
+
+var (
+	_ L1Signer        = (*ExternalL1Signer)(nil)
+	_ TypedDataSigner = (*ExternalL1Signer)(nil)
+)
+
+// NewExternalL1Signer creates an L1Signer for address that signs with sign
+func NewExternalL1Signer(address string, sign ExternalSignFunc) (*ExternalL1Signer, error) {
+	if !common.IsHexAddress(address) {
+		return nil, fmt.Errorf("invalid ethereum address %q", address)
+	}
+	if sign == nil {
+		return nil, errors.New("sign func is required")
+	}
+	return &ExternalL1Signer{address: common.HexToAddress(address), sign: sign}, nil
+}
+
+// Sign passes message to the sign func and returns its signature, normalized to V 27/28
+func (s *ExternalL1Signer) Sign(message string) (string, error) {
+	signature, err := s.sign(message)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign message: %w", err)
+	}
+	signature, err = NormalizeL1Signature(signature)
+	if err != nil {
+		return "", err
+	}
+	if err := VerifyL1Signature(message, signature, s.address.Hex()); err != nil {
+		return "", err
+	}
+	return signature, nil
+}
+
+// SetTypedDataSignFunc enables SignTypedData with signTyped
+func (s *ExternalL1Signer) SetTypedDataSignFunc(signTyped ExternalTypedDataSignFunc) {
+	s.signTyped = signTyped
+}
+
+// SignTypedData passes typedData to the typed data sign func and returns its signature,
+// normalized to V 27/28. It returns ErrTypedDataUnsupported if no such func was set.
+func (s *ExternalL1Signer) SignTypedData(typedData apitypes.TypedData) (string, error) {
+	if s.signTyped == nil {
+		return "", ErrTypedDataUnsupported
+	}
+	signature, err := s.signTyped(typedData)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign typed data: %w", err)
+	}
+	signature, err = NormalizeL1Signature(signature)
+	if err != nil {
+		return "", err
+	}
+	if err := VerifyL1TypedDataSignature(typedData, signature, s.address.Hex()); err != nil {
+		return "", err
+	}
+	return signature, nil
+}
+
+// Address returns the address the signatures are expected from
+func (s *ExternalL1Signer) Address() string {
+	return s.address.Hex()
+}
+
+// NormalizeL1Signature validates a hex-encoded signature and transforms V from 0/1 to 27/28,
+// as some wallets return the former
+func NormalizeL1Signature(signature string) (string, error) {
+	b, err := hexutil.Decode(addHexPrefix(signature))
+	if err != nil || len(b) != crypto.SignatureLength {
+		return "", ErrInvalidL1Signature
+	}
+	if b[64] < 27 {
+		b[64] += 27
+	}
+	if b[64] != 27 && b[64] != 28 {
+		return "", ErrInvalidL1Signature
+	}
+	return hexutil.Encode(b), nil
+}
+
+// RecoverL1Address returns the address that signed message with the EIP-191 signature
+func RecoverL1Address(message, signature string) (string, error) {
+	return recoverL1Address(accounts.TextHash([]byte(message)), signature)
+}
+
+// VerifyL1Signature checks that signature over message was made by address
+func VerifyL1Signature(message, signature, address string) error {
+	recovered, err := RecoverL1Address(message, signature)
+	if err != nil {
+		return err
+	}
+	return checkL1Address(recovered, address)
+}
+
+// TypedDataHash returns the EIP-712 hash of typedData,
+// keccak256("\x19\x01" || domainSeparator || hashStruct(message))
+func TypedDataHash(typedData apitypes.TypedData) ([]byte, error) {
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, fmt.Errorf("invalid typed data: %w", err)
+	}
+	return hash, nil
+}
+
+// RecoverL1TypedDataAddress returns the address that signed typedData with the EIP-712 signature
+func RecoverL1TypedDataAddress(typedData apitypes.TypedData, signature string) (string, error) {
+	hash, err := TypedDataHash(typedData)
+	if err != nil {
+		return "", err
+	}
+	return recoverL1Address(hash, signature)
+}
+
+// VerifyL1TypedDataSignature checks that signature over typedData was made by address
+func VerifyL1TypedDataSignature(typedData apitypes.TypedData, signature, address string) error {
+	recovered, err := RecoverL1TypedDataAddress(typedData, signature)
+	if err != nil {
+		return err
+	}
+	return checkL1Address(recovered, address)
+}
+
+// recoverL1Address returns the address that signed hash
+func recoverL1Address(hash []byte, signature string) (string, error) {
+	b, err := hexutil.Decode(addHexPrefix(signature))
+	if err != nil || len(b) != crypto.SignatureLength {
+		return "", ErrInvalidL1Signature
+	}
+	// Transform V from 27/28 to 0/1
+	if b[64] >= 27 {
+		b[64] -= 27
+	}
+	pub, err := crypto.SigToPub(hash, b)
+	if err != nil {
+		return "", ErrInvalidL1Signature
+	}
+	return crypto.PubkeyToAddress(*pub).Hex(), nil
+}
+
+func checkL1Address(recovered, address string) error {
+	if !strings.EqualFold(recovered, address) {
+		return fmt.Errorf("%w: expected %s, got %s", ErrL1SignerMismatch, address, recovered)
+	}
+	return nil
+}
+
+func addHexPrefix(s string) string {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
+		return "0x" + s
+	}
+	return s
+}
@@ -0,0 +1,175 @@
+package signer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+const (
+	testEthKey         = "0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+	testOtherKey       = "0x8f2a55949038a9610f50fb23b5883af3b4ecb3c3bb792cbcefbd1542c692be63"
+	testL1Message      = "Register Lighter Account\n\npubkey: 0x00\nnonce: 0x0000000001"
+	testL1Address      = "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23"
+	testL1AddressLower = "0x2c7536e3605d9c16a7a3d7b1898e529396a65c23"
+)
+
+func TestExternalL1Signer_VerifiesAddress(t *testing.T) {
+	key, _ := NewL1Signer(testEthKey)
+	if key.Address() != testL1Address {
+		t.Fatalf("expected address %s, got %s", testL1Address, key.Address())
+	}
+
+	// A wallet returning V as 0/1 is normalized to 27/28
+	var asked string
+	external, err := NewExternalL1Signer(testL1AddressLower, func(message string) (string, error) {
+		asked = message
+		sig, err := key.Sign(message)
+		if err != nil {
+			return "", err
+		}
+		b := hexutil.MustDecode(sig)
+		b[64] -= 27
+		return hexutil.Encode(b)[2:], nil
+	})
+	if err != nil {
+		t.Fatalf("NewExternalL1Signer failed: %v", err)
+	}
+
+	sig, err := external.Sign(testL1Message)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if asked != testL1Message {
+		t.Errorf("expected the message passed to the wallet, got %q", asked)
+	}
+	want, _ := key.Sign(testL1Message)
+	if sig != want {
+		t.Errorf("expected signature %s, got %s", want, sig)
+	}
+	if addr, _ := RecoverL1Address(testL1Message, sig); addr != testL1Address {
+		t.Errorf("expected %s recovered, got %s", testL1Address, addr)
+	}
+}
+
+func TestExternalL1Signer_RejectsOtherSigner(t *testing.T) {
+	other, _ := NewL1Signer(testOtherKey)
+	external, _ := NewExternalL1Signer(testL1Address, other.Sign)
+	if _, err := external.Sign(testL1Message); !errors.Is(err, ErrL1SignerMismatch) {
+		t.Errorf("expected ErrL1SignerMismatch, got %v", err)
+	}
+
+	garbage, _ := NewExternalL1Signer(testL1Address, func(string) (string, error) { return "0x1234", nil })
+	if _, err := garbage.Sign(testL1Message); !errors.Is(err, ErrInvalidL1Signature) {
+		t.Errorf("expected ErrInvalidL1Signature, got %v", err)
+	}
+
+	walletErr := errors.New("user rejected the request")
+	rejected, _ := NewExternalL1Signer(testL1Address, func(string) (string, error) { return "", walletErr })
+	if _, err := rejected.Sign(testL1Message); !errors.Is(err, walletErr) {
+		t.Errorf("expected the wallet error, got %v", err)
+	}
+
+	if _, err := NewExternalL1Signer("not an address", other.Sign); err == nil {
+		t.Error("expected an invalid address rejected")
+	}
+}
+
+// mailTypedData is the example of EIP-712, signed with keccak256("cow") in the spec
+func mailTypedData() apitypes.TypedData {
+	return apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"Person": {
+				{Name: "name", Type: "string"},
+				{Name: "wallet", Type: "address"},
+			},
+			"Mail": {
+				{Name: "from", Type: "Person"},
+				{Name: "to", Type: "Person"},
+				{Name: "contents", Type: "string"},
+			},
+		},
+		PrimaryType: "Mail",
+		Domain: apitypes.TypedDataDomain{
+			Name:              "Ether Mail",
+			Version:           "1",
+			ChainId:           math.NewHexOrDecimal256(1),
+			VerifyingContract: "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC",
+		},
+		Message: apitypes.TypedDataMessage{
+			"from":     map[string]interface{}{"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+			"to":       map[string]interface{}{"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+			"contents": "Hello, Bob!",
+		},
+	}
+}
+
+func TestPrivateKeyL1Signer_SignTypedData(t *testing.T) {
+	typedData := mailTypedData()
+	hash, err := TypedDataHash(typedData)
+	if err != nil {
+		t.Fatalf("TypedDataHash failed: %v", err)
+	}
+	if got := hexutil.Encode(hash); got != "0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2" {
+		t.Errorf("unexpected typed data hash %s", got)
+	}
+
+	cow, _ := NewL1Signer(hexutil.Encode(crypto.Keccak256([]byte("cow"))))
+	sig, err := SignTypedData(cow, typedData)
+	if err != nil {
+		t.Fatalf("SignTypedData failed: %v", err)
+	}
+	want := "0x4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d" +
+		"07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b915621c"
+	if sig != want {
+		t.Errorf("expected the signature of the spec, got %s", sig)
+	}
+	if err := VerifyL1TypedDataSignature(typedData, sig, "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"); err != nil {
+		t.Errorf("VerifyL1TypedDataSignature failed: %v", err)
+	}
+
+	// The signature covers the message and the domain
+	typedData.Message["contents"] = "Hello, Eve!"
+	if err := VerifyL1TypedDataSignature(typedData, sig, cow.Address()); !errors.Is(err, ErrL1SignerMismatch) {
+		t.Errorf("expected a changed message rejected, got %v", err)
+	}
+	typedData = mailTypedData()
+	typedData.Domain.ChainId = math.NewHexOrDecimal256(304)
+	if err := VerifyL1TypedDataSignature(typedData, sig, cow.Address()); !errors.Is(err, ErrL1SignerMismatch) {
+		t.Errorf("expected another chain rejected, got %v", err)
+	}
+}
+
+func TestExternalL1Signer_SignTypedData(t *testing.T) {
+	key, _ := NewL1Signer(testEthKey)
+	other, _ := NewL1Signer(testOtherKey)
+
+	external, _ := NewExternalL1Signer(testL1Address, key.Sign)
+	if _, err := SignTypedData(external, mailTypedData()); !errors.Is(err, ErrTypedDataUnsupported) {
+		t.Errorf("expected ErrTypedDataUnsupported without a typed data sign func, got %v", err)
+	}
+
+	external.SetTypedDataSignFunc(key.SignTypedData)
+	sig, err := SignTypedData(external, mailTypedData())
+	if err != nil {
+		t.Fatalf("SignTypedData failed: %v", err)
+	}
+	if addr, _ := RecoverL1TypedDataAddress(mailTypedData(), sig); addr != testL1Address {
+		t.Errorf("expected %s recovered, got %s", testL1Address, addr)
+	}
+
+	external.SetTypedDataSignFunc(other.SignTypedData)
+	if _, err := external.SignTypedData(mailTypedData()); !errors.Is(err, ErrL1SignerMismatch) {
+		t.Errorf("expected ErrL1SignerMismatch, got %v", err)
+	}
+}
@@ -0,0 +1,196 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	gFp5 "github.com/elliottech/poseidon_crypto/field/goldilocks_quintic_extension"
+	schnorr "github.com/elliottech/poseidon_crypto/signature/schnorr"
+
+	"github.com/0xJord4n/lighter-go/signer"
+	"github.com/0xJord4n/lighter-go/types/txtypes"
+)
+
+// KeyManager signs through a lighter-signer daemon. It implements signer.KeyManager and
+// signer.TxSigner, so transactions built with it are sent to the daemon to be checked and
+// signed; the private key never enters this process.
+type KeyManager struct {
+	baseURL    string
+	secret     []byte
+	httpClient *http.Client
+	timeout    time.Duration
+
+	pubKey      gFp5.Element
+	pubKeyBytes [40]byte
+}
+
+// Option configures a KeyManager
+type Option func(*KeyManager)
+
+// WithHTTPClient sets the HTTP client used to reach a TCP endpoint
+func WithHTTPClient(c *http.Client) Option {
+	return func(km *KeyManager) {
+		km.httpClient = c
+	}
+}
+
+// WithTimeout bounds each request to the daemon (default: 5s)
+func WithTimeout(d time.Duration) Option {
+	return func(km *KeyManager) {
+		km.timeout = d
+	}
+}
+
+// NewKeyManager connects to the daemon at endpoint, "unix:///path/to/socket" or
+// "http(s)://host:port", and fetches the public key of the API key it holds
+func NewKeyManager(endpoint string, secret []byte, opts ...Option) (*KeyManager, error) {
+	if len(secret) < MinSecretLength {
+		return nil, ErrInvalidSecret
+	}
+	km := &KeyManager{secret: secret, timeout: 5 * time.Second}
+
+	if socket, ok := strings.CutPrefix(endpoint, "unix://"); ok {
+		km.baseURL = "http://lighter-signer"
+		km.httpClient = &http.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socket)
+			},
+		}}
+	} else {
+		km.baseURL = strings.TrimSuffix(endpoint, "/")
+		km.httpClient = http.DefaultClient
+	}
+	for _, opt := range opts {
+		opt(km)
+	}
+
+	var resp PublicKeyResponse
+	if err := km.call(http.MethodGet, PathPublicKey, nil, &resp); err != nil {
+		return nil, err
+	}
+	b, err := hex.DecodeString(strings.TrimPrefix(resp.PublicKey, "0x"))
+	if err != nil || len(b) != len(km.pubKeyBytes) {
+		return nil, fmt.Errorf("remote signer returned an invalid public key: %q", resp.PublicKey)
+	}
+	km.pubKey, err = gFp5.FromCanonicalLittleEndianBytes(b)
+	if err != nil {
+		return nil, fmt.Errorf("remote signer returned an invalid public key: %w", err)
+	}
+	copy(km.pubKeyBytes[:], b)
+	return km, nil
+}
+
+// Sign refuses bare hashes, which the daemon could not check against its policy
+func (km *KeyManager) Sign(hashedMessage []byte, hFunc hash.Hash) ([]byte, error) {
+	return nil, ErrTxRequired
+}
+
+// SignTx has the daemon check and sign a transaction
+func (km *KeyManager) SignTx(tx txtypes.TxInfo, hashedMessage []byte) ([]byte, error) {
+	txInfo, err := tx.GetTxInfo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode transaction: %w", err)
+	}
+	return km.sign(PathSign, &SignRequest{
+		TxType: tx.GetTxType(),
+		TxInfo: json.RawMessage(txInfo),
+		Hash:   hex.EncodeToString(hashedMessage),
+	}, hashedMessage)
+}
+
+// SignAuthToken has the daemon check and sign an auth token message
+func (km *KeyManager) SignAuthToken(message string, hashedMessage []byte) ([]byte, error) {
+	return km.sign(PathAuthToken, &AuthTokenRequest{
+		Message: message,
+		Hash:    hex.EncodeToString(hashedMessage),
+	}, hashedMessage)
+}
+
+// sign sends a signing request and checks the signature against the daemon's public key
+func (km *KeyManager) sign(path string, req interface{}, hashedMessage []byte) ([]byte, error) {
+	var resp SignResponse
+	if err := km.call(http.MethodPost, path, req, &resp); err != nil {
+		return nil, err
+	}
+	sig, err := hex.DecodeString(resp.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("remote signer returned an invalid signature: %w", err)
+	}
+	if err := schnorr.Validate(km.pubKeyBytes[:], hashedMessage, sig); err != nil {
+		return nil, fmt.Errorf("remote signer returned an invalid signature: %w", err)
+	}
+	return sig, nil
+}
+
+func (km *KeyManager) PubKey() gFp5.Element {
+	return km.pubKey
+}
+
+func (km *KeyManager) PubKeyBytes() [40]byte {
+	return km.pubKeyBytes
+}
+
+// PrvKeyBytes returns nil: the private key stays in the daemon
+func (km *KeyManager) PrvKeyBytes() []byte {
+	return nil
+}
+
+// call sends an authenticated request and decodes the response into out
+func (km *KeyManager) call(method, path string, in, out interface{}) error {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), km.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, km.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, requestMAC(km.secret, timestamp, method, path, body))
+
+	resp, err := km.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("remote signer unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read remote signer response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var e ErrorResponse
+		if err := json.Unmarshal(data, &e); err != nil || e.Code == "" {
+			return &RemoteError{Status: resp.StatusCode, Code: CodeInternal, Message: strings.TrimSpace(string(data))}
+		}
+		return &RemoteError{Status: resp.StatusCode, Code: e.Code, Message: e.Error}
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode remote signer response: %w", err)
+	}
+	return nil
+}
+
+var (
+	_ signer.KeyManager = (*KeyManager)(nil)
+	_ signer.TxSigner   = (*KeyManager)(nil)
+)
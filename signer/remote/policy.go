@@ -0,0 +1,122 @@
+package remote
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/bits"
+	"os"
+	"time"
+
+	"github.com/0xJord4n/lighter-go/types/txtypes"
+)
+
+// Policy is the allow-list the daemon checks every request against.
+// It is loaded from JSON, e.g.:
+//
+//	{"tx_types": [14, 15, 16, 17], "markets": [0, 1], "max_notional": {"0": 5000000000}, "allow_auth_tokens": true, "max_auth_token_lifetime": 3600}
+type Policy struct {
+	// Transaction types that may be signed (txtypes.TxTypeL2*); none if empty
+	TxTypes []uint8 `json:"tx_types"`
+
+	// Markets that orders, cancels, leverage and margin updates may touch; all if empty
+	Markets []int16 `json:"markets"`
+
+	// Largest BaseAmount × Price of a single order per market, in the integer units of
+	// the transaction; markets without an entry are not limited
+	MaxNotional map[int16]uint64 `json:"max_notional"`
+
+	// Whether auth tokens for private endpoints may be signed
+	AllowAuthTokens bool `json:"allow_auth_tokens"`
+
+	// Longest auth token lifetime, from signing to deadline, in seconds; up to
+	// MaxAuthTokenLifetime if zero
+	MaxAuthTokenLifetime int64 `json:"max_auth_token_lifetime"`
+}
+
+// LoadPolicy reads a JSON policy file
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy: %w", err)
+	}
+	var p Policy
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse policy: %w", err)
+	}
+	return &p, nil
+}
+
+// CheckTx returns an error wrapping ErrPolicyDenied if the policy does not allow tx
+func (p *Policy) CheckTx(tx txtypes.TxInfo) error {
+	if !p.allowsTxType(tx.GetTxType()) {
+		return fmt.Errorf("%w: tx type %d is not allowed", ErrPolicyDenied, tx.GetTxType())
+	}
+
+	switch tx := tx.(type) {
+	case *txtypes.L2CreateOrderTxInfo:
+		return p.checkOrder(tx.MarketIndex, tx.BaseAmount, tx.Price)
+	case *txtypes.L2CreateGroupedOrdersTxInfo:
+		for _, order := range tx.Orders {
+			if err := p.checkOrder(order.MarketIndex, order.BaseAmount, order.Price); err != nil {
+				return err
+			}
+		}
+	case *txtypes.L2ModifyOrderTxInfo:
+		return p.checkOrder(tx.MarketIndex, tx.BaseAmount, tx.Price)
+	case *txtypes.L2CancelOrderTxInfo:
+		return p.checkMarket(tx.MarketIndex)
+	case *txtypes.L2UpdateLeverageTxInfo:
+		return p.checkMarket(tx.MarketIndex)
+	case *txtypes.L2UpdateMarginTxInfo:
+		return p.checkMarket(tx.MarketIndex)
+	}
+	return nil
+}
+
+// CheckAuthToken returns an error wrapping ErrPolicyDenied if auth tokens may not be signed,
+// or not for lifetime
+func (p *Policy) CheckAuthToken(lifetime time.Duration) error {
+	if !p.AllowAuthTokens {
+		return fmt.Errorf("%w: auth tokens are not allowed", ErrPolicyDenied)
+	}
+	if limit := time.Duration(p.MaxAuthTokenLifetime) * time.Second; limit > 0 && lifetime > limit {
+		return fmt.Errorf("%w: auth token lifetime %v exceeds %v", ErrPolicyDenied, lifetime, limit)
+	}
+	return nil
+}
+
+func (p *Policy) allowsTxType(txType uint8) bool {
+	for _, t := range p.TxTypes {
+		if t == txType {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Policy) checkMarket(marketIndex int16) error {
+	if len(p.Markets) == 0 {
+		return nil
+	}
+	for _, m := range p.Markets {
+		if m == marketIndex {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: market %d is not allowed", ErrPolicyDenied, marketIndex)
+}
+
+func (p *Policy) checkOrder(marketIndex int16, baseAmount int64, price uint32) error {
+	if err := p.checkMarket(marketIndex); err != nil {
+		return err
+	}
+	limit, ok := p.MaxNotional[marketIndex]
+	if !ok || baseAmount <= 0 {
+		return nil
+	}
+	hi, notional := bits.Mul64(uint64(baseAmount), uint64(price))
+	if hi != 0 || notional > limit {
+		return fmt.Errorf("%w: order notional %d×%d on market %d exceeds %d", ErrPolicyDenied, baseAmount, price, marketIndex, limit)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+// Package remote keeps API private keys out of the trading process. A lighter-signer
+// daemon (cmd/lighter-signer) holds the key and serves Server; the trading process signs
+// through KeyManager, which implements signer.KeyManager over a small RPC protocol.
+//
+// The protocol is JSON over HTTP, on a Unix socket or TCP:
+//
+//	GET  /v1/public_key                                       -> {"public_key": hex}
+//	POST /v1/sign        {"tx_type", "tx_info", "hash"}       -> {"signature": hex}
+//	POST /v1/auth_token  {"message", "hash"}                  -> {"signature": hex}
+//
+// Every request is authenticated with an HMAC-SHA256 of its timestamp, method, path and
+// body under a secret shared by both sides. The daemon re-hashes each transaction, so it
+// only signs what it was shown, and checks it against its Policy before signing.
+//
+// Example:
+//
+//	km, err := remote.NewKeyManager("unix:///run/lighter-signer.sock", secret)
+//	txClient := client.NewTxClientWithKeyManager(httpClient, km, accountIndex, apiKeyIndex, chainId)
+package remote
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	PathPublicKey = "/v1/public_key"
+	PathSign      = "/v1/sign"
+	PathAuthToken = "/v1/auth_token"
+
+	HeaderTimestamp = "X-Signer-Timestamp"
+	HeaderSignature = "X-Signer-Signature"
+
+	// MaxClockSkew is how far a request timestamp may be from the daemon's clock
+	MaxClockSkew = 30 * time.Second
+
+	// MaxAuthTokenLifetime is how far in the future an auth token deadline may be, as
+	// accepted by Lighter; Policy.MaxAuthTokenLifetime may shorten it
+	MaxAuthTokenLifetime = 8 * time.Hour
+)
+
+// Error codes returned by the daemon
+const (
+	CodeUnauthorized = "unauthorized"
+	CodeBadRequest   = "bad_request"
+	CodePolicyDenied = "policy_denied"
+	CodeInternal     = "internal"
+)
+
+var (
+	ErrUnauthorized  = errors.New("remote signer rejected the request authentication")
+	ErrPolicyDenied  = errors.New("remote signer policy denied the request")
+	ErrBadRequest    = errors.New("remote signer rejected the request")
+	ErrHashMismatch  = errors.New("hash does not match the transaction")
+	ErrTxRequired    = errors.New("remote signer only signs transactions and auth tokens it can check")
+	ErrInvalidSecret = errors.New("shared secret must be at least 32 bytes")
+)
+
+// MinSecretLength is the minimum length of the shared secret
+const MinSecretLength = 32
+
+// SignRequest asks the daemon to sign a transaction
+type SignRequest struct {
+	TxType uint8           `json:"tx_type"`
+	TxInfo json.RawMessage `json:"tx_info"`
+	Hash   string          `json:"hash"`
+}
+
+// AuthTokenRequest asks the daemon to sign an auth token message ("deadline:account:apiKey")
+type AuthTokenRequest struct {
+	Message string `json:"message"`
+	Hash    string `json:"hash"`
+}
+
+// SignResponse carries a signature
+type SignResponse struct {
+	Signature string `json:"signature"`
+}
+
+// PublicKeyResponse carries the public key of the daemon's API key
+type PublicKeyResponse struct {
+	PublicKey string `json:"public_key"`
+}
+
+// ErrorResponse is returned with a non-2xx status
+type ErrorResponse struct {
+	Code  string `json:"code"`
+	Error string `json:"error"`
+}
+
+// RemoteError is an error reported by the daemon
+type RemoteError struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+func (e *RemoteError) Error() string {
+	return fmt.Sprintf("remote signer: %s (%s, status %d)", e.Message, e.Code, e.Status)
+}
+
+// Unwrap maps the error code to ErrUnauthorized, ErrPolicyDenied or ErrBadRequest
+func (e *RemoteError) Unwrap() error {
+	switch e.Code {
+	case CodeUnauthorized:
+		return ErrUnauthorized
+	case CodePolicyDenied:
+		return ErrPolicyDenied
+	case CodeBadRequest:
+		return ErrBadRequest
+	}
+	return nil
+}
+
+// requestMAC authenticates a request: HMAC-SHA256 over "timestamp\nmethod\npath\nbody"
+func requestMAC(secret []byte, timestamp, method, path string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp + "\n" + method + "\n" + path + "\n"))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verifyRequest checks the timestamp and MAC headers of a request
+func verifyRequest(secret []byte, timestamp, signature, method, path string, body []byte, now time.Time) error {
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: invalid timestamp", ErrUnauthorized)
+	}
+	if skew := now.Sub(time.Unix(unix, 0)); skew > MaxClockSkew || skew < -MaxClockSkew {
+		return fmt.Errorf("%w: timestamp outside the allowed clock skew", ErrUnauthorized)
+	}
+	want := requestMAC(secret, timestamp, method, path, body)
+	if !hmac.Equal([]byte(want), []byte(signature)) {
+		return fmt.Errorf("%w: invalid signature", ErrUnauthorized)
+	}
+	return nil
+}
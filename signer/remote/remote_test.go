@@ -0,0 +1,223 @@
+package remote
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	curve "github.com/elliottech/poseidon_crypto/curve/ecgfp5"
+	p2 "github.com/elliottech/poseidon_crypto/hash/poseidon2_goldilocks"
+	schnorr "github.com/elliottech/poseidon_crypto/signature/schnorr"
+
+	"github.com/0xJord4n/lighter-go/client"
+	"github.com/0xJord4n/lighter-go/signer"
+	"github.com/0xJord4n/lighter-go/types"
+	"github.com/0xJord4n/lighter-go/types/txtypes"
+)
+
+const testChainId = 304
+
+var testSecret = bytes.Repeat([]byte("s"), MinSecretLength)
+
+func testPolicy() *Policy {
+	return &Policy{
+		TxTypes:         []uint8{txtypes.TxTypeL2CreateOrder, txtypes.TxTypeL2Transfer, txtypes.TxTypeL2ChangePubKey},
+		Markets:         []int16{0},
+		MaxNotional:     map[int16]uint64{0: 1000 * 3000},
+		AllowAuthTokens: true,
+	}
+}
+
+// newTestDaemon serves a fresh key on a Unix socket, as the lighter-signer daemon does
+func newTestDaemon(t *testing.T, policy *Policy) (signer.KeyManager, string) {
+	t.Helper()
+	key, err := signer.NewKeyManager(curve.SampleScalar(nil).ToLittleEndianBytes())
+	if err != nil {
+		t.Fatalf("NewKeyManager failed: %v", err)
+	}
+	server, err := NewServer(key, testChainId, 7, 2, testSecret, policy)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+
+	// Socket paths are limited to ~100 bytes, too short for t.TempDir on some systems
+	dir, err := os.MkdirTemp("", "signer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	endpoint := "unix://" + filepath.Join(dir, "s.sock")
+
+	listener, err := Listen(endpoint)
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	srv := &http.Server{Handler: server}
+	go srv.Serve(listener) //nolint:errcheck
+	t.Cleanup(func() { srv.Close() })
+	return key, endpoint
+}
+
+func newTestTxClient(t *testing.T, endpoint string) (*client.TxClient, *KeyManager) {
+	t.Helper()
+	km, err := NewKeyManager(endpoint, testSecret)
+	if err != nil {
+		t.Fatalf("NewKeyManager failed: %v", err)
+	}
+	return client.NewTxClientWithKeyManager(nil, km, 7, 2, testChainId), km
+}
+
+func order(market int16, baseAmount int64, price uint32) *types.CreateOrderTxReq {
+	return &types.CreateOrderTxReq{
+		MarketIndex: market,
+		BaseAmount:  baseAmount,
+		Price:       price,
+		Type:        txtypes.LimitOrder,
+		TimeInForce: txtypes.GoodTillTime,
+		OrderExpiry: time.Now().Add(time.Hour).UnixMilli(),
+	}
+}
+
+func opts(nonce int64) *types.TransactOpts {
+	return &types.TransactOpts{Nonce: types.NewInt64(nonce)}
+}
+
+func TestRemote_SignsAllowedTransactions(t *testing.T) {
+	key, endpoint := newTestDaemon(t, testPolicy())
+	txClient, km := newTestTxClient(t, endpoint)
+
+	if km.PubKeyBytes() != key.PubKeyBytes() {
+		t.Fatal("expected the daemon's public key")
+	}
+	if km.PrvKeyBytes() != nil {
+		t.Error("the private key must not leave the daemon")
+	}
+
+	tx, err := txClient.GetCreateOrderTransaction(order(0, 1000, 3000), opts(5))
+	if err != nil {
+		t.Fatalf("GetCreateOrderTransaction failed: %v", err)
+	}
+	pk := key.PubKeyBytes()
+	msgHash, _ := tx.Hash(testChainId)
+	if err := schnorr.Validate(pk[:], msgHash, tx.Sig); err != nil {
+		t.Errorf("invalid signature: %v", err)
+	}
+
+	transfer := &types.TransferTxReq{ToAccountIndex: 8, AssetIndex: 1, Amount: 100, Memo: [32]byte{1, 2, 3}}
+	if _, err := txClient.GetTransferTransaction(transfer, opts(6)); err != nil {
+		t.Errorf("GetTransferTransaction failed: %v", err)
+	}
+	if _, err := txClient.GetChangePubKeyTransaction(&types.ChangePubKeyReq{PubKey: pk}, opts(7)); err != nil {
+		t.Errorf("GetChangePubKeyTransaction failed: %v", err)
+	}
+	if _, err := txClient.GetAuthToken(time.Now().Add(time.Hour)); err != nil {
+		t.Errorf("GetAuthToken failed: %v", err)
+	}
+}
+
+func TestRemote_PolicyDenied(t *testing.T) {
+	policy := testPolicy()
+	policy.AllowAuthTokens = false
+	_, endpoint := newTestDaemon(t, policy)
+	txClient, _ := newTestTxClient(t, endpoint)
+
+	if _, err := txClient.GetCreateOrderTransaction(order(1, 1000, 3000), opts(5)); !errors.Is(err, ErrPolicyDenied) {
+		t.Errorf("expected market 1 denied, got %v", err)
+	}
+	if _, err := txClient.GetCreateOrderTransaction(order(0, 1001, 3000), opts(5)); !errors.Is(err, ErrPolicyDenied) {
+		t.Errorf("expected the notional limit denied, got %v", err)
+	}
+	cancel := &types.CancelOrderTxReq{MarketIndex: 0, Index: 1}
+	if _, err := txClient.GetCancelOrderTransaction(cancel, opts(5)); !errors.Is(err, ErrPolicyDenied) {
+		t.Errorf("expected the tx type denied, got %v", err)
+	}
+	if _, err := txClient.GetAuthToken(time.Now().Add(time.Hour)); !errors.Is(err, ErrPolicyDenied) {
+		t.Errorf("expected auth tokens denied, got %v", err)
+	}
+}
+
+func TestRemote_OnlySignsWhatItIsShown(t *testing.T) {
+	_, endpoint := newTestDaemon(t, testPolicy())
+	txClient, km := newTestTxClient(t, endpoint)
+
+	tx, err := txClient.GetCreateOrderTransaction(order(0, 1000, 3000), opts(5))
+	if err != nil {
+		t.Fatalf("GetCreateOrderTransaction failed: %v", err)
+	}
+
+	// The hash of a larger order, passed off with the allowed one
+	large := *tx
+	large.OrderInfo = &txtypes.OrderInfo{}
+	*large.OrderInfo = *tx.OrderInfo
+	large.BaseAmount = 1_000_000
+	largeHash, _ := large.Hash(testChainId)
+	if _, err := km.SignTx(tx, largeHash); !errors.Is(err, ErrBadRequest) {
+		t.Errorf("expected the hash mismatch rejected, got %v", err)
+	}
+
+	if _, err := km.Sign(largeHash, p2.NewPoseidon2()); !errors.Is(err, ErrTxRequired) {
+		t.Errorf("expected bare hashes refused, got %v", err)
+	}
+}
+
+func TestRemote_Authentication(t *testing.T) {
+	_, endpoint := newTestDaemon(t, testPolicy())
+
+	if _, err := NewKeyManager(endpoint, bytes.Repeat([]byte("x"), MinSecretLength)); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("expected a wrong secret rejected, got %v", err)
+	}
+	if _, err := NewKeyManager(endpoint, []byte("short")); !errors.Is(err, ErrInvalidSecret) {
+		t.Errorf("expected ErrInvalidSecret, got %v", err)
+	}
+
+	// Requests outside the clock skew are rejected, e.g. a replayed capture
+	key, _ := signer.NewKeyManager(curve.SampleScalar(nil).ToLittleEndianBytes())
+	server, _ := NewServer(key, testChainId, 7, 2, testSecret, testPolicy())
+	server.now = func() time.Time { return time.Now().Add(time.Minute) }
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+	if _, err := NewKeyManager(srv.URL, testSecret); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("expected a stale request rejected, got %v", err)
+	}
+}
+
+func TestRemote_AuthTokenChecks(t *testing.T) {
+	policy := testPolicy()
+	policy.MaxAuthTokenLifetime = 3600
+	_, endpoint := newTestDaemon(t, policy)
+	_, km := newTestTxClient(t, endpoint)
+
+	signAuthToken := func(deadline time.Time, accountIndex int64, apiKeyIndex uint8) error {
+		message := fmt.Sprintf("%d:%d:%d", deadline.Unix(), accountIndex, apiKeyIndex)
+		msgHash, err := types.AuthTokenHash(message)
+		if err != nil {
+			t.Fatalf("AuthTokenHash failed: %v", err)
+		}
+		_, err = km.SignAuthToken(message, msgHash)
+		return err
+	}
+
+	now := time.Now()
+	if err := signAuthToken(now.Add(30*time.Minute), 7, 2); err != nil {
+		t.Errorf("expected a token for the daemon's key signed, got %v", err)
+	}
+	for name, err := range map[string]error{
+		"other account": signAuthToken(now.Add(30*time.Minute), 8, 2),
+		"other api key": signAuthToken(now.Add(30*time.Minute), 7, 3),
+		"past deadline": signAuthToken(now.Add(-time.Minute), 7, 2),
+		"beyond 8h":     signAuthToken(now.Add(9*time.Hour), 7, 2),
+	} {
+		if !errors.Is(err, ErrBadRequest) {
+			t.Errorf("%s: expected ErrBadRequest, got %v", name, err)
+		}
+	}
+	if err := signAuthToken(now.Add(2*time.Hour), 7, 2); !errors.Is(err, ErrPolicyDenied) {
+		t.Errorf("expected the policy lifetime enforced, got %v", err)
+	}
+}
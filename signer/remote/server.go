@@ -0,0 +1,281 @@
+package remote
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	p2 "github.com/elliottech/poseidon_crypto/hash/poseidon2_goldilocks"
+
+	"github.com/0xJord4n/lighter-go/signer"
+	"github.com/0xJord4n/lighter-go/types"
+	"github.com/0xJord4n/lighter-go/types/txtypes"
+)
+
+// maxRequestSize bounds request bodies
+const maxRequestSize = 1 << 20
+
+// Server is the daemon side of the protocol: it authenticates requests, re-hashes each
+// transaction, checks it against the policy and signs it with the key it holds
+type Server struct {
+	key          signer.KeyManager
+	chainId      uint32
+	accountIndex int64
+	apiKeyIndex  uint8
+	secret       []byte
+	policy       *Policy
+	logger       *log.Logger
+	now          func() time.Time
+}
+
+// NewServer creates a server signing for chainId with key, the api key apiKeyIndex of
+// accountIndex. Auth tokens are only signed for that account and api key.
+func NewServer(key signer.KeyManager, chainId uint32, accountIndex int64, apiKeyIndex uint8, secret []byte, policy *Policy) (*Server, error) {
+	if len(secret) < MinSecretLength {
+		return nil, ErrInvalidSecret
+	}
+	if policy == nil {
+		policy = &Policy{}
+	}
+	return &Server{
+		key:          key,
+		chainId:      chainId,
+		accountIndex: accountIndex,
+		apiKeyIndex:  apiKeyIndex,
+		secret:       secret,
+		policy:       policy,
+		now:          time.Now,
+	}, nil
+}
+
+// SetLogger logs every signing decision to l
+func (s *Server) SetLogger(l *log.Logger) {
+	s.logger = l
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestSize))
+	if err != nil {
+		s.fail(w, r, http.StatusBadRequest, CodeBadRequest, err)
+		return
+	}
+	err = verifyRequest(s.secret, r.Header.Get(HeaderTimestamp), r.Header.Get(HeaderSignature), r.Method, r.URL.Path, body, s.now())
+	if err != nil {
+		s.fail(w, r, http.StatusUnauthorized, CodeUnauthorized, err)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == PathPublicKey:
+		pk := s.key.PubKeyBytes()
+		writeJSON(w, http.StatusOK, &PublicKeyResponse{PublicKey: hex.EncodeToString(pk[:])})
+	case r.Method == http.MethodPost && r.URL.Path == PathSign:
+		s.handleSign(w, r, body)
+	case r.Method == http.MethodPost && r.URL.Path == PathAuthToken:
+		s.handleAuthToken(w, r, body)
+	default:
+		s.fail(w, r, http.StatusNotFound, CodeBadRequest, errors.New("unknown endpoint"))
+	}
+}
+
+func (s *Server) handleSign(w http.ResponseWriter, r *http.Request, body []byte) {
+	var req SignRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		s.fail(w, r, http.StatusBadRequest, CodeBadRequest, err)
+		return
+	}
+	tx, err := decodeTx(req.TxType, req.TxInfo)
+	if err != nil {
+		s.fail(w, r, http.StatusBadRequest, CodeBadRequest, err)
+		return
+	}
+	if err := tx.Validate(); err != nil {
+		s.fail(w, r, http.StatusBadRequest, CodeBadRequest, err)
+		return
+	}
+
+	// Sign the hash of the transaction as shown, never the hash as claimed
+	msgHash, err := tx.Hash(s.chainId)
+	if err != nil {
+		s.fail(w, r, http.StatusBadRequest, CodeBadRequest, err)
+		return
+	}
+	if !strings.EqualFold(hex.EncodeToString(msgHash), strings.TrimPrefix(req.Hash, "0x")) {
+		s.fail(w, r, http.StatusBadRequest, CodeBadRequest, ErrHashMismatch)
+		return
+	}
+	if err := s.policy.CheckTx(tx); err != nil {
+		s.fail(w, r, http.StatusForbidden, CodePolicyDenied, err)
+		return
+	}
+
+	s.signHash(w, r, msgHash, fmt.Sprintf("tx type %d nonce %d", tx.GetTxType(), tx.GetNonce()))
+}
+
+func (s *Server) handleAuthToken(w http.ResponseWriter, r *http.Request, body []byte) {
+	var req AuthTokenRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		s.fail(w, r, http.StatusBadRequest, CodeBadRequest, err)
+		return
+	}
+	deadline, err := s.checkAuthTokenMessage(req.Message)
+	if err != nil {
+		s.fail(w, r, http.StatusBadRequest, CodeBadRequest, err)
+		return
+	}
+	msgHash, err := types.AuthTokenHash(req.Message)
+	if err != nil {
+		s.fail(w, r, http.StatusBadRequest, CodeBadRequest, err)
+		return
+	}
+	if !strings.EqualFold(hex.EncodeToString(msgHash), strings.TrimPrefix(req.Hash, "0x")) {
+		s.fail(w, r, http.StatusBadRequest, CodeBadRequest, ErrHashMismatch)
+		return
+	}
+	if err := s.policy.CheckAuthToken(deadline.Sub(s.now())); err != nil {
+		s.fail(w, r, http.StatusForbidden, CodePolicyDenied, err)
+		return
+	}
+
+	s.signHash(w, r, msgHash, "auth token "+req.Message)
+}
+
+// checkAuthTokenMessage parses a "deadline:account:apiKey" message and returns its deadline
+// if it is for the server's api key and no more than MaxAuthTokenLifetime away
+func (s *Server) checkAuthTokenMessage(message string) (time.Time, error) {
+	parts := strings.Split(message, ":")
+	if len(parts) != 3 {
+		return time.Time{}, errors.New("auth token message must be deadline:account:apiKey")
+	}
+	unix, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid auth token deadline: %w", err)
+	}
+	accountIndex, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid auth token account: %w", err)
+	}
+	apiKeyIndex, err := strconv.ParseUint(parts[2], 10, 8)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid auth token api key: %w", err)
+	}
+	if accountIndex != s.accountIndex || uint8(apiKeyIndex) != s.apiKeyIndex {
+		return time.Time{}, fmt.Errorf("auth token for account %d api key %d, the key is account %d api key %d", accountIndex, apiKeyIndex, s.accountIndex, s.apiKeyIndex)
+	}
+
+	deadline := time.Unix(unix, 0)
+	now := s.now()
+	if !deadline.After(now) {
+		return time.Time{}, fmt.Errorf("auth token deadline %v has passed", deadline)
+	}
+	if deadline.Sub(now) > MaxAuthTokenLifetime {
+		return time.Time{}, fmt.Errorf("auth token deadline %v is more than %v away", deadline, MaxAuthTokenLifetime)
+	}
+	return deadline, nil
+}
+
+func (s *Server) signHash(w http.ResponseWriter, r *http.Request, msgHash []byte, what string) {
+	sig, err := s.key.Sign(msgHash, p2.NewPoseidon2())
+	if err != nil {
+		s.fail(w, r, http.StatusInternalServerError, CodeInternal, err)
+		return
+	}
+	s.logf("signed %s", what)
+	writeJSON(w, http.StatusOK, &SignResponse{Signature: hex.EncodeToString(sig)})
+}
+
+func (s *Server) fail(w http.ResponseWriter, r *http.Request, status int, code string, err error) {
+	s.logf("denied %s %s: %v", r.Method, r.URL.Path, err)
+	writeJSON(w, status, &ErrorResponse{Code: code, Error: err.Error()})
+}
+
+func (s *Server) logf(format string, args ...interface{}) {
+	if s.logger != nil {
+		s.logger.Printf(format, args...)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v) //nolint:errcheck // The client is gone
+}
+
+// decodeTx decodes the tx info of a transaction type
+func decodeTx(txType uint8, txInfo json.RawMessage) (txtypes.TxInfo, error) {
+	var tx txtypes.TxInfo
+	switch txType {
+	case txtypes.TxTypeL2ChangePubKey:
+		tx = &txtypes.L2ChangePubKeyTxInfo{}
+	case txtypes.TxTypeL2CreateSubAccount:
+		tx = &txtypes.L2CreateSubAccountTxInfo{}
+	case txtypes.TxTypeL2CreatePublicPool:
+		tx = &txtypes.L2CreatePublicPoolTxInfo{}
+	case txtypes.TxTypeL2UpdatePublicPool:
+		tx = &txtypes.L2UpdatePublicPoolTxInfo{}
+	case txtypes.TxTypeL2Transfer:
+		tx = &txtypes.L2TransferTxInfo{}
+	case txtypes.TxTypeL2Withdraw:
+		tx = &txtypes.L2WithdrawTxInfo{}
+	case txtypes.TxTypeL2CreateOrder:
+		tx = &txtypes.L2CreateOrderTxInfo{}
+	case txtypes.TxTypeL2CancelOrder:
+		tx = &txtypes.L2CancelOrderTxInfo{}
+	case txtypes.TxTypeL2CancelAllOrders:
+		tx = &txtypes.L2CancelAllOrdersTxInfo{}
+	case txtypes.TxTypeL2ModifyOrder:
+		tx = &txtypes.L2ModifyOrderTxInfo{}
+	case txtypes.TxTypeL2MintShares:
+		tx = &txtypes.L2MintSharesTxInfo{}
+	case txtypes.TxTypeL2BurnShares:
+		tx = &txtypes.L2BurnSharesTxInfo{}
+	case txtypes.TxTypeL2UpdateLeverage:
+		tx = &txtypes.L2UpdateLeverageTxInfo{}
+	case txtypes.TxTypeL2CreateGroupedOrders:
+		tx = &txtypes.L2CreateGroupedOrdersTxInfo{}
+	case txtypes.TxTypeL2UpdateMargin:
+		tx = &txtypes.L2UpdateMarginTxInfo{}
+	default:
+		return nil, fmt.Errorf("unsupported tx type %d", txType)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(txInfo))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(tx); err != nil {
+		return nil, fmt.Errorf("invalid tx info: %w", err)
+	}
+	return tx, nil
+}
+
+// Listen opens the daemon's listener on "unix:///path/to/socket" or "host:port".
+// A Unix socket replaces a stale one and is only accessible to the daemon's user.
+func Listen(endpoint string) (net.Listener, error) {
+	socket, ok := strings.CutPrefix(endpoint, "unix://")
+	if !ok {
+		return net.Listen("tcp", endpoint)
+	}
+
+	if err := os.Remove(socket); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+	}
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(socket, 0o600); err != nil {
+		l.Close() //nolint:errcheck // Returning the chmod error
+		return nil, fmt.Errorf("failed to restrict socket: %w", err)
+	}
+	return l, nil
+}
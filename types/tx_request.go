@@ -134,14 +134,17 @@ func ConstructAuthToken(key signer.Signer, deadline time.Time, ops *TransactOpts
 	}
 	message := fmt.Sprintf("%v:%v:%v", deadline.Unix(), *ops.FromAccountIndex, *ops.ApiKeyIndex)
 
-	msgInField, err := g.ArrayFromCanonicalLittleEndianBytes([]byte(message))
+	msgHash, err := AuthTokenHash(message)
 	if err != nil {
-		return "", fmt.Errorf("failed to convert bytes to field element. message: %s, error: %w", message, err)
+		return "", err
 	}
 
-	msgHash := p2.HashToQuinticExtension(msgInField).ToLittleEndianBytes()
-
-	signatureBytes, err := key.Sign(msgHash, p2.NewPoseidon2())
+	var signatureBytes []byte
+	if txSigner, ok := key.(signer.TxSigner); ok {
+		signatureBytes, err = txSigner.SignAuthToken(message, msgHash)
+	} else {
+		signatureBytes, err = key.Sign(msgHash, p2.NewPoseidon2())
+	}
 	if err != nil {
 		return "", err
 	}
@@ -150,6 +153,23 @@ func ConstructAuthToken(key signer.Signer, deadline time.Time, ops *TransactOpts
 	return fmt.Sprintf("%v:%v", message, signature), err
 }
 
+// AuthTokenHash returns the hash signed for an auth token message ("deadline:account:apiKey")
+func AuthTokenHash(message string) ([]byte, error) {
+	msgInField, err := g.ArrayFromCanonicalLittleEndianBytes([]byte(message))
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert bytes to field element. message: %s, error: %w", message, err)
+	}
+	return p2.HashToQuinticExtension(msgInField).ToLittleEndianBytes(), nil
+}
+
+// signTx signs the hash of a transaction, handing the transaction itself to signers that check it
+func signTx(key signer.Signer, tx txtypes.TxInfo, msgHash []byte) ([]byte, error) {
+	if txSigner, ok := key.(signer.TxSigner); ok {
+		return txSigner.SignTx(tx, msgHash)
+	}
+	return key.Sign(msgHash, p2.NewPoseidon2())
+}
+
 func ConstructChangePubKeyTx(key signer.Signer, lighterChainId uint32, tx *ChangePubKeyReq, ops *TransactOpts) (*txtypes.L2ChangePubKeyTxInfo, error) {
 	convertedTx := ConvertChangePubKeyTx(tx, ops)
 	err := convertedTx.Validate()
@@ -162,7 +182,7 @@ func ConstructChangePubKeyTx(key signer.Signer, lighterChainId uint32, tx *Chang
 		return nil, err
 	}
 
-	signature, err := key.Sign(msgHash, p2.NewPoseidon2())
+	signature, err := signTx(key, convertedTx, msgHash)
 	if err != nil {
 		return nil, err
 	}
@@ -184,7 +204,7 @@ func ConstructCreateSubAccountTx(key signer.Signer, lighterChainId uint32, ops *
 		return nil, err
 	}
 
-	signature, err := key.Sign(msgHash, p2.NewPoseidon2())
+	signature, err := signTx(key, convertedTx, msgHash)
 	if err != nil {
 		return nil, err
 	}
@@ -206,7 +226,7 @@ func ConstructCreatePublicPoolTx(key signer.Signer, lighterChainId uint32, tx *C
 		return nil, err
 	}
 
-	signature, err := key.Sign(msgHash, p2.NewPoseidon2())
+	signature, err := signTx(key, convertedTx, msgHash)
 	if err != nil {
 		return nil, err
 	}
@@ -228,7 +248,7 @@ func ConstructUpdatePublicPoolTx(key signer.Signer, lighterChainId uint32, tx *U
 		return nil, err
 	}
 
-	signature, err := key.Sign(msgHash, p2.NewPoseidon2())
+	signature, err := signTx(key, convertedTx, msgHash)
 	if err != nil {
 		return nil, err
 	}
@@ -250,7 +270,7 @@ func ConstructWithdrawTx(key signer.Signer, lighterChainId uint32, tx *WithdrawT
 		return nil, err
 	}
 
-	signature, err := key.Sign(msgHash, p2.NewPoseidon2())
+	signature, err := signTx(key, convertedTx, msgHash)
 	if err != nil {
 		return nil, err
 	}
@@ -272,7 +292,7 @@ func ConstructCreateOrderTx(key signer.Signer, lighterChainId uint32, tx *Create
 		return nil, err
 	}
 
-	signature, err := key.Sign(msgHash, p2.NewPoseidon2())
+	signature, err := signTx(key, convertedTx, msgHash)
 	if err != nil {
 		return nil, err
 	}
@@ -294,7 +314,7 @@ func ConstructL2CreateGroupedOrdersTx(key signer.Signer, lighterChainId uint32,
 		return nil, err
 	}
 
-	signature, err := key.Sign(msgHash, p2.NewPoseidon2())
+	signature, err := signTx(key, convertedTx, msgHash)
 	if err != nil {
 		return nil, err
 	}
@@ -316,7 +336,7 @@ func ConstructL2CancelOrderTx(key signer.Signer, lighterChainId uint32, tx *Canc
 		return nil, err
 	}
 
-	signature, err := key.Sign(msgHash, p2.NewPoseidon2())
+	signature, err := signTx(key, convertedTx, msgHash)
 	if err != nil {
 		return nil, err
 	}
@@ -338,7 +358,7 @@ func ConstructL2ModifyOrderTx(key signer.Signer, lighterChainId uint32, tx *Modi
 		return nil, err
 	}
 
-	signature, err := key.Sign(msgHash, p2.NewPoseidon2())
+	signature, err := signTx(key, convertedTx, msgHash)
 	if err != nil {
 		return nil, err
 	}
@@ -360,7 +380,7 @@ func ConstructL2CancelAllOrdersTx(key signer.Signer, lighterChainId uint32, tx *
 		return nil, err
 	}
 
-	signature, err := key.Sign(msgHash, p2.NewPoseidon2())
+	signature, err := signTx(key, convertedTx, msgHash)
 	if err != nil {
 		return nil, err
 	}
@@ -382,7 +402,7 @@ func ConstructTransferTx(key signer.Signer, lighterChainId uint32, tx *TransferT
 		return nil, err
 	}
 
-	signature, err := key.Sign(msgHash, p2.NewPoseidon2())
+	signature, err := signTx(key, convertedTx, msgHash)
 	if err != nil {
 		return nil, err
 	}
@@ -404,7 +424,7 @@ func ConstructMintSharesTx(key signer.Signer, lighterChainId uint32, tx *MintSha
 		return nil, err
 	}
 
-	signature, err := key.Sign(msgHash, p2.NewPoseidon2())
+	signature, err := signTx(key, convertedTx, msgHash)
 	if err != nil {
 		return nil, err
 	}
@@ -426,7 +446,7 @@ func ConstructBurnSharesTx(key signer.Signer, lighterChainId uint32, tx *BurnSha
 		return nil, err
 	}
 
-	signature, err := key.Sign(msgHash, p2.NewPoseidon2())
+	signature, err := signTx(key, convertedTx, msgHash)
 	if err != nil {
 		return nil, err
 	}
@@ -448,7 +468,7 @@ func ConstructUpdateLeverageTx(key signer.Signer, lighterChainId uint32, tx *Upd
 		return nil, err
 	}
 
-	signature, err := key.Sign(msgHash, p2.NewPoseidon2())
+	signature, err := signTx(key, convertedTx, msgHash)
 	if err != nil {
 		return nil, err
 	}
@@ -470,7 +490,7 @@ func ConstructUpdateMarginTx(key signer.Signer, lighterChainId uint32, tx *Updat
 		return nil, err
 	}
 
-	signature, err := key.Sign(msgHash, p2.NewPoseidon2())
+	signature, err := signTx(key, convertedTx, msgHash)
 	if err != nil {
 		return nil, err
 	}
@@ -32,6 +32,18 @@ func (txInfo *L2BurnSharesTxInfo) GetTxHash() string {
 	return txInfo.SignedHash
 }
 
+func (txInfo *L2BurnSharesTxInfo) GetAccountIndex() int64 {
+	return txInfo.AccountIndex
+}
+
+func (txInfo *L2BurnSharesTxInfo) GetApiKeyIndex() uint8 {
+	return txInfo.ApiKeyIndex
+}
+
+func (txInfo *L2BurnSharesTxInfo) GetNonce() int64 {
+	return txInfo.Nonce
+}
+
 func (txInfo *L2BurnSharesTxInfo) Validate() error {
 	if txInfo.AccountIndex < MinAccountIndex {
 		return ErrFromAccountIndexTooLow
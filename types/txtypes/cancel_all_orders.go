@@ -32,6 +32,18 @@ func (txInfo *L2CancelAllOrdersTxInfo) GetTxHash() string {
 	return txInfo.SignedHash
 }
 
+func (txInfo *L2CancelAllOrdersTxInfo) GetAccountIndex() int64 {
+	return txInfo.AccountIndex
+}
+
+func (txInfo *L2CancelAllOrdersTxInfo) GetApiKeyIndex() uint8 {
+	return txInfo.ApiKeyIndex
+}
+
+func (txInfo *L2CancelAllOrdersTxInfo) GetNonce() int64 {
+	return txInfo.Nonce
+}
+
 func (txInfo *L2CancelAllOrdersTxInfo) Validate() error {
 	// AccountIndex
 	if txInfo.AccountIndex < MinAccountIndex {
@@ -32,6 +32,18 @@ func (txInfo *L2CancelOrderTxInfo) GetTxHash() string {
 	return txInfo.SignedHash
 }
 
+func (txInfo *L2CancelOrderTxInfo) GetAccountIndex() int64 {
+	return txInfo.AccountIndex
+}
+
+func (txInfo *L2CancelOrderTxInfo) GetApiKeyIndex() uint8 {
+	return txInfo.ApiKeyIndex
+}
+
+func (txInfo *L2CancelOrderTxInfo) GetNonce() int64 {
+	return txInfo.Nonce
+}
+
 func (txInfo *L2CancelOrderTxInfo) Validate() error {
 	// AccountIndex
 	if txInfo.AccountIndex < MinAccountIndex {
@@ -49,6 +49,32 @@ func (txInfo *L2ChangePubKeyTxInfo) MarshalJSON() ([]byte, error) {
 	})
 }
 
+// UnmarshalJSON decodes the hex-encoded PubKey and Sig written by MarshalJSON
+func (txInfo *L2ChangePubKeyTxInfo) UnmarshalJSON(data []byte) error {
+	var raw l2ChangePubKeyTxInfoJSON
+	if err := sonic.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	pubKey, err := hex.DecodeString(raw.PubKey)
+	if err != nil {
+		return fmt.Errorf("invalid PubKey: %w", err)
+	}
+	sig, err := hex.DecodeString(raw.Sig)
+	if err != nil {
+		return fmt.Errorf("invalid Sig: %w", err)
+	}
+	*txInfo = L2ChangePubKeyTxInfo{
+		AccountIndex: raw.AccountIndex,
+		ApiKeyIndex:  raw.ApiKeyIndex,
+		PubKey:       pubKey,
+		L1Sig:        raw.L1Sig,
+		ExpiredAt:    raw.ExpiredAt,
+		Nonce:        raw.Nonce,
+		Sig:          sig,
+	}
+	return nil
+}
+
 func (txInfo *L2ChangePubKeyTxInfo) GetTxType() uint8 {
 	return TxTypeL2ChangePubKey
 }
@@ -61,6 +87,18 @@ func (txInfo *L2ChangePubKeyTxInfo) GetTxHash() string {
 	return txInfo.SignedHash
 }
 
+func (txInfo *L2ChangePubKeyTxInfo) GetAccountIndex() int64 {
+	return txInfo.AccountIndex
+}
+
+func (txInfo *L2ChangePubKeyTxInfo) GetApiKeyIndex() uint8 {
+	return txInfo.ApiKeyIndex
+}
+
+func (txInfo *L2ChangePubKeyTxInfo) GetNonce() int64 {
+	return txInfo.Nonce
+}
+
 func (txInfo *L2ChangePubKeyTxInfo) Validate() error {
 	// AccountIndex
 	if txInfo.AccountIndex < MinAccountIndex {
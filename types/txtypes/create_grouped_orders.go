@@ -34,6 +34,18 @@ func (txInfo *L2CreateGroupedOrdersTxInfo) GetTxHash() string {
 	return txInfo.SignedHash
 }
 
+func (txInfo *L2CreateGroupedOrdersTxInfo) GetAccountIndex() int64 {
+	return txInfo.AccountIndex
+}
+
+func (txInfo *L2CreateGroupedOrdersTxInfo) GetApiKeyIndex() uint8 {
+	return txInfo.ApiKeyIndex
+}
+
+func (txInfo *L2CreateGroupedOrdersTxInfo) GetNonce() int64 {
+	return txInfo.Nonce
+}
+
 func (txInfo *L2CreateGroupedOrdersTxInfo) Validate() error {
 	// AccountIndex
 	if txInfo.AccountIndex < MinAccountIndex {
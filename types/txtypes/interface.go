@@ -15,6 +15,16 @@ type TxInfo interface {
 	// Returns empty string if the Tx is not signed.
 	GetTxHash() string
 
+	// GetAccountIndex returns the account sending this transaction.
+	GetAccountIndex() int64
+
+	// GetApiKeyIndex returns the index of the ApiKey that signed this transaction.
+	GetApiKeyIndex() uint8
+
+	// GetNonce returns the nonce this transaction was signed with.
+	// It lets callers acknowledge the exact nonce to a nonce.Manager after submission.
+	GetNonce() int64
+
 	Validate() error
 
 	Hash(lighterChainId uint32, extra ...g.Element) (msgHash []byte, err error)
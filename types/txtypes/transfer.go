@@ -106,6 +106,18 @@ func (txInfo *L2TransferTxInfo) GetTxHash() string {
 	return txInfo.SignedHash
 }
 
+func (txInfo *L2TransferTxInfo) GetAccountIndex() int64 {
+	return txInfo.FromAccountIndex
+}
+
+func (txInfo *L2TransferTxInfo) GetApiKeyIndex() uint8 {
+	return txInfo.ApiKeyIndex
+}
+
+func (txInfo *L2TransferTxInfo) GetNonce() int64 {
+	return txInfo.Nonce
+}
+
 func (txInfo *L2TransferTxInfo) GetTxInfo() (string, error) {
 	return getTxInfo(txInfo)
 }
@@ -79,6 +79,18 @@ func (txInfo *L2WithdrawTxInfo) GetTxHash() string {
 	return txInfo.SignedHash
 }
 
+func (txInfo *L2WithdrawTxInfo) GetAccountIndex() int64 {
+	return txInfo.FromAccountIndex
+}
+
+func (txInfo *L2WithdrawTxInfo) GetApiKeyIndex() uint8 {
+	return txInfo.ApiKeyIndex
+}
+
+func (txInfo *L2WithdrawTxInfo) GetNonce() int64 {
+	return txInfo.Nonce
+}
+
 func (txInfo *L2WithdrawTxInfo) Hash(lighterChainId uint32, extra ...g.Element) (msgHash []byte, err error) {
 	elems := make([]g.Element, 0, 14)
 
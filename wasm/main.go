@@ -4,6 +4,8 @@
 package main
 
 import (
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"syscall/js"
@@ -11,6 +13,7 @@ import (
 
 	"github.com/0xJord4n/lighter-go/client"
 	"github.com/0xJord4n/lighter-go/client/http"
+	"github.com/0xJord4n/lighter-go/signer"
 	"github.com/0xJord4n/lighter-go/types"
 	"github.com/0xJord4n/lighter-go/types/txtypes"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -58,6 +61,54 @@ func convertTxInfoToJS(info txtypes.TxInfo, err error, chainId uint32) js.Value
 	return js.ValueOf(out)
 }
 
+// attachL1Signature verifies a wallet's signature of the message returned with a
+// ChangePubKey or Transfer transaction and sets it as the transaction's L1Sig.
+// The hash is not part of the tx info, so it is recomputed.
+func attachL1Signature(txType uint8, txInfoStr, signature, address string, chainId uint32) (txtypes.TxInfo, error) {
+	signature, err := signer.NormalizeL1Signature(signature)
+	if err != nil {
+		return nil, err
+	}
+
+	var message string
+	var tx txtypes.TxInfo
+	switch txType {
+	case txtypes.TxTypeL2ChangePubKey:
+		changePubKey := &txtypes.L2ChangePubKeyTxInfo{}
+		if err := json.Unmarshal([]byte(txInfoStr), changePubKey); err != nil {
+			return nil, err
+		}
+		msgHash, err := changePubKey.Hash(chainId)
+		if err != nil {
+			return nil, err
+		}
+		message = changePubKey.GetL1SignatureBody()
+		changePubKey.SetL1Sig(signature)
+		changePubKey.SignedHash = hex.EncodeToString(msgHash)
+		tx = changePubKey
+	case txtypes.TxTypeL2Transfer:
+		transfer := &txtypes.L2TransferTxInfo{}
+		if err := json.Unmarshal([]byte(txInfoStr), transfer); err != nil {
+			return nil, err
+		}
+		msgHash, err := transfer.Hash(chainId)
+		if err != nil {
+			return nil, err
+		}
+		message = transfer.GetL1SignatureBody(chainId)
+		transfer.SetL1Sig(signature)
+		transfer.SignedHash = hex.EncodeToString(msgHash)
+		tx = transfer
+	default:
+		return nil, fmt.Errorf("tx type %d does not take an L1 signature", txType)
+	}
+
+	if err := signer.VerifyL1Signature(message, signature, address); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
 // safeInt safely extracts an int from a js.Value, handling undefined values
 func safeInt(v js.Value, index int) (int64, error) {
 	if v.Type() == js.TypeUndefined {
@@ -402,6 +453,19 @@ func main() {
 		})
 	}))
 
+	js.Global().Set("AttachL1Signature", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		return recoverPanic(func() js.Value {
+			if len(args) < 5 {
+				return js.ValueOf(map[string]interface{}{"error": "AttachL1Signature expects 5 args: txType, txInfo, signature, address, chainId"})
+			}
+			txType := uint8(args[0].Int())
+			chainId := uint32(args[4].Int())
+
+			tx, err := attachL1Signature(txType, args[1].String(), args[2].String(), args[3].String(), chainId)
+			return convertTxInfoToJS(tx, err, chainId)
+		})
+	}))
+
 	js.Global().Set("SignWithdraw", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
 		return recoverPanic(func() js.Value {
 			if len(args) < 4 {
@@ -0,0 +1,61 @@
+//go:build js
+// +build js
+
+package main
+
+import (
+	"testing"
+
+	"github.com/0xJord4n/lighter-go/client"
+	"github.com/0xJord4n/lighter-go/signer"
+	"github.com/0xJord4n/lighter-go/types"
+	"github.com/0xJord4n/lighter-go/types/txtypes"
+)
+
+const (
+	testChainId = 304
+	testEthKey  = "0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+)
+
+func TestAttachL1Signature_RoundTrip(t *testing.T) {
+	privateKey, _, _ := client.GenerateAPIKey()
+	txClient, err := client.NewTxClient(nil, privateKey, 43, 3, testChainId)
+	if err != nil {
+		t.Fatalf("NewTxClient failed: %v", err)
+	}
+	l1Signer, _ := signer.NewL1Signer(testEthKey)
+	ops := func() *types.TransactOpts { return &types.TransactOpts{Nonce: types.NewInt64(10)} }
+
+	// ChangePubKey is signed with the key it registers
+	changePubKey, err := txClient.GetChangePubKeyTransaction(&types.ChangePubKeyReq{PubKey: txClient.GetKeyManager().PubKeyBytes()}, ops())
+	if err != nil {
+		t.Fatalf("GetChangePubKeyTransaction failed: %v", err)
+	}
+	transfer, err := txClient.GetTransferTransaction(&types.TransferTxReq{ToAccountIndex: 44, AssetIndex: 3, Amount: 1000}, ops())
+	if err != nil {
+		t.Fatalf("GetTransferTransaction failed: %v", err)
+	}
+
+	for _, tx := range []txtypes.TxInfo{changePubKey, transfer} {
+		txInfo, err := tx.GetTxInfo()
+		if err != nil {
+			t.Fatalf("GetTxInfo failed: %v", err)
+		}
+		signature, err := l1Signer.Sign(messageToSign(tx, testChainId))
+		if err != nil {
+			t.Fatalf("Sign failed: %v", err)
+		}
+
+		signed, err := attachL1Signature(tx.GetTxType(), txInfo, signature, l1Signer.Address(), testChainId)
+		if err != nil {
+			t.Fatalf("tx type %d: attachL1Signature failed: %v", tx.GetTxType(), err)
+		}
+		if signed.GetTxHash() == "" || signed.GetTxHash() != tx.GetTxHash() {
+			t.Errorf("tx type %d: expected hash %s, got %q", tx.GetTxType(), tx.GetTxHash(), signed.GetTxHash())
+		}
+
+		if _, err := attachL1Signature(tx.GetTxType(), txInfo, signature, "0x0000000000000000000000000000000000000001", testChainId); err == nil {
+			t.Errorf("tx type %d: expected a signature of another address refused", tx.GetTxType())
+		}
+	}
+}